@@ -53,11 +53,17 @@ type Block struct {
 
 	ClientStateHash Key                        `json:"state_hash"`
 	Txns            []*transaction.Transaction `json:"transactions,omitempty"`
+	MagicBlock      *MagicBlock                `json:"magic_block,omitempty"`
 
-	// muted
+	VerificationTickets          []*VerificationTicket `json:"verification_tickets,omitempty"`
+	PrevBlockVerificationTickets []*VerificationTicket `json:"prev_verification_tickets,omitempty"`
+}
 
-	// VerificationTickets []*VerificationTicket `json:"verification_tickets,omitempty"`
-	// PrevBlockVerificationTickets []*VerificationTicket `json:"prev_verification_tickets,omitempty"`
+// VerificationTicket is a signature of a miner over a block hash
+// confirming the miner has verified the block.
+type VerificationTicket struct {
+	VerifierID string `json:"verifier_id"`
+	Signature  string `json:"signature"`
 }
 
 type ChainStats struct {
@@ -0,0 +1,307 @@
+package block
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// GetMiner returns the miner with given id from the magic block.
+func (mb *MagicBlock) GetMiner(id string) (Node, bool) {
+	if mb.Miners == nil {
+		return Node{}, false
+	}
+	n, ok := mb.Miners.Nodes[id]
+	return n, ok
+}
+
+// IsDKGParticipant reports whether the miner published a valid public
+// polynomial for the DKG of the magic block: T coefficients, each a BLS
+// public key. A magic block without mpks treats every miner as a
+// participant.
+func (mb *MagicBlock) IsDKGParticipant(id string) bool {
+	if mb.Mpks == nil || len(mb.Mpks.Mpks) == 0 {
+		_, ok := mb.GetMiner(id)
+		return ok
+	}
+	mpk, ok := mb.Mpks.Mpks[id]
+	if !ok || mpk == nil || len(mpk.Mpk) == 0 {
+		return false
+	}
+	if mb.T > 0 && len(mpk.Mpk) != mb.T {
+		return false
+	}
+	for _, c := range mpk.Mpk {
+		if !zcncrypto.IsBLSPublicKey(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ThresholdTickets returns the number of distinct verification tickets
+// a block of this magic block needs. It is the DKG threshold T, or two
+// thirds of the miners when T is not set.
+func (mb *MagicBlock) ThresholdTickets() int {
+	if mb.T > 0 {
+		return mb.T
+	}
+	if mb.Miners == nil {
+		return 0
+	}
+	return int(math.Ceil(float64(len(mb.Miners.Nodes)) * 2 / 3))
+}
+
+// IsActiveAt reports whether the magic block governs the given round.
+func (mb *MagicBlock) IsActiveAt(round int64) bool {
+	return round >= mb.StartingRound
+}
+
+// VerifyNext checks that next is the magic block following mb.
+func (mb *MagicBlock) VerifyNext(next *MagicBlock) error {
+	if next == nil {
+		return errors.New("magic_block_rotation", "missing next magic block")
+	}
+	if next.MagicBlockNumber != mb.MagicBlockNumber+1 {
+		return errors.New("magic_block_rotation",
+			fmt.Sprintf("unexpected magic block number %d, want %d",
+				next.MagicBlockNumber, mb.MagicBlockNumber+1))
+	}
+	if next.PreviousMagicBlockHash != mb.Hash {
+		return errors.New("magic_block_rotation",
+			fmt.Sprintf("magic block %d does not extend %s",
+				next.MagicBlockNumber, mb.Hash))
+	}
+	if next.StartingRound <= mb.StartingRound {
+		return errors.New("magic_block_rotation",
+			fmt.Sprintf("magic block %d starts at round %d before %d",
+				next.MagicBlockNumber, next.StartingRound, mb.StartingRound))
+	}
+	if next.Miners == nil || len(next.Miners.Nodes) == 0 {
+		return errors.New("magic_block_rotation", "magic block without miners")
+	}
+	return nil
+}
+
+// ComputeHash returns the hash of the magic block the way the chain
+// computes it: the sha3 of its number, previous hash, starting round,
+// sorted miner and sharder ids, the shares and mpks hashes, T and N,
+// concatenated without separators.
+func (mb *MagicBlock) ComputeHash() string {
+	var data = []byte(strconv.FormatInt(mb.MagicBlockNumber, 10))
+	data = append(data, mb.PreviousMagicBlockHash...)
+	data = append(data, strconv.FormatInt(mb.StartingRound, 10)...)
+	for _, id := range poolIDs(mb.Miners) {
+		data = append(data, id...)
+	}
+	for _, id := range poolIDs(mb.Sharders) {
+		data = append(data, id...)
+	}
+	if mb.ShareOrSigns != nil {
+		data = append(data, mb.ShareOrSigns.rawHash()...)
+	}
+	if mb.Mpks != nil {
+		data = append(data, mb.Mpks.rawHash()...)
+	}
+	data = append(data, strconv.Itoa(mb.T)...)
+	data = append(data, strconv.Itoa(mb.N)...)
+	return encryption.Hash(data)
+}
+
+func poolIDs(pool *NodePool) []string {
+	if pool == nil {
+		return nil
+	}
+	var ids = make([]string, 0, len(pool.Nodes))
+	for id := range pool.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// rawHash hashes the shares of every miner, in miner id order.
+func (gsos *GroupSharesOrSigns) rawHash() []byte {
+	var ids = make([]string, 0, len(gsos.Shares))
+	for id := range gsos.Shares {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var data []byte
+	for _, id := range ids {
+		if sos := gsos.Shares[id]; sos != nil {
+			data = append(data, sos.rawHash()...)
+		}
+	}
+	return encryption.RawHash(data)
+}
+
+func (sos *ShareOrSigns) rawHash() []byte {
+	var ids = make([]string, 0, len(sos.ShareOrSigns))
+	for id := range sos.ShareOrSigns {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var data = sos.ID
+	for _, id := range ids {
+		share, _ := json.Marshal(sos.ShareOrSigns[id])
+		data += string(share)
+	}
+	return encryption.RawHash(data)
+}
+
+func (mpks *Mpks) rawHash() []byte {
+	data, _ := json.Marshal(mpks)
+	return encryption.RawHash(data)
+}
+
+// verifyNodeIDs checks that every node id is the hash of the node public
+// key. The magic block hash only covers the ids, so this binds the keys
+// used to verify signatures to the hashed content.
+func verifyNodeIDs(pool *NodePool) error {
+	if pool == nil {
+		return nil
+	}
+	for id, n := range pool.Nodes {
+		pk, err := hex.DecodeString(n.PublicKey)
+		if err != nil || n.ID != id || encryption.Hash(pk) != id {
+			return errors.New("magic_block_rotation",
+				fmt.Sprintf("node %s does not match its public key", id))
+		}
+	}
+	return nil
+}
+
+// VerifyNextBlock checks that the block carries the magic block following
+// mb, that the magic block hashes to its hash, that its node ids match
+// their keys and that the block is endorsed by ThresholdTickets miners of
+// mb. It returns the verified next magic block.
+//
+// The block hash does not cover the magic block, which the chain keeps in
+// its state, so the pairing of block and magic block relies on the
+// sharders agreeing on it.
+func (mb *MagicBlock) VerifyNextBlock(b *Block, scheme string) (*MagicBlock, error) {
+	if b == nil || b.MagicBlock == nil {
+		return nil, errors.New("magic_block_rotation", "block carries no magic block")
+	}
+	var next = b.MagicBlock
+	if err := mb.VerifyNext(next); err != nil {
+		return nil, err
+	}
+	if hash := next.ComputeHash(); hash != next.Hash {
+		return nil, errors.New("magic_block_rotation",
+			fmt.Sprintf("magic block %d hashes to %s, not %s", next.MagicBlockNumber, hash, next.Hash))
+	}
+	if err := verifyNodeIDs(next.Miners); err != nil {
+		return nil, err
+	}
+	if err := verifyNodeIDs(next.Sharders); err != nil {
+		return nil, err
+	}
+	if b.Round >= next.StartingRound {
+		return nil, errors.New("magic_block_rotation",
+			fmt.Sprintf("magic block %d is carried by round %d after its start", next.MagicBlockNumber, b.Round))
+	}
+	if err := mb.VerifyBlock(b, scheme); err != nil {
+		return nil, errors.Wrap(err, "magic block not endorsed by the previous miners")
+	}
+	return next, nil
+}
+
+// VerifyBlock checks that the block header hashes to the block hash, that
+// the block is signed by its generator and that it carries at least
+// ThresholdTickets valid verification tickets from DKG participants of the
+// magic block. The scheme is the chain signature scheme.
+func (mb *MagicBlock) VerifyBlock(b *Block, scheme string) error {
+	if b == nil || b.Header == nil {
+		return errors.New("verify_block", "missing block header")
+	}
+	if b.Header.Hash != string(b.Hash) || b.Header.Round != b.Round {
+		return errors.New("verify_block", "header does not match block")
+	}
+	if !mb.IsActiveAt(b.Round) {
+		return errors.New("verify_block",
+			fmt.Sprintf("round %d is before magic block %d", b.Round, mb.MagicBlockNumber))
+	}
+	if !b.Header.IsBlockExtends(b.PrevHash) {
+		return errors.New("verify_block", "block hash verification failed")
+	}
+
+	miner, ok := mb.GetMiner(string(b.MinerID))
+	if !ok {
+		return errors.New("verify_block",
+			fmt.Sprintf("generator %s is not a miner of magic block %d", b.MinerID, mb.MagicBlockNumber))
+	}
+	if err := verifySignature(scheme, miner.PublicKey, b.Signature, string(b.Hash)); err != nil {
+		return errors.Wrap(err, "invalid block signature")
+	}
+
+	var (
+		need   = mb.ThresholdTickets()
+		seen   = make(map[string]struct{}, len(b.VerificationTickets))
+		signed = make([]zcncrypto.SignedHash, 0, len(b.VerificationTickets))
+	)
+	for _, vt := range b.VerificationTickets {
+		if vt == nil {
+			continue
+		}
+		if _, dup := seen[vt.VerifierID]; dup {
+			continue
+		}
+		verifier, ok := mb.GetMiner(vt.VerifierID)
+		if !ok || !mb.IsDKGParticipant(vt.VerifierID) {
+			continue
+		}
+		seen[vt.VerifierID] = struct{}{}
+		signed = append(signed, zcncrypto.SignedHash{
+			PublicKey: verifier.PublicKey,
+			Signature: vt.Signature,
+			Hash:      string(b.Hash),
+		})
+	}
+	if len(signed) < need {
+		return errors.New("verify_block",
+			fmt.Sprintf("not enough verification tickets: %d of %d", len(signed), need))
+	}
+	// all the tickets are usually valid, verify them at once and only
+	// look for the invalid ones when the batch fails
+	if ok, err := zcncrypto.BatchVerify(scheme, signed); err == nil && ok {
+		return nil
+	}
+	valid := 0
+	for _, sh := range signed {
+		if verifySignature(scheme, sh.PublicKey, sh.Signature, sh.Hash) != nil {
+			continue
+		}
+		if valid++; valid >= need {
+			return nil
+		}
+	}
+	return errors.New("verify_block",
+		fmt.Sprintf("not enough verification tickets: %d of %d", valid, need))
+}
+
+func verifySignature(scheme, publicKey, signature, hash string) error {
+	if !zcncrypto.IsSignatureSchemeSupported(scheme) {
+		return errors.New("verify_signature", "invalid signature scheme "+scheme)
+	}
+	ss := zcncrypto.NewSignatureScheme(scheme)
+	if err := ss.SetPublicKey(publicKey); err != nil {
+		return err
+	}
+	ok, err := ss.Verify(signature, hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("verify_signature", "signature mismatch")
+	}
+	return nil
+}
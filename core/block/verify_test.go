@@ -0,0 +1,264 @@
+package block
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/herumi/bls-go-binary/bls"
+	"github.com/stretchr/testify/require"
+)
+
+// testMiners are the miners of a magic block, with real node ids and
+// DKG public polynomials.
+type testMiners struct {
+	mb      *MagicBlock
+	ids     []string
+	signers map[string]zcncrypto.SignatureScheme
+}
+
+func newTestMpk(t int) []string {
+	var sk bls.SecretKey
+	sk.SetByCSPRNG()
+	var mpk []string
+	for _, pk := range bls.GetMasterPublicKey(sk.GetMasterSecretKey(t)) {
+		mpk = append(mpk, pk.GetHexString())
+	}
+	return mpk
+}
+
+func newTestMiners(t *testing.T, n int) *testMiners {
+	var tm = &testMiners{
+		mb: &MagicBlock{
+			Hash:             encryption.Hash("mb1"),
+			MagicBlockNumber: 1,
+			StartingRound:    10,
+			Miners:           &NodePool{Nodes: make(map[string]Node)},
+			Mpks:             &Mpks{Mpks: make(map[string]*MPK)},
+			T:                2,
+		},
+		signers: make(map[string]zcncrypto.SignatureScheme),
+	}
+	for i := 0; i < n; i++ {
+		ss := zcncrypto.NewSignatureScheme("bls0chain")
+		w, err := ss.GenerateKeys()
+		require.NoError(t, err)
+		id := w.ClientID
+		tm.mb.Miners.Nodes[id] = Node{ID: id, PublicKey: w.Keys[0].PublicKey}
+		tm.mb.Mpks.Mpks[id] = &MPK{ID: id, Mpk: newTestMpk(tm.mb.T)}
+		tm.ids = append(tm.ids, id)
+		tm.signers[id] = ss
+	}
+	return tm
+}
+
+func (tm *testMiners) block(t *testing.T, round int64, verifiers ...int) *Block {
+	return tm.carrier(t, round, nil, verifiers...)
+}
+
+// carrier returns a block of the round generated by the first miner and
+// carrying the magic block.
+func (tm *testMiners) carrier(t *testing.T, round int64, mb *MagicBlock, verifiers ...int) *Block {
+	var h = &Header{
+		MinerID:        tm.ids[0],
+		Round:          round,
+		CreationDate:   1000,
+		MerkleTreeRoot: encryption.Hash("txns"),
+	}
+	var prevHash = encryption.Hash("prev")
+	h.Hash = encryption.Hash(fmt.Sprintf("%s:%s:%d:%d:%d:%s:%s", h.MinerID,
+		prevHash, h.CreationDate, h.Round, h.RoundRandomSeed,
+		h.MerkleTreeRoot, h.ReceiptMerkleTreeRoot))
+
+	sig, err := tm.signers[h.MinerID].Sign(h.Hash)
+	require.NoError(t, err)
+
+	var b = &Block{
+		Header:     h,
+		MinerID:    common.Key(h.MinerID),
+		Round:      round,
+		Hash:       common.Key(h.Hash),
+		Signature:  sig,
+		PrevHash:   prevHash,
+		MagicBlock: mb,
+	}
+	for _, i := range verifiers {
+		sig, err := tm.signers[tm.ids[i]].Sign(h.Hash)
+		require.NoError(t, err)
+		b.VerificationTickets = append(b.VerificationTickets,
+			&VerificationTicket{VerifierID: tm.ids[i], Signature: sig})
+	}
+	return b
+}
+
+func TestMagicBlockVerifyBlock(t *testing.T) {
+	tm := newTestMiners(t, 3)
+	mb := tm.mb
+
+	t.Run("valid", func(t *testing.T) {
+		b := tm.block(t, 12, 1, 2)
+		require.NoError(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+
+	t.Run("duplicate tickets", func(t *testing.T) {
+		b := tm.block(t, 12, 1, 1)
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+
+	t.Run("ticket from non participant", func(t *testing.T) {
+		b := tm.block(t, 12, 1, 2)
+		mpk := mb.Mpks.Mpks[tm.ids[2]]
+		defer func() { mb.Mpks.Mpks[tm.ids[2]] = mpk }()
+
+		delete(mb.Mpks.Mpks, tm.ids[2])
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+
+		mb.Mpks.Mpks[tm.ids[2]] = &MPK{ID: tm.ids[2], Mpk: newTestMpk(mb.T + 1)}
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+
+		mb.Mpks.Mpks[tm.ids[2]] = &MPK{ID: tm.ids[2], Mpk: []string{mpk.Mpk[0], "x"}}
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+
+	t.Run("invalid ticket among valid ones", func(t *testing.T) {
+		b := tm.block(t, 12, 0, 1, 2)
+		b.VerificationTickets[0].Signature = b.VerificationTickets[1].Signature
+		require.NoError(t, mb.VerifyBlock(b, "bls0chain"))
+		b.VerificationTickets[2].Signature = b.VerificationTickets[1].Signature
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+
+	t.Run("bad generator signature", func(t *testing.T) {
+		b := tm.block(t, 12, 1, 2)
+		b.Signature = b.VerificationTickets[0].Signature
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		b := tm.block(t, 12, 1, 2)
+		b.Header.MerkleTreeRoot = encryption.Hash("other")
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+
+	t.Run("round before magic block", func(t *testing.T) {
+		b := tm.block(t, 5, 1, 2)
+		require.Error(t, mb.VerifyBlock(b, "bls0chain"))
+	})
+}
+
+func TestMagicBlockVerifyNext(t *testing.T) {
+	mb := newTestMiners(t, 1).mb
+	next := &MagicBlock{
+		Hash:                   encryption.Hash("mb2"),
+		PreviousMagicBlockHash: mb.Hash,
+		MagicBlockNumber:       2,
+		StartingRound:          100,
+		Miners:                 mb.Miners,
+	}
+	require.NoError(t, mb.VerifyNext(next))
+
+	next.PreviousMagicBlockHash = encryption.Hash("other")
+	require.Error(t, mb.VerifyNext(next))
+
+	next.PreviousMagicBlockHash = mb.Hash
+	next.MagicBlockNumber = 3
+	require.Error(t, mb.VerifyNext(next))
+}
+
+func newTestNext(mb *MagicBlock) *MagicBlock {
+	next := &MagicBlock{
+		PreviousMagicBlockHash: mb.Hash,
+		MagicBlockNumber:       mb.MagicBlockNumber + 1,
+		StartingRound:          100,
+		Miners:                 mb.Miners,
+		Mpks:                   mb.Mpks,
+		T:                      mb.T,
+	}
+	next.Hash = next.ComputeHash()
+	return next
+}
+
+func TestMagicBlockVerifyNextBlock(t *testing.T) {
+	tm := newTestMiners(t, 3)
+	mb := tm.mb
+
+	t.Run("valid", func(t *testing.T) {
+		next := newTestNext(mb)
+		b := tm.carrier(t, 50, next, 1, 2)
+		got, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.NoError(t, err)
+		require.Equal(t, next, got)
+	})
+
+	t.Run("no magic block", func(t *testing.T) {
+		b := tm.block(t, 50, 1, 2)
+		_, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.Error(t, err)
+	})
+
+	t.Run("hash not recomputed", func(t *testing.T) {
+		next := newTestNext(mb)
+		next.Hash = encryption.Hash("forged")
+		b := tm.carrier(t, 50, next, 1, 2)
+		_, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.Error(t, err)
+	})
+
+	t.Run("content changed", func(t *testing.T) {
+		next := newTestNext(mb)
+		b := tm.carrier(t, 50, next, 1, 2)
+		next.T = 1
+		_, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.Error(t, err)
+	})
+
+	t.Run("node id not matching its key", func(t *testing.T) {
+		next := newTestNext(mb)
+		next.Miners = &NodePool{Nodes: make(map[string]Node)}
+		for id, n := range mb.Miners.Nodes {
+			next.Miners.Nodes[id] = n
+		}
+		forged := next.Miners.Nodes[tm.ids[1]]
+		forged.PublicKey = next.Miners.Nodes[tm.ids[2]].PublicKey
+		next.Miners.Nodes[tm.ids[1]] = forged
+		next.Hash = next.ComputeHash()
+		b := tm.carrier(t, 50, next, 1, 2)
+		_, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.Error(t, err)
+	})
+
+	t.Run("not endorsed by the previous miners", func(t *testing.T) {
+		b := tm.carrier(t, 50, newTestNext(mb), 1)
+		_, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.Error(t, err)
+	})
+
+	t.Run("carried after its start", func(t *testing.T) {
+		b := tm.carrier(t, 100, newTestNext(mb), 1, 2)
+		_, err := mb.VerifyNextBlock(b, "bls0chain")
+		require.Error(t, err)
+	})
+}
+
+func TestMagicBlockComputeHash(t *testing.T) {
+	mb := &MagicBlock{
+		PreviousMagicBlockHash: encryption.Hash("mb1"),
+		MagicBlockNumber:       2,
+		StartingRound:          100,
+		Miners: &NodePool{Nodes: map[string]Node{
+			"m2": {ID: "m2"}, "m1": {ID: "m1"},
+		}},
+		Sharders: &NodePool{Nodes: map[string]Node{"s1": {ID: "s1"}}},
+		T:        2,
+		N:        3,
+	}
+	// number, previous hash, starting round, sorted ids, T and N
+	// concatenated without separators
+	want := encryption.Hash([]byte("2" + mb.PreviousMagicBlockHash + "100m1m2s123"))
+	require.Equal(t, want, mb.ComputeHash())
+
+	mb.Mpks = &Mpks{Mpks: map[string]*MPK{"m1": {ID: "m1", Mpk: newTestMpk(2)}}}
+	require.NotEqual(t, want, mb.ComputeHash())
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"regexp"
 )
 
 const (
@@ -32,6 +33,14 @@ const (
 	strDEBUG = "[DEBUG]  "
 )
 
+// secretFields matches the values of the json fields holding keys, wallet
+// and key pair json is redacted in logged messages.
+var secretFields = regexp.MustCompile(`("(?:private_key|mnemonic|mnemonics)"\s*:\s*)"(?:[^"\\]|\\.)+"`)
+
+func redact(msg string) string {
+	return secretFields.ReplaceAllString(msg, `$1"[REDACTED]"`)
+}
+
 type loggerIf interface {
 	Init(lvl int)
 	Debug(v ...interface{})
@@ -105,25 +114,25 @@ func (l *Logger) SetLogFile(logFile io.Writer, verbose bool) {
 
 func (l *Logger) Debug(v ...interface{}) {
 	if l.lvl >= DEBUG {
-		l.logDebug.Output(2, fmt.Sprint(v...))
+		l.logDebug.Output(2, redact(fmt.Sprint(v...)))
 	}
 }
 
 func (l *Logger) Info(v ...interface{}) {
 	if l.lvl >= INFO {
-		l.logInfo.Output(2, fmt.Sprint(v...))
+		l.logInfo.Output(2, redact(fmt.Sprint(v...)))
 	}
 }
 
 func (l *Logger) Error(v ...interface{}) {
 	if l.lvl >= ERROR {
-		l.logError.Output(2, fmt.Sprint(v...)+cReset)
+		l.logError.Output(2, redact(fmt.Sprint(v...))+cReset)
 	}
 }
 
 func (l *Logger) Fatal(v ...interface{}) {
 	if l.lvl >= FATAL {
-		l.logFatal.Output(2, fmt.Sprint(v...)+cReset)
+		l.logFatal.Output(2, redact(fmt.Sprint(v...))+cReset)
 	}
 }
 
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	var l Logger
+	l.Init(DEBUG, "test")
+	l.SetLogFile(&buf, false)
+
+	l.Debug(`{"client_id":"id","keys":[{"public_key":"pub","private_key":"c0ffee"}],"mnemonics":"word \"other\" word"}`)
+	l.Error("setup failed: ", `{"private_key" : "c0ffee", "mnemonic":"words"}`)
+	out := buf.String()
+	require.Contains(t, out, `"public_key":"pub"`)
+	require.Contains(t, out, `"client_id":"id"`)
+	require.NotContains(t, out, "c0ffee")
+	require.NotContains(t, out, "word")
+	require.Contains(t, out, `"private_key":"[REDACTED]"`)
+}
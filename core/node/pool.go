@@ -0,0 +1,330 @@
+// Package node tracks the health of miners and sharders and ranks them
+// for requests. The Miners and Sharders pools are shared by zcncore and
+// zboxcore.
+package node
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+// Config of a node pool.
+type Config struct {
+	// MaxFailures is the number of consecutive failures after which
+	// a node is quarantined.
+	MaxFailures int
+	// MinBackoff is the first quarantine period. Each quarantine in
+	// a row doubles it up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxRoundLag is the number of rounds a node can be behind the
+	// highest known round and still be considered healthy.
+	MaxRoundLag int64
+	// Decay is the weight of the latest sample in the latency and
+	// error rate moving averages.
+	Decay float64
+}
+
+// DefaultConfig used by the Miners and Sharders pools.
+var DefaultConfig = Config{
+	MaxFailures: 3,
+	MinBackoff:  5 * time.Second,
+	MaxBackoff:  5 * time.Minute,
+	MaxRoundLag: 5,
+	Decay:       0.2,
+}
+
+var (
+	// Miners is the health pool of the miners.
+	Miners = NewPool(DefaultConfig)
+	// Sharders is the health pool of the sharders.
+	Sharders = NewPool(DefaultConfig)
+)
+
+var errServerError = errors.New("server_error", "node responded with server error")
+
+// Stats of a node.
+type Stats struct {
+	URL                 string    `json:"url"`
+	Requests            int64     `json:"requests"`
+	Failures            int64     `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	ErrorRate           float64   `json:"error_rate"`
+	LatencyMs           float64   `json:"latency_ms"`
+	Round               int64     `json:"round"`
+	RoundLag            int64     `json:"round_lag"`
+	Quarantines         int       `json:"quarantines"`
+	QuarantinedUntil    time.Time `json:"quarantined_until,omitempty"`
+	Healthy             bool      `json:"healthy"`
+}
+
+type health struct {
+	requests    int64
+	failures    int64
+	consecutive int
+	errorRate   float64
+	latency     float64 // milliseconds
+	round       int64
+	quarantines int
+	until       time.Time
+}
+
+// Pool tracks latency, error rate and round lag of nodes identified by
+// their URL. Unknown nodes are added on first use.
+type Pool struct {
+	mutex    sync.Mutex
+	config   Config
+	nodes    map[string]*health
+	maxRound int64
+	now      func() time.Time
+}
+
+// NewPool creates a node pool.
+func NewPool(config Config) *Pool {
+	return &Pool{
+		config: config,
+		nodes:  make(map[string]*health),
+		now:    time.Now,
+	}
+}
+
+func (p *Pool) get(url string) *health {
+	h, ok := p.nodes[url]
+	if !ok {
+		h = new(health)
+		p.nodes[url] = h
+	}
+	return h
+}
+
+// Report records the result of a request to the node. Requests canceled
+// by the caller say nothing of the node and are not recorded.
+func (p *Pool) Report(url string, latency time.Duration, err error) {
+	if isCanceled(err) {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var (
+		h     = p.get(url)
+		decay = p.config.Decay
+		ms    = float64(latency) / float64(time.Millisecond)
+	)
+	h.requests++
+	if h.requests == 1 {
+		h.latency = ms
+	} else {
+		h.latency = decay*ms + (1-decay)*h.latency
+	}
+
+	if err == nil {
+		h.errorRate = (1 - decay) * h.errorRate
+		h.consecutive = 0
+		h.quarantines = 0
+		h.until = time.Time{}
+		return
+	}
+
+	h.failures++
+	h.errorRate = decay + (1-decay)*h.errorRate
+	h.consecutive++
+	if h.consecutive < p.config.MaxFailures {
+		return
+	}
+
+	backoff := p.config.MinBackoff << uint(h.quarantines)
+	if backoff > p.config.MaxBackoff || backoff <= 0 {
+		backoff = p.config.MaxBackoff
+	}
+	h.quarantines++
+	// a failure right after the quarantine quarantines again
+	h.consecutive = p.config.MaxFailures - 1
+	h.until = p.now().Add(backoff)
+}
+
+// isCanceled reports whether the error, or an error it wraps, is
+// context.Canceled.
+func isCanceled(err error) bool {
+	for err != nil {
+		if err == context.Canceled {
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// ReportRound records the latest round known by the node.
+func (p *Pool) ReportRound(url string, round int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	h := p.get(url)
+	if round > h.round {
+		h.round = round
+	}
+	if round > p.maxRound {
+		p.maxRound = round
+	}
+}
+
+// IsQuarantined reports whether the node is in quarantine.
+func (p *Pool) IsQuarantined(url string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	h, ok := p.nodes[url]
+	return ok && p.now().Before(h.until)
+}
+
+func (p *Pool) lag(h *health) int64 {
+	if h.round == 0 {
+		return 0
+	}
+	return p.maxRound - h.round
+}
+
+func (p *Pool) isHealthy(h *health, now time.Time) bool {
+	return !now.Before(h.until) && p.lag(h) <= p.config.MaxRoundLag
+}
+
+// score orders healthy nodes, lower is better.
+func (p *Pool) score(h *health) float64 {
+	return h.latency * (1 + 4*h.errorRate) * (1 + float64(p.lag(h))/10)
+}
+
+// Rank orders the nodes by health: healthy nodes by score first, then
+// lagging nodes, then quarantined nodes by end of quarantine. Nodes of
+// equal score are shuffled.
+func (p *Pool) Rank(urls []string) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var (
+		now    = p.now()
+		ranked = make([]string, len(urls))
+		hs     = make(map[string]*health, len(urls))
+	)
+	for i, j := range rand.Perm(len(urls)) {
+		ranked[i] = urls[j]
+		hs[urls[j]] = p.get(urls[j])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		hi, hj := hs[ranked[i]], hs[ranked[j]]
+		qi, qj := now.Before(hi.until), now.Before(hj.until)
+		if qi != qj {
+			return qj
+		}
+		if qi {
+			return hi.until.Before(hj.until)
+		}
+		li, lj := p.lag(hi) > p.config.MaxRoundLag, p.lag(hj) > p.config.MaxRoundLag
+		if li != lj {
+			return lj
+		}
+		return p.score(hi) < p.score(hj)
+	})
+	return ranked
+}
+
+// Select returns the n best ranked nodes. Quarantined nodes are only
+// returned when there are not enough other nodes.
+func (p *Pool) Select(urls []string, n int) []string {
+	ranked := p.Rank(urls)
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+	return ranked[:n]
+}
+
+// Available returns the nodes not in quarantine, in rank order. If all
+// the nodes are quarantined all of them are returned, so that callers
+// always have nodes to try.
+func (p *Pool) Available(urls []string) []string {
+	var (
+		ranked    = p.Rank(urls)
+		available = make([]string, 0, len(ranked))
+	)
+	for _, url := range ranked {
+		if !p.IsQuarantined(url) {
+			available = append(available, url)
+		}
+	}
+	if len(available) == 0 {
+		return ranked
+	}
+	return available
+}
+
+// PoolStats of the shared miners and sharders pools.
+type PoolStats struct {
+	Miners   []Stats `json:"miners"`
+	Sharders []Stats `json:"sharders"`
+}
+
+// GetPoolStats returns the stats of the shared Miners and Sharders pools.
+func GetPoolStats() *PoolStats {
+	return &PoolStats{
+		Miners:   Miners.Stats(),
+		Sharders: Sharders.Stats(),
+	}
+}
+
+// ReportResponse records the result of an HTTP request to the node. Server
+// errors and transport errors count as failures.
+func (p *Pool) ReportResponse(url string, start time.Time, statusCode int, err error) {
+	if err == nil && statusCode >= 500 {
+		err = errServerError
+	}
+	p.Report(url, time.Since(start), err)
+}
+
+// Stats returns the stats of all the known nodes ordered by URL.
+func (p *Pool) Stats() []Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var (
+		now   = p.now()
+		stats = make([]Stats, 0, len(p.nodes))
+	)
+	for url, h := range p.nodes {
+		s := Stats{
+			URL:                 url,
+			Requests:            h.requests,
+			Failures:            h.failures,
+			ConsecutiveFailures: h.consecutive,
+			ErrorRate:           h.errorRate,
+			LatencyMs:           h.latency,
+			Round:               h.round,
+			RoundLag:            p.lag(h),
+			Quarantines:         h.quarantines,
+			Healthy:             p.isHealthy(h, now),
+		}
+		if now.Before(h.until) {
+			s.QuarantinedUntil = h.until
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].URL < stats[j].URL })
+	return stats
+}
+
+// Reset forgets all the tracked nodes.
+func (p *Pool) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.nodes = make(map[string]*health)
+	p.maxRound = 0
+}
@@ -0,0 +1,103 @@
+package node
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool() (*Pool, *time.Time) {
+	var (
+		now = time.Unix(1000, 0)
+		p   = NewPool(DefaultConfig)
+	)
+	p.now = func() time.Time { return now }
+	return p, &now
+}
+
+func TestPoolQuarantine(t *testing.T) {
+	p, now := newTestPool()
+	var errTest = errors.New("test", "request failed")
+
+	for i := 0; i < DefaultConfig.MaxFailures-1; i++ {
+		p.Report("a", time.Millisecond, errTest)
+	}
+	require.False(t, p.IsQuarantined("a"))
+
+	p.Report("a", time.Millisecond, errTest)
+	require.True(t, p.IsQuarantined("a"))
+	require.Equal(t, []string{"b"}, p.Available([]string{"a", "b"}))
+
+	*now = now.Add(DefaultConfig.MinBackoff)
+	require.False(t, p.IsQuarantined("a"))
+
+	// a single failure after the quarantine doubles the backoff
+	p.Report("a", time.Millisecond, errTest)
+	require.True(t, p.IsQuarantined("a"))
+	*now = now.Add(DefaultConfig.MinBackoff)
+	require.True(t, p.IsQuarantined("a"))
+	*now = now.Add(DefaultConfig.MinBackoff)
+	require.False(t, p.IsQuarantined("a"))
+
+	p.Report("a", time.Millisecond, nil)
+	stats := p.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, "a", stats[0].URL)
+	require.True(t, stats[0].Healthy)
+	require.Equal(t, int64(4), stats[0].Failures)
+}
+
+func TestPoolRank(t *testing.T) {
+	p, _ := newTestPool()
+	var errTest = errors.New("test", "request failed")
+
+	p.Report("slow", 500*time.Millisecond, nil)
+	p.Report("fast", 10*time.Millisecond, nil)
+	p.Report("lagging", time.Millisecond, nil)
+	p.ReportRound("slow", 100)
+	p.ReportRound("fast", 100)
+	p.ReportRound("lagging", 10)
+	for i := 0; i < DefaultConfig.MaxFailures; i++ {
+		p.Report("down", time.Millisecond, errTest)
+	}
+
+	var urls = []string{"down", "lagging", "slow", "fast"}
+	for i := 0; i < 10; i++ {
+		require.Equal(t, []string{"fast", "slow", "lagging", "down"}, p.Rank(urls))
+	}
+	require.Equal(t, []string{"fast", "slow"}, p.Select(urls, 2))
+	require.Equal(t, []string{"down"}, p.Available([]string{"down"}))
+}
+
+func TestPoolReportCanceled(t *testing.T) {
+	p, _ := newTestPool()
+
+	for i := 0; i < DefaultConfig.MaxFailures; i++ {
+		p.Report("a", time.Millisecond, context.Canceled)
+		p.Report("a", time.Millisecond, &url.Error{Op: "Get", URL: "a", Err: context.Canceled})
+	}
+	require.False(t, p.IsQuarantined("a"))
+	require.Empty(t, p.Stats())
+
+	p.Report("a", time.Millisecond, context.DeadlineExceeded)
+	require.Equal(t, int64(1), p.Stats()[0].Failures)
+}
+
+func TestPoolRoundLag(t *testing.T) {
+	p, _ := newTestPool()
+
+	p.Report("a", time.Millisecond, nil)
+	p.Report("b", time.Millisecond, nil)
+	p.ReportRound("a", 100)
+	p.ReportRound("b", 100-DefaultConfig.MaxRoundLag-1)
+
+	require.Equal(t, []string{"a"}, p.Select([]string{"b", "a"}, 1))
+	stats := p.Stats()
+	require.True(t, stats[0].Healthy)
+	require.False(t, stats[1].Healthy)
+	require.Equal(t, DefaultConfig.MaxRoundLag+1, stats[1].RoundLag)
+}
@@ -0,0 +1,84 @@
+package transaction
+
+const (
+	StorageSmartContractAddress      = `6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d7`
+	VestingSmartContractAddress      = `2bba5b05949ea59c80aed3ac3474d7379d3be737e8eb5a968c52295e48333ead`
+	FaucetSmartContractAddress       = `6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d3`
+	InterestPoolSmartContractAddress = `cf8d0df9bd8cc637a4ff4e792ffe3686da6220c45f0e1103baa609f3f1751ef4`
+	MultiSigSmartContractAddress     = `27b5ef7120252b79f9dd9c05505dd28f328c80f6863ee446daede08a84d651a7`
+	MinerSmartContractAddress        = `6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d9`
+
+	MULTISIG_REGISTER = "register"
+	MULTISIG_VOTE     = "vote"
+)
+
+// The 0chain smart contracts. Functions returning a plain text output
+// declare no output type.
+var StorageSmartContract = NewSmartContract(StorageSmartContractAddress).
+	WithMethod(NEW_ALLOCATION_REQUEST, NewAllocationRequest{}, nil).
+	WithMethod(NEW_FREE_ALLOCATION, FreeAllocationRequest{}, nil).
+	WithMethod(UPDATE_ALLOCATION_REQUEST, UpdateAllocationRequest{}, nil).
+	WithMethod(FREE_UPDATE_ALLOCATION, FreeUpdateAllocationRequest{}, nil).
+	WithMethod(ADD_FREE_ALLOCATION_ASSIGNER, FreeStorageAssignerRequest{}, nil).
+	WithMethod(STORAGESC_FINALIZE_ALLOCATION, AllocationIDRequest{}, nil).
+	WithMethod(STORAGESC_CANCEL_ALLOCATION, AllocationIDRequest{}, nil).
+	WithMethod(STORAGESC_CREATE_READ_POOL, nil, nil).
+	WithMethod(STORAGESC_READ_POOL_LOCK, PoolLockRequest{}, nil).
+	WithMethod(STORAGESC_READ_POOL_UNLOCK, PoolUnlockRequest{}, nil).
+	WithMethod(STORAGESC_STAKE_POOL_LOCK, StakePoolRequest{}, nil).
+	WithMethod(STORAGESC_STAKE_POOL_UNLOCK, StakePoolRequest{}, StakePoolUnstake{}).
+	WithMethod(STORAGESC_STAKE_POOL_PAY_INTERESTS, StakePoolRequest{}, nil).
+	WithMethod(STORAGESC_UPDATE_BLOBBER_SETTINGS, StorageNode{}, nil).
+	WithMethod(STORAGESC_WRITE_POOL_LOCK, PoolLockRequest{}, nil).
+	WithMethod(STORAGESC_WRITE_POOL_UNLOCK, PoolUnlockRequest{}, nil).
+	WithMethod(STORAGESC_ADD_CURATOR, AddCuratorRequest{}, nil).
+	WithMethod(STORAGESC_CURATOR_TRANSFER, CuratorTransferRequest{}, nil).
+	WithQuery("getConfig", "/getConfig", StorageSCConfig{}).
+	WithQuery("getChallengePoolStat", "/getChallengePoolStat", ChallengePoolStat{}, "allocation_id").
+	WithQuery("allocation", "/allocation", Allocation{}, "allocation").
+	WithQuery("allocations", "/allocations", []*Allocation{}, "client").
+	WithQuery("allocation_min_lock", "/allocation_min_lock", AllocationMinLock{}, "allocation_data").
+	WithQuery("getReadPoolStat", "/getReadPoolStat", AllocationPoolStats{}, "client_id").
+	WithQuery("getWritePoolStat", "/getWritePoolStat", AllocationPoolStats{}, "client_id").
+	WithQuery("getStakePoolStat", "/getStakePoolStat", StakePoolStat{}, "blobber_id").
+	WithQuery("getUserStakePoolStat", "/getUserStakePoolStat", UserStakePoolStat{}, "client_id").
+	WithQuery("getblobbers", "/getblobbers", StorageNodes{}).
+	WithQuery("getBlobber", "/getBlobber", StorageNode{}, "blobber_id")
+
+var VestingSmartContract = NewSmartContract(VestingSmartContractAddress).
+	WithMethod(VESTING_TRIGGER, VestingPoolRequest{}, nil).
+	WithMethod(VESTING_STOP, VestingStopRequest{}, nil).
+	WithMethod(VESTING_UNLOCK, VestingPoolRequest{}, nil).
+	WithMethod(VESTING_ADD, VestingAddRequest{}, nil).
+	WithMethod(VESTING_DELETE, VestingPoolRequest{}, nil).
+	WithMethod(VESTING_UPDATE_CONFIG, VestingSCConfig{}, nil).
+	WithQuery("getConfig", "/getConfig", VestingSCConfig{}).
+	WithQuery("getPoolInfo", "/getPoolInfo", VestingPoolInfo{}, "pool_id").
+	WithQuery("getClientPools", "/getClientPools", VestingClientPools{}, "client_id")
+
+var MinerSmartContract = NewSmartContract(MinerSmartContractAddress).
+	WithMethod(MINERSC_LOCK, MinerNodeRequest{}, nil).
+	WithMethod(MINERSC_UNLOCK, MinerNodeRequest{}, nil).
+	WithMethod(MINERSC_SETTINGS, MinerNodeSettings{}, nil).
+	WithQuery("nodeStat", "/nodeStat", MinerNode{}, "id").
+	WithQuery("nodePoolStat", "/nodePoolStat", MinerDelegatePoolStat{}, "id", "pool_id").
+	WithQuery("configs", "/configs", MinerSCConfig{}).
+	WithQuery("getUserPools", "/getUserPools", MinerUserPools{}, "client_id").
+	WithQuery("getMinerList", "/getMinerList", MinerNodes{}).
+	WithQuery("getSharderList", "/getSharderList", MinerNodes{})
+
+var InterestPoolSmartContract = NewSmartContract(InterestPoolSmartContractAddress).
+	WithMethod(LOCK_TOKEN, InterestPoolLockRequest{}, nil).
+	WithMethod(UNLOCK_TOKEN, InterestPoolUnlockRequest{}, nil).
+	WithQuery("getLockConfig", "/getLockConfig", InterestPoolConfig{}).
+	WithQuery("getPoolsStats", "/getPoolsStats", InterestPoolStats{}, "client_id")
+
+var MultiSigSmartContract = NewSmartContract(MultiSigSmartContractAddress).
+	WithMethod(MULTISIG_REGISTER, MultiSigWallet{}, nil).
+	WithMethod(MULTISIG_VOTE, MultiSigVote{}, nil).
+	WithQuery("getProposal", "/getProposal", MultiSigProposal{}, "client_id", "proposal_id").
+	WithQuery("getProposals", "/getProposals", []*MultiSigProposal{}, "client_id")
+
+// FaucetSmartContract declares no function, the faucet functions
+// depend on the network.
+var FaucetSmartContract = NewSmartContract(FaucetSmartContractAddress)
@@ -0,0 +1,459 @@
+package transaction
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/0chain/gosdk/core/common"
+)
+
+// Inputs and outputs of the smart contract functions and REST endpoints.
+// Callers may use their own types for them: an input must not have fields
+// the declared input lacks, an output must have all the fields of the
+// declared output.
+
+//
+// storage SC
+//
+
+type PriceRange struct {
+	Min common.Balance `json:"min"`
+	Max common.Balance `json:"max"`
+}
+
+type NewAllocationRequest struct {
+	DataShards                 int              `json:"data_shards"`
+	ParityShards               int              `json:"parity_shards"`
+	Size                       common.Size      `json:"size"`
+	Expiration                 common.Timestamp `json:"expiration_date"`
+	Owner                      string           `json:"owner_id"`
+	OwnerPublicKey             string           `json:"owner_public_key"`
+	PreferredBlobbers          []string         `json:"preferred_blobbers"`
+	ReadPriceRange             PriceRange       `json:"read_price_range"`
+	WritePriceRange            PriceRange       `json:"write_price_range"`
+	MaxChallengeCompletionTime time.Duration    `json:"max_challenge_completion_time"`
+	DiversifyBlobbers          bool             `json:"diversify_blobbers"`
+}
+
+type FreeAllocationRequest struct {
+	RecipientPublicKey string `json:"recipient_public_key"`
+	Marker             string `json:"marker"`
+}
+
+type UpdateAllocationRequest struct {
+	ID              string           `json:"id"`
+	Owner           string           `json:"owner_id"`
+	Size            int64            `json:"size"`
+	Expiration      common.Timestamp `json:"expiration_date"`
+	SetImmutable    bool             `json:"set_immutable"`
+	AddBlobberID    string           `json:"add_blobber_id"`
+	RemoveBlobberID string           `json:"remove_blobber_id"`
+}
+
+type FreeUpdateAllocationRequest struct {
+	AllocationID string `json:"allocation_id"`
+	Marker       string `json:"marker"`
+}
+
+type FreeStorageAssignerRequest struct {
+	Name            string  `json:"name"`
+	PublicKey       string  `json:"public_key"`
+	IndividualLimit float64 `json:"individual_limit"`
+	TotalLimit      float64 `json:"total_limit"`
+}
+
+type AllocationIDRequest struct {
+	AllocationID string `json:"allocation_id"`
+}
+
+type AddCuratorRequest struct {
+	CuratorID    string `json:"curator_id"`
+	AllocationID string `json:"allocation_id"`
+}
+
+type CuratorTransferRequest struct {
+	AllocationID      string `json:"allocation_id"`
+	NewOwnerID        string `json:"new_owner_id"`
+	NewOwnerPublicKey string `json:"new_owner_public_key"`
+}
+
+type PoolLockRequest struct {
+	Duration     time.Duration `json:"duration"`
+	AllocationID string        `json:"allocation_id"`
+	BlobberID    string        `json:"blobber_id,omitempty"`
+}
+
+type PoolUnlockRequest struct {
+	PoolID string `json:"pool_id"`
+}
+
+type StakePoolRequest struct {
+	BlobberID string `json:"blobber_id,omitempty"`
+	PoolID    string `json:"pool_id,omitempty"`
+}
+
+type Terms struct {
+	ReadPrice               common.Balance `json:"read_price"`
+	WritePrice              common.Balance `json:"write_price"`
+	MinLockDemand           float64        `json:"min_lock_demand"`
+	MaxOfferDuration        time.Duration  `json:"max_offer_duration"`
+	ChallengeCompletionTime time.Duration  `json:"challenge_completion_time"`
+}
+
+type StakePoolSettings struct {
+	DelegateWallet string         `json:"delegate_wallet"`
+	MinStake       common.Balance `json:"min_stake"`
+	MaxStake       common.Balance `json:"max_stake"`
+	NumDelegates   int            `json:"num_delegates"`
+	ServiceCharge  float64        `json:"service_charge,omitempty"`
+}
+
+// StorageNode is a blobber, the input of the blobber settings update.
+type StorageNode struct {
+	ID                common.Key        `json:"id"`
+	BaseURL           string            `json:"url"`
+	Terms             Terms             `json:"terms"`
+	Capacity          common.Size       `json:"capacity"`
+	Used              common.Size       `json:"used"`
+	LastHealthCheck   common.Timestamp  `json:"last_health_check"`
+	StakePoolSettings StakePoolSettings `json:"stake_pool_settings"`
+}
+
+type StorageNodes struct {
+	Nodes []*StorageNode `json:"Nodes"`
+}
+
+type StorageReadPoolConfig struct {
+	MinLock       common.Balance `json:"min_lock"`
+	MinLockPeriod time.Duration  `json:"min_lock_period"`
+	MaxLockPeriod time.Duration  `json:"max_lock_period"`
+}
+
+type StorageWritePoolConfig struct {
+	MinLock       common.Balance `json:"min_lock"`
+	MinLockPeriod time.Duration  `json:"min_lock_period"`
+	MaxLockPeriod time.Duration  `json:"max_lock_period"`
+}
+
+type StorageStakePoolConfig struct {
+	MinLock          common.Balance `json:"min_lock"`
+	InterestRate     float64        `json:"interest_rate"`
+	InterestInterval time.Duration  `json:"interest_interval"`
+}
+
+type StorageSCConfig struct {
+	MinAllocSize               common.Size             `json:"min_alloc_size"`
+	MinAllocDuration           time.Duration           `json:"min_alloc_duration"`
+	MaxChallengeCompletionTime time.Duration           `json:"max_challenge_completion_time"`
+	MinOfferDuration           time.Duration           `json:"min_offer_duration"`
+	MinBlobberCapacity         common.Size             `json:"min_blobber_capacity"`
+	ReadPool                   *StorageReadPoolConfig  `json:"readpool"`
+	WritePool                  *StorageWritePoolConfig `json:"writepool"`
+	StakePool                  *StorageStakePoolConfig `json:"stakepool"`
+	ValidatorReward            float64                 `json:"validator_reward"`
+	BlobberSlash               float64                 `json:"blobber_slash"`
+	MaxReadPrice               common.Balance          `json:"max_read_price"`
+	MaxWritePrice              common.Balance          `json:"max_write_price"`
+	MaxDelegates               int                     `json:"max_delegates"`
+	MaxCharge                  float64                 `json:"max_charge"`
+	TimeUnit                   time.Duration           `json:"time_unit"`
+}
+
+type AllocationBlobber struct {
+	ID      string `json:"id"`
+	BaseURL string `json:"url"`
+}
+
+type Allocation struct {
+	ID              string               `json:"id"`
+	Tx              string               `json:"tx"`
+	DataShards      int                  `json:"data_shards"`
+	ParityShards    int                  `json:"parity_shards"`
+	Size            int64                `json:"size"`
+	Expiration      int64                `json:"expiration_date"`
+	Owner           string               `json:"owner_id"`
+	OwnerPublicKey  string               `json:"owner_public_key"`
+	Blobbers        []*AllocationBlobber `json:"blobbers"`
+	ReadPriceRange  PriceRange           `json:"read_price_range"`
+	WritePriceRange PriceRange           `json:"write_price_range"`
+	Finalized       bool                 `json:"finalized,omitempty"`
+	Canceled        bool                 `json:"canceled,omitempty"`
+}
+
+type AllocationMinLock struct {
+	MinLockDemand int64 `json:"min_lock_demand"`
+}
+
+type ChallengePoolStat struct {
+	ID         string           `json:"id"`
+	Balance    common.Balance   `json:"balance"`
+	StartTime  common.Timestamp `json:"start_time"`
+	Expiration common.Timestamp `json:"expiration"`
+	Finalized  bool             `json:"finalized"`
+}
+
+type BlobberPoolStat struct {
+	BlobberID common.Key     `json:"blobber_id"`
+	Balance   common.Balance `json:"balance"`
+}
+
+type AllocationPoolStat struct {
+	ID           string             `json:"id"`
+	Balance      common.Balance     `json:"balance"`
+	ExpireAt     common.Timestamp   `json:"expire_at"`
+	AllocationID common.Key         `json:"allocation_id"`
+	Blobbers     []*BlobberPoolStat `json:"blobbers"`
+	Locked       bool               `json:"locked"`
+}
+
+type AllocationPoolStats struct {
+	Pools []*AllocationPoolStat `json:"pools"`
+}
+
+type StakePoolDelegateStat struct {
+	ID         common.Key     `json:"id"`
+	Balance    common.Balance `json:"balance"`
+	DelegateID common.Key     `json:"delegate_id"`
+	Rewards    common.Balance `json:"rewards"`
+	Interests  common.Balance `json:"interests"`
+	Penalty    common.Balance `json:"penalty"`
+}
+
+type StakePoolStat struct {
+	ID          common.Key               `json:"pool_id"`
+	Balance     common.Balance           `json:"balance"`
+	Unstake     common.Balance           `json:"unstake"`
+	Free        common.Size              `json:"free"`
+	Capacity    common.Size              `json:"capacity"`
+	WritePrice  common.Balance           `json:"write_price"`
+	OffersTotal common.Balance           `json:"offers_total"`
+	Delegate    []*StakePoolDelegateStat `json:"delegate"`
+	Settings    StakePoolSettings        `json:"settings"`
+}
+
+type UserStakePoolStat struct {
+	Pools map[common.Key][]*StakePoolDelegateStat `json:"pools"`
+}
+
+type StakePoolUnstake struct {
+	Unstake common.Timestamp `json:"unstake"`
+}
+
+//
+// vesting SC
+//
+
+type VestingPoolRequest struct {
+	PoolID common.Key `json:"pool_id"`
+}
+
+type VestingStopRequest struct {
+	PoolID      common.Key `json:"pool_id"`
+	Destination common.Key `json:"destination"`
+}
+
+type VestingDest struct {
+	ID     common.Key     `json:"id"`
+	Amount common.Balance `json:"amount"`
+}
+
+type VestingAddRequest struct {
+	Description  string           `json:"description"`
+	StartTime    common.Timestamp `json:"start_time"`
+	Duration     time.Duration    `json:"duration"`
+	Destinations []*VestingDest   `json:"destinations"`
+}
+
+type VestingSCConfig struct {
+	MinLock              common.Balance `json:"min_lock"`
+	MinDuration          time.Duration  `json:"min_duration"`
+	MaxDuration          time.Duration  `json:"max_duration"`
+	MaxDestinations      int            `json:"max_destinations"`
+	MaxDescriptionLength int            `json:"max_description_length"`
+}
+
+type VestingDestInfo struct {
+	ID     common.Key       `json:"id"`
+	Wanted common.Balance   `json:"wanted"`
+	Earned common.Balance   `json:"earned"`
+	Vested common.Balance   `json:"vested"`
+	Last   common.Timestamp `json:"last"`
+}
+
+type VestingPoolInfo struct {
+	ID           common.Key         `json:"pool_id"`
+	Balance      common.Balance     `json:"balance"`
+	Left         common.Balance     `json:"left"`
+	Description  string             `json:"description"`
+	StartTime    common.Timestamp   `json:"start_time"`
+	ExpireAt     common.Timestamp   `json:"expire_at"`
+	Destinations []*VestingDestInfo `json:"destinations"`
+	ClientID     common.Key         `json:"client_id"`
+}
+
+type VestingClientPools struct {
+	Pools []common.Key `json:"pools"`
+}
+
+//
+// miner SC
+//
+
+type MinerNodeRequest struct {
+	ID     string `json:"id"`
+	PoolID string `json:"pool_id,omitempty"`
+}
+
+type MinerNodeStat struct {
+	BlockReward      common.Balance `json:"block_reward,omitempty"`
+	ServiceCharge    common.Balance `json:"service_charge,omitempty"`
+	UsersFee         common.Balance `json:"users_fee,omitempty"`
+	BlockShardersFee common.Balance `json:"block_sharders_fee,omitempty"`
+	SharderRewards   common.Balance `json:"sharder_rewards,omitempty"`
+}
+
+type SimpleMinerNode struct {
+	ID                string         `json:"id"`
+	BaseURL           string         `json:"url"`
+	DelegateWallet    string         `json:"delegate_wallet"`
+	ServiceCharge     float64        `json:"service_charge"`
+	NumberOfDelegates int            `json:"number_of_delegates"`
+	MinStake          common.Balance `json:"min_stake"`
+	MaxStake          common.Balance `json:"max_stake"`
+	Stat              MinerNodeStat  `json:"stat"`
+}
+
+// MinerNodeSettings is the input of the miner settings update. The
+// delegate pools are not updated by it.
+type MinerNodeSettings struct {
+	SimpleMiner *SimpleMinerNode           `json:"simple_miner"`
+	Pending     map[string]json.RawMessage `json:"pending"`
+	Active      map[string]json.RawMessage `json:"active"`
+	Deleting    map[string]json.RawMessage `json:"deleting"`
+}
+
+type MinerNode struct {
+	ID                string  `json:"id"`
+	N2NHost           string  `json:"n2n_host"`
+	Host              string  `json:"host"`
+	Port              int     `json:"port"`
+	PublicKey         string  `json:"public_key"`
+	ShortName         string  `json:"short_name"`
+	DelegateWallet    string  `json:"delegate_wallet"`
+	ServiceCharge     float64 `json:"service_charge"`
+	NumberOfDelegates int     `json:"number_of_delegates"`
+	MinStake          int64   `json:"min_stake"`
+	MaxStake          int64   `json:"max_stake"`
+}
+
+type MinerNodes struct {
+	Nodes []struct {
+		Miner MinerNode `json:"simple_miner"`
+	} `json:"Nodes"`
+}
+
+type MinerDelegatePoolStat struct {
+	ID           common.Key     `json:"id"`
+	Balance      common.Balance `json:"balance"`
+	InterestPaid common.Balance `json:"interest_paid"`
+	RewardPaid   common.Balance `json:"reward_paid"`
+	Status       string         `json:"status"`
+	High         common.Balance `json:"high"`
+	Low          common.Balance `json:"low"`
+}
+
+type MinerUserPools struct {
+	Pools map[string]map[string][]*MinerDelegatePoolStat `json:"pools"`
+}
+
+type MinerSCConfig struct {
+	ViewChange   int64          `json:"view_change"`
+	MaxN         int            `json:"max_n"`
+	MinN         int            `json:"min_n"`
+	MinS         int            `json:"min_s"`
+	MaxS         int            `json:"max_s"`
+	TPercent     float64        `json:"t_percent"`
+	KPercent     float64        `json:"k_percent"`
+	MaxStake     common.Balance `json:"max_stake"`
+	MinStake     common.Balance `json:"min_stake"`
+	InterestRate float64        `json:"interest_rate"`
+	RewardRate   float64        `json:"reward_rate"`
+	ShareRatio   float64        `json:"share_ratio"`
+	BlockReward  common.Balance `json:"block_reward"`
+	MaxCharge    float64        `json:"max_charge"`
+	Epoch        int64          `json:"epoch"`
+	MaxDelegates int            `json:"max_delegates"`
+}
+
+//
+// interest pool SC
+//
+
+type InterestPoolLockRequest struct {
+	Duration string `json:"duration"`
+}
+
+type InterestPoolUnlockRequest struct {
+	PoolID string `json:"pool_id"`
+}
+
+type InterestPoolGlobalNode struct {
+	MaxMint     common.Balance `json:"max_mint"`
+	TotalMinted common.Balance `json:"total_minted"`
+	MinLock     common.Balance `json:"min_lock"`
+	APR         float64        `json:"apr"`
+}
+
+type InterestPoolConfig struct {
+	ID               string                  `json:"ID"`
+	SimpleGlobalNode *InterestPoolGlobalNode `json:"simple_global_node"`
+	MinLockPeriod    time.Duration           `json:"min_lock_period"`
+}
+
+type InterestPoolStat struct {
+	ID           common.Key       `json:"pool_id"`
+	StartTime    common.Timestamp `json:"start_time"`
+	Duration     time.Duration    `json:"duration"`
+	TimeLeft     time.Duration    `json:"time_left"`
+	Locked       bool             `json:"locked"`
+	APR          float64          `json:"apr"`
+	TokensEarned common.Balance   `json:"tokens_earned"`
+	Balance      common.Balance   `json:"balance"`
+}
+
+type InterestPoolStats struct {
+	Stats []*InterestPoolStat `json:"stats"`
+}
+
+//
+// multisig SC
+//
+
+type MultiSigWallet struct {
+	ClientID           string   `json:"client_id"`
+	SignatureScheme    string   `json:"signature_scheme"`
+	PublicKey          string   `json:"public_key"`
+	SignerThresholdIDs []string `json:"signer_threshold_ids"`
+	SignerPublicKeys   []string `json:"signer_public_keys"`
+	NumRequired        int      `json:"num_required"`
+}
+
+type MultiSigTransfer struct {
+	ClientID   string `json:"from"`
+	ToClientID string `json:"to"`
+	Amount     int64  `json:"amount"`
+}
+
+type MultiSigVote struct {
+	ProposalID string           `json:"proposal_id"`
+	Transfer   MultiSigTransfer `json:"transfer"`
+	Signature  string           `json:"signature"`
+}
+
+type MultiSigProposal struct {
+	ProposalID       string           `json:"proposal_id"`
+	ExpirationDate   common.Timestamp `json:"expiration_date"`
+	Transfer         MultiSigTransfer `json:"transfer"`
+	SignerSignatures []string         `json:"signer_signatures"`
+	ClientSignature  string           `json:"client_signature"`
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/node"
 	"github.com/0chain/gosdk/core/util"
 )
 
@@ -32,6 +34,7 @@ type Transaction struct {
 	TransactionOutput string `json:"transaction_output,omitempty"`
 	TransactionFee    int64  `json:"transaction_fee"`
 	OutputHash        string `json:"txn_output_hash"`
+	Status            int    `json:"transaction_status,omitempty"`
 }
 
 //TxnReceipt - a transaction receipt is a processed transaction that contains the output
@@ -178,26 +181,31 @@ func (t *Transaction) VerifyTransaction(verifyHandler VerifyFunc) (bool, error)
 	return verifyHandler(t.Signature, t.Hash, t.PublicKey)
 }
 
+// SendTransactionSync sends the transaction to the miners not in
+// quarantine and waits for all of them to respond.
 func SendTransactionSync(txn *Transaction, miners []string) {
+	miners = node.Miners.Available(miners)
 	wg := sync.WaitGroup{}
 	wg.Add(len(miners))
 	for _, miner := range miners {
-		url := fmt.Sprintf("%v/%v", miner, TXN_SUBMIT_URL)
-		go sendTransactionToURL(url, txn, &wg)
+		go sendTransactionToMiner(miner, txn, &wg)
 	}
 	wg.Wait()
 }
 
-func sendTransactionToURL(url string, txn *Transaction, wg *sync.WaitGroup) ([]byte, error) {
+func sendTransactionToMiner(miner string, txn *Transaction, wg *sync.WaitGroup) ([]byte, error) {
 	if wg != nil {
 		defer wg.Done()
 	}
+	url := fmt.Sprintf("%v/%v", miner, TXN_SUBMIT_URL)
 	postReq, err := util.NewHTTPPostRequest(url, txn)
 	if err != nil {
 		//Logger.Error("Error in serializing the transaction", txn, err.Error())
 		return nil, err
 	}
+	start := time.Now()
 	postResponse, err := postReq.Post()
+	node.Miners.ReportResponse(miner, start, postResponse.StatusCode, err)
 	if postResponse.StatusCode >= 200 && postResponse.StatusCode <= 299 {
 		return []byte(postResponse.Body), nil
 	}
@@ -205,6 +213,7 @@ func sendTransactionToURL(url string, txn *Transaction, wg *sync.WaitGroup) ([]b
 }
 
 func VerifyTransaction(txnHash string, sharders []string) (*Transaction, error) {
+	sharders = node.Sharders.Available(sharders)
 	numSharders := len(sharders)
 	numSuccess := 0
 	var retTxn *Transaction
@@ -217,7 +226,9 @@ func VerifyTransaction(txnHash string, sharders []string) (*Transaction, error)
 			numSharders--
 			continue
 		}
+		start := time.Now()
 		response, err := req.Get()
+		node.Sharders.ReportResponse(sharder, start, response.StatusCode, err)
 		if err != nil {
 			customError = errors.Wrap(customError, err)
 			numSharders--
@@ -248,6 +259,9 @@ func VerifyTransaction(txnHash string, sharders []string) (*Transaction, error)
 				customError = errors.Wrap(customError, err)
 				continue
 			}
+			if status, ok := objmap["transaction_status"]; ok && txn.Status == 0 {
+				json.Unmarshal(status, &txn.Status)
+			}
 			if len(txn.Signature) > 0 {
 				retTxn = txn
 			}
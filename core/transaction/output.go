@@ -0,0 +1,120 @@
+package transaction
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+// Codes of the errors of rejected smart contract transactions. Rejections
+// with a known error code of the smart contract get the matching code,
+// others keep the code given by the smart contract, or get
+// ErrCodeSmartContract when it has none.
+const (
+	ErrCodeInsufficientBalance = "insufficient_balance"
+	ErrCodeNotOwner            = "not_owner"
+	ErrCodePoolLocked          = "pool_locked"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeSmartContract       = "smart_contract_error"
+)
+
+// scErrorCodes maps the error codes returned by the smart contracts to the
+// codes of the rejections.
+var scErrorCodes = map[string]string{
+	"insufficient_balance": ErrCodeInsufficientBalance,
+	"insufficient_funds":   ErrCodeInsufficientBalance,
+	"not_owner":            ErrCodeNotOwner,
+	"unauthorized":         ErrCodeNotOwner,
+	"unauthorized_access":  ErrCodeNotOwner,
+	"pool_locked":          ErrCodePoolLocked,
+	"not_expired":          ErrCodePoolLocked,
+	"not_found":            ErrCodeNotFound,
+	"value_not_present":    ErrCodeNotFound,
+	"node_not_found":       ErrCodeNotFound,
+}
+
+// parseSmartContractError splits the output of a rejected transaction,
+// formatted by the smart contracts as "code: message" or as a JSON error,
+// into code and message.
+func parseSmartContractError(output string) (code, msg string) {
+	msg = strings.TrimSpace(output)
+	var jsonErr struct {
+		Code  string `json:"code"`
+		Error string `json:"error"`
+		Msg   string `json:"msg"`
+	}
+	if json.Unmarshal([]byte(msg), &jsonErr) == nil && jsonErr.Code != "" {
+		if jsonErr.Error != "" {
+			return jsonErr.Code, jsonErr.Error
+		}
+		return jsonErr.Code, jsonErr.Msg
+	}
+	if i := strings.Index(msg, ": "); i > 0 && !strings.ContainsAny(msg[:i], " \t\"{") {
+		return msg[:i], msg[i+2:]
+	}
+	return "", msg
+}
+
+// NewSmartContractError returns the error of a smart contract transaction
+// rejected with the given output.
+func NewSmartContractError(output string) *errors.Error {
+	code, msg := parseSmartContractError(output)
+	if known, ok := scErrorCodes[code]; ok {
+		// keep the smart contract code when it differs
+		if known != code {
+			msg = code + ": " + msg
+		}
+		return errors.New(known, msg)
+	}
+	if code == "" {
+		return errors.New(ErrCodeSmartContract, msg)
+	}
+	return errors.New(code, msg)
+}
+
+// IsFailed reports whether the transaction was confirmed as failed.
+func (t *Transaction) IsFailed() bool {
+	return t.Status == TxnStatusFailure
+}
+
+// OutputError returns the error of the confirmed transaction, nil if
+// it did not fail.
+func (t *Transaction) OutputError() error {
+	if !t.IsFailed() {
+		return nil
+	}
+	return NewSmartContractError(t.TransactionOutput)
+}
+
+// SmartContractData returns the smart contract call of the transaction.
+func (t *Transaction) SmartContractData() (*SmartContractTxnData, error) {
+	if t.TransactionType != TxnTypeSmartContract {
+		return nil, errors.New("not_smart_contract", "not a smart contract transaction")
+	}
+	var sn SmartContractTxnData
+	if err := json.Unmarshal([]byte(t.TransactionData), &sn); err != nil {
+		return nil, errors.Wrap(err, "error decoding smart contract data")
+	}
+	return &sn, nil
+}
+
+// DecodeOutput returns the error of the confirmed transaction if it
+// failed, or decodes its output into out, checking it against the output
+// type declared for the smart contract function. A *string out receives
+// the raw output.
+func (t *Transaction) DecodeOutput(out interface{}) error {
+	if err := t.OutputError(); err != nil {
+		return err
+	}
+	sn, err := t.SmartContractData()
+	if err != nil {
+		return decode(nil, []byte(t.TransactionOutput), out)
+	}
+	sc, err := GetSmartContract(t.ToClientID)
+	if err != nil {
+		// the output of an undeclared smart contract is not checked
+		return decode(nil, []byte(t.TransactionOutput), out)
+	}
+	return sc.DecodeOutput(sn.Name, t.TransactionOutput, out)
+}
@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSmartContractError(t *testing.T) {
+	var tests = []struct {
+		output string
+		code   string
+	}{
+		{"insufficient_balance: Balance not sufficient for transfer", ErrCodeInsufficientBalance},
+		{"unauthorized_access: only owner can update the allocation", ErrCodeNotOwner},
+		{"value_not_present: allocation not found", ErrCodeNotFound},
+		{`{"code":"pool_locked","error":"pool is not expired yet"}`, ErrCodePoolLocked},
+		{"stake_pool_lock_failed: insufficient balance", "stake_pool_lock_failed"},
+		{"invalid_request: bad input", "invalid_request"},
+		{"something went wrong: try again", ErrCodeSmartContract},
+		{"something went wrong", ErrCodeSmartContract},
+	}
+	for _, tt := range tests {
+		err := NewSmartContractError(tt.output)
+		require.Equal(t, tt.code, err.Code, tt.output)
+	}
+}
+
+func TestTransactionDecodeOutput(t *testing.T) {
+	data, err := json.Marshal(&SmartContractTxnData{Name: STORAGESC_STAKE_POOL_LOCK})
+	require.NoError(t, err)
+
+	var txn = &Transaction{
+		ToClientID:        StorageSmartContractAddress,
+		TransactionType:   TxnTypeSmartContract,
+		TransactionData:   string(data),
+		TransactionOutput: "pool_id",
+		Status:            TxnStatusSuccess,
+	}
+	var out string
+	require.NoError(t, txn.DecodeOutput(&out))
+	require.Equal(t, "pool_id", out)
+
+	txn.Status = TxnStatusFailure
+	txn.TransactionOutput = "insufficient_balance: no tokens to lock"
+	err = txn.DecodeOutput(&out)
+	require.Error(t, err)
+	require.Equal(t, ErrCodeInsufficientBalance, err.(*errors.Error).Code)
+
+	txn.Status = TxnStatusSuccess
+	txn.TransactionOutput = `{"unstake":10}`
+	txn.TransactionData = `{"name":"` + STORAGESC_STAKE_POOL_UNLOCK + `"}`
+	var unstake StakePoolUnstake
+	require.NoError(t, txn.DecodeOutput(&unstake))
+	require.EqualValues(t, 10, unstake.Unstake)
+	txn.TransactionOutput = `{"unstake":"soon"}`
+	require.Error(t, txn.DecodeOutput(&unstake))
+}
@@ -0,0 +1,339 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+// SmartContractMethod declares a smart contract function executed by
+// transaction. A nil Input accepts any input and a nil Output keeps the
+// raw transaction output. Inputs and outputs of other types are accepted
+// when they are compatible with the declared ones.
+type SmartContractMethod struct {
+	Name   string
+	Input  reflect.Type
+	Output reflect.Type
+}
+
+// SmartContractQuery declares a smart contract REST endpoint. Params are
+// the required query parameters. A nil Output keeps the raw response.
+type SmartContractQuery struct {
+	Name   string
+	Path   string
+	Params []string
+	Output reflect.Type
+}
+
+// SmartContract declares the functions and the REST endpoints of a smart
+// contract. A contract without declared functions accepts any function.
+type SmartContract struct {
+	Address string
+
+	mutex   sync.RWMutex
+	methods map[string]*SmartContractMethod
+	queries map[string]*SmartContractQuery
+}
+
+var (
+	smartContractsMutex sync.Mutex
+	smartContracts      = make(map[string]*SmartContract)
+)
+
+// NewSmartContract creates and registers the smart contract declaration
+// of the address, replacing any previous one.
+func NewSmartContract(address string) *SmartContract {
+	var sc = &SmartContract{
+		Address: address,
+		methods: make(map[string]*SmartContractMethod),
+		queries: make(map[string]*SmartContractQuery),
+	}
+	smartContractsMutex.Lock()
+	smartContracts[address] = sc
+	smartContractsMutex.Unlock()
+	return sc
+}
+
+// GetSmartContract returns the declaration of the smart contract of the
+// address.
+func GetSmartContract(address string) (*SmartContract, error) {
+	smartContractsMutex.Lock()
+	defer smartContractsMutex.Unlock()
+
+	sc, ok := smartContracts[address]
+	if !ok {
+		return nil, errors.New("unknown_sc", "smart contract "+address+" is not declared")
+	}
+	return sc, nil
+}
+
+func typeOf(v interface{}) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(reflect.Type); ok {
+		return t
+	}
+	var t = reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// WithMethod declares a function of the smart contract. The input and the
+// output are sample values, pointers to them or their reflect.Type.
+func (sc *SmartContract) WithMethod(name string, input, output interface{}) *SmartContract {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	sc.methods[name] = &SmartContractMethod{
+		Name:   name,
+		Input:  typeOf(input),
+		Output: typeOf(output),
+	}
+	return sc
+}
+
+// WithQuery declares a REST endpoint of the smart contract.
+func (sc *SmartContract) WithQuery(name, path string, output interface{}, params ...string) *SmartContract {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	sc.queries[name] = &SmartContractQuery{
+		Name:   name,
+		Path:   path,
+		Params: params,
+		Output: typeOf(output),
+	}
+	return sc
+}
+
+// Method returns the declared function.
+func (sc *SmartContract) Method(name string) (*SmartContractMethod, bool) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	m, ok := sc.methods[name]
+	return m, ok
+}
+
+// Methods returns names of the declared functions, sorted.
+func (sc *SmartContract) Methods() (names []string) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	for name := range sc.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Query returns the declared REST endpoint.
+func (sc *SmartContract) Query(name string) (*SmartContractQuery, bool) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	q, ok := sc.queries[name]
+	return q, ok
+}
+
+// checkInput checks that the input has no field the expected type lacks
+// and that its fields have the expected types.
+func checkInput(expected reflect.Type, input interface{}) error {
+	if expected == nil || input == nil {
+		return nil
+	}
+	if typeOf(input) == expected {
+		return nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return errors.Wrap(err, "error encoding smart contract input")
+	}
+	if err = decodeStrict(data, reflect.New(expected).Interface()); err != nil {
+		return errors.New("invalid_sc_input",
+			fmt.Sprintf("input %s is not a %s: %v", typeOf(input), expected, err))
+	}
+	return nil
+}
+
+// checkOutput checks that the raw output is of the expected type and that
+// the out type has all the fields of it.
+func checkOutput(expected reflect.Type, raw []byte, out interface{}) error {
+	if expected == nil {
+		return nil
+	}
+	var v = reflect.New(expected).Interface()
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.New("invalid_sc_output",
+			fmt.Sprintf("output is not a %s: %v", expected, err))
+	}
+	if typeOf(out) == expected {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "error encoding smart contract output")
+	}
+	if err = decodeStrict(data, reflect.New(typeOf(out)).Interface()); err != nil {
+		return errors.New("invalid_sc_output",
+			fmt.Sprintf("output type is %s, expected %s: %v", typeOf(out), expected, err))
+	}
+	return nil
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	var dec = json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// TxnData returns the transaction data calling the function with the input.
+func (sc *SmartContract) TxnData(methodName string, input interface{}) (*SmartContractTxnData, error) {
+	sc.mutex.RLock()
+	var m, ok = sc.methods[methodName]
+	var any = len(sc.methods) == 0
+	sc.mutex.RUnlock()
+
+	if !ok && !any {
+		return nil, errors.New("unknown_sc_method",
+			fmt.Sprintf("smart contract %s has no function %s", sc.Address, methodName))
+	}
+	if ok {
+		if err := checkInput(m.Input, input); err != nil {
+			return nil, err
+		}
+	}
+	return &SmartContractTxnData{Name: methodName, InputArgs: input}, nil
+}
+
+// decode decodes raw into out, a pointer to the declared type. A *string or
+// a *[]byte out receives the raw data.
+func decode(expected reflect.Type, raw []byte, out interface{}) error {
+	switch o := out.(type) {
+	case nil:
+		return nil
+	case *string:
+		*o = string(raw)
+		return nil
+	case *[]byte:
+		*o = raw
+		return nil
+	}
+	if reflect.TypeOf(out).Kind() != reflect.Ptr {
+		return errors.New("invalid_sc_output", "output must be a pointer")
+	}
+	if err := checkOutput(expected, raw, out); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return errors.Wrap(err, "error decoding smart contract output")
+	}
+	return nil
+}
+
+// DecodeOutput decodes the transaction output of the function into out.
+func (sc *SmartContract) DecodeOutput(methodName, output string, out interface{}) error {
+	var expected reflect.Type
+	if m, ok := sc.Method(methodName); ok {
+		expected = m.Output
+	}
+	return decode(expected, []byte(output), out)
+}
+
+// QueryPath returns the path of the declared REST endpoint, checking the
+// required parameters are given.
+func (sc *SmartContract) QueryPath(name string, params map[string]string) (string, error) {
+	q, ok := sc.Query(name)
+	if !ok {
+		return "", errors.New("unknown_sc_query",
+			fmt.Sprintf("smart contract %s has no endpoint %s", sc.Address, name))
+	}
+	for _, p := range q.Params {
+		if params[p] == "" {
+			return "", errors.New("missing_sc_query_param",
+				fmt.Sprintf("endpoint %s requires %s", name, p))
+		}
+	}
+	return q.Path, nil
+}
+
+// DecodeQuery decodes the response of the REST endpoint into out.
+func (sc *SmartContract) DecodeQuery(name string, body []byte, out interface{}) error {
+	var expected reflect.Type
+	if q, ok := sc.Query(name); ok {
+		expected = q.Output
+	}
+	return decode(expected, body, out)
+}
+
+// SmartContractExecutor executes smart contract transactions and returns
+// the transaction hash and output once confirmed.
+type SmartContractExecutor interface {
+	ExecuteSmartContract(address string, data *SmartContractTxnData, value, fee int64) (hash, output string, err error)
+}
+
+// SmartContractQuerier queries smart contract REST endpoints.
+type SmartContractQuerier interface {
+	QuerySmartContract(address, path string, params map[string]string) ([]byte, error)
+}
+
+// SmartContractClient binds a smart contract declaration to a transport.
+type SmartContractClient struct {
+	*SmartContract
+	Executor SmartContractExecutor
+	Querier  SmartContractQuerier
+}
+
+// NewSmartContractClient creates a client of the smart contract.
+func NewSmartContractClient(sc *SmartContract, executor SmartContractExecutor, querier SmartContractQuerier) *SmartContractClient {
+	return &SmartContractClient{
+		SmartContract: sc,
+		Executor:      executor,
+		Querier:       querier,
+	}
+}
+
+// Call executes the function with the input, value and fee, and decodes
+// the transaction output into out, if given.
+func (c *SmartContractClient) Call(methodName string, input interface{}, value, fee int64, out interface{}) (hash string, err error) {
+	if c.Executor == nil {
+		return "", errors.New("sc_client", "no smart contract executor")
+	}
+	sn, err := c.TxnData(methodName, input)
+	if err != nil {
+		return "", err
+	}
+	hash, output, err := c.Executor.ExecuteSmartContract(c.Address, sn, value, fee)
+	if err != nil {
+		return hash, err
+	}
+	return hash, c.DecodeOutput(methodName, output, out)
+}
+
+// Query requests the REST endpoint with the params and decodes the
+// response into out.
+func (c *SmartContractClient) Query(name string, params map[string]string, out interface{}) error {
+	if c.Querier == nil {
+		return errors.New("sc_client", "no smart contract querier")
+	}
+	path, err := c.QueryPath(name, params)
+	if err != nil {
+		return err
+	}
+	body, err := c.Querier.QuerySmartContract(c.Address, path, params)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return errors.New("empty response")
+	}
+	return c.DecodeQuery(name, body, out)
+}
@@ -0,0 +1,151 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testInput struct {
+	PoolID string `json:"pool_id"`
+}
+
+type testOutput struct {
+	Balance int64 `json:"balance"`
+}
+
+type testTransport struct {
+	address string
+	data    *SmartContractTxnData
+	path    string
+	params  map[string]string
+}
+
+func (tt *testTransport) ExecuteSmartContract(address string, data *SmartContractTxnData, value, fee int64) (string, string, error) {
+	tt.address, tt.data = address, data
+	return "hash", `{"balance":10}`, nil
+}
+
+func (tt *testTransport) QuerySmartContract(address, path string, params map[string]string) ([]byte, error) {
+	tt.address, tt.path, tt.params = address, path, params
+	return json.Marshal(&testOutput{Balance: 20})
+}
+
+func TestSmartContractClient(t *testing.T) {
+	var (
+		sc = NewSmartContract("test_sc").
+			WithMethod("lock", &testInput{}, &testOutput{}).
+			WithQuery("getPool", "/getPool", testOutput{}, "pool_id")
+		tt     = new(testTransport)
+		client = NewSmartContractClient(sc, tt, tt)
+	)
+	got, err := GetSmartContract("test_sc")
+	require.NoError(t, err)
+	require.Equal(t, sc, got)
+
+	t.Run("call", func(t *testing.T) {
+		var out testOutput
+		hash, err := client.Call("lock", &testInput{PoolID: "p"}, 1, 0, &out)
+		require.NoError(t, err)
+		require.Equal(t, "hash", hash)
+		require.Equal(t, "test_sc", tt.address)
+		require.Equal(t, "lock", tt.data.Name)
+		require.Equal(t, int64(10), out.Balance)
+
+		var raw string
+		_, err = client.Call("lock", &testInput{}, 0, 0, &raw)
+		require.NoError(t, err)
+		require.Equal(t, `{"balance":10}`, raw)
+	})
+
+	t.Run("call undeclared", func(t *testing.T) {
+		_, err := client.Call("unlock", &testInput{}, 0, 0, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("call invalid input", func(t *testing.T) {
+		_, err := client.Call("lock", &testOutput{}, 0, 0, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("call compatible input", func(t *testing.T) {
+		type poolRequest struct {
+			PoolID string `json:"pool_id"`
+		}
+		_, err := client.Call("lock", &poolRequest{PoolID: "p"}, 0, 0, nil)
+		require.NoError(t, err)
+		_, err = client.Call("lock", map[string]interface{}{"pool_id": "p"}, 0, 0, nil)
+		require.NoError(t, err)
+		_, err = client.Call("lock", map[string]interface{}{"pool_id": 1}, 0, 0, nil)
+		require.Error(t, err)
+		_, err = client.Call("lock", map[string]interface{}{"id": "p"}, 0, 0, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("query compatible output", func(t *testing.T) {
+		var out struct {
+			Balance int64  `json:"balance"`
+			Extra   string `json:"extra"`
+		}
+		require.NoError(t, client.Query("getPool", map[string]string{"pool_id": "p"}, &out))
+		require.Equal(t, int64(20), out.Balance)
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var out testOutput
+		err := client.Query("getPool", map[string]string{"pool_id": "p"}, &out)
+		require.NoError(t, err)
+		require.Equal(t, "/getPool", tt.path)
+		require.Equal(t, int64(20), out.Balance)
+	})
+
+	t.Run("query missing param", func(t *testing.T) {
+		var out testOutput
+		require.Error(t, client.Query("getPool", nil, &out))
+	})
+
+	t.Run("query invalid output", func(t *testing.T) {
+		var out testInput
+		require.Error(t, client.Query("getPool", map[string]string{"pool_id": "p"}, &out))
+	})
+}
+
+func TestSmartContractAnyMethod(t *testing.T) {
+	sn, err := NewSmartContract("any_sc").TxnData("anything", map[string]interface{}{})
+	require.NoError(t, err)
+	require.Equal(t, "anything", sn.Name)
+}
+
+func TestGetSmartContractUnknown(t *testing.T) {
+	_, err := GetSmartContract("unknown_sc")
+	require.Error(t, err)
+
+	_, err = GetSmartContract(StorageSmartContractAddress)
+	require.NoError(t, err)
+}
+
+func TestDeclaredContracts(t *testing.T) {
+	for _, sc := range []*SmartContract{StorageSmartContract, VestingSmartContract,
+		MinerSmartContract, InterestPoolSmartContract, MultiSigSmartContract} {
+
+		for _, name := range sc.Methods() {
+			m, _ := sc.Method(name)
+			if name != STORAGESC_CREATE_READ_POOL {
+				require.NotNil(t, m.Input, name)
+			}
+		}
+		for _, q := range sc.queries {
+			require.NotNil(t, q.Output, q.Name)
+		}
+	}
+
+	_, err := StorageSmartContract.TxnData(STORAGESC_READ_POOL_LOCK, map[string]interface{}{
+		"duration": 10, "allocation_id": "a", "blobber_id": "b",
+	})
+	require.NoError(t, err)
+	_, err = StorageSmartContract.TxnData(STORAGESC_READ_POOL_LOCK, map[string]interface{}{
+		"allocation": "a",
+	})
+	require.Error(t, err)
+}
@@ -13,3 +13,8 @@ const (
 
 	TxnTypeSmartContract = 1000 // A smart contract transaction type
 )
+
+const (
+	TxnStatusSuccess = 1 // The transaction was executed
+	TxnStatusFailure = 2 // The transaction was rejected, the output is the error
+)
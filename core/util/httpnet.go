@@ -154,6 +154,17 @@ func NewHTTPPostRequest(url string, data interface{}) (*PostRequest, error) {
 	return pr, nil
 }
 
+func NewHTTPPostRequestContext(ctx context.Context, url string, data interface{}) (
+	pr *PostRequest, err error) {
+
+	if pr, err = NewHTTPPostRequest(url, data); err != nil {
+		return
+	}
+	pr.cncl()
+	pr.ctx, pr.cncl = context.WithCancel(ctx)
+	return
+}
+
 func (r *GetRequest) Get() (*GetResponse, error) {
 	response := &GetResponse{}
 	presp, err := r.Post()
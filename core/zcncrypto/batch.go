@@ -0,0 +1,197 @@
+package zcncrypto
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+// SignedHash is a hex encoded hash, its signature and the public key to
+// verify it with.
+type SignedHash struct {
+	PublicKey string
+	Signature string
+	Hash      string
+}
+
+// BatchVerify verifies the signatures of the scheme. A false result doesn't
+// tell which signature is invalid, verify them one by one to find out.
+//
+// Only the bls0chain signatures are aggregated, checked with one
+// multi-pairing. The signatures of the other schemes are verified one by
+// one with Verify and cost as much as verifying them separately.
+func BatchVerify(scheme string, signed []SignedHash) (bool, error) {
+	if !IsSignatureSchemeSupported(scheme) {
+		return false, errors.New("invalid_signature_scheme", "invalid signature scheme "+scheme)
+	}
+	if len(signed) == 0 {
+		return true, nil
+	}
+	switch scheme {
+	case "bls0chain":
+		return bls0BatchVerify(signed)
+	}
+	for _, s := range signed {
+		ss := NewSignatureScheme(scheme)
+		if err := ss.SetPublicKey(s.PublicKey); err != nil {
+			return false, err
+		}
+		if ok, err := ss.Verify(s.Signature, s.Hash); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// BLS0AggregateVerify verifies the aggregate of the bls0chain signatures
+// of the hashes, each one signed by the public key at the same index. The
+// hashes must be distinct.
+func BLS0AggregateVerify(signature string, publicKeys, hashes []string) (bool, error) {
+	if len(publicKeys) != len(hashes) || len(hashes) == 0 {
+		return false, errors.New("aggregate_verify", "need as many public keys as hashes")
+	}
+	var sig bls.G1
+	if err := bls0G1(&sig, signature); err != nil {
+		return false, err
+	}
+	var (
+		seen = make(map[string]struct{}, len(hashes))
+		g1s  = make([]bls.G1, len(hashes)+1)
+		g2s  = make([]bls.G2, len(hashes)+1)
+	)
+	bls.G1Neg(&g1s[0], &sig)
+	g2s[0] = bls0Generator()
+	for i, h := range hashes {
+		if _, dup := seen[h]; dup {
+			return false, errors.New("aggregate_verify", "duplicate hash "+h)
+		}
+		seen[h] = struct{}{}
+		if err := bls0HashToG1(&g1s[i+1], h); err != nil {
+			return false, err
+		}
+		if err := bls0G2(&g2s[i+1], publicKeys[i]); err != nil {
+			return false, err
+		}
+	}
+	return bls0PairingIsOne(g1s, g2s), nil
+}
+
+// bls0BatchVerify checks e(sum r_i*sig_i, Q) == prod e(H(m_i), r_i*pk_i)
+// for random r_i. Signatures of the same hash share the pairing, so block
+// verification tickets cost two pairings whatever their number.
+func bls0BatchVerify(signed []SignedHash) (bool, error) {
+	var (
+		sigs   = make([]bls.G1, len(signed))
+		rs     = make([]bls.Fr, len(signed))
+		groups = make(map[string]int)
+		g1s    = []bls.G1{{}}
+		g2s    = []bls.G2{bls0Generator()}
+		pks    [][]bls.G2
+		prs    [][]bls.Fr
+	)
+	for i, s := range signed {
+		if err := bls0G1(&sigs[i], s.Signature); err != nil {
+			return false, err
+		}
+		var pk bls.G2
+		if err := bls0G2(&pk, s.PublicKey); err != nil {
+			return false, err
+		}
+		if err := randomFr(&rs[i]); err != nil {
+			return false, err
+		}
+		g, ok := groups[s.Hash]
+		if !ok {
+			var hm bls.G1
+			if err := bls0HashToG1(&hm, s.Hash); err != nil {
+				return false, err
+			}
+			g = len(pks)
+			groups[s.Hash] = g
+			g1s = append(g1s, hm)
+			pks = append(pks, nil)
+			prs = append(prs, nil)
+		}
+		pks[g] = append(pks[g], pk)
+		prs[g] = append(prs[g], rs[i])
+	}
+	var agg bls.G1
+	bls.G1MulVec(&agg, sigs, rs)
+	bls.G1Neg(&g1s[0], &agg)
+	for g := range pks {
+		var pk bls.G2
+		bls.G2MulVec(&pk, pks[g], prs[g])
+		g2s = append(g2s, pk)
+	}
+	return bls0PairingIsOne(g1s, g2s), nil
+}
+
+// bls0Generator returns Q, the G2 point public keys are multiples of.
+func bls0Generator() bls.G2 {
+	var (
+		sk bls.SecretKey
+		q  bls.G2
+	)
+	sk.SetLittleEndian([]byte{1})
+	q.Deserialize(sk.GetPublicKey().Serialize())
+	return q
+}
+
+func bls0PairingIsOne(g1s []bls.G1, g2s []bls.G2) bool {
+	var e bls.GT
+	bls.MillerLoopVec(&e, g1s, g2s)
+	bls.FinalExp(&e, &e)
+	return e.IsOne()
+}
+
+// bls0G1 reads a signature, bls.Sign is serialized as its G1 point.
+func bls0G1(p *bls.G1, signature string) error {
+	buf, err := hex.DecodeString(signature)
+	if err == nil && len(buf) == 0 {
+		// the bls binding panics on an empty buffer
+		err = errors.New("empty_buffer", "empty")
+	}
+	if err == nil {
+		err = p.Deserialize(buf)
+	}
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	return nil
+}
+
+// bls0G2 reads a public key, bls.PublicKey is serialized as its G2 point.
+func bls0G2(p *bls.G2, publicKey string) error {
+	buf, err := hex.DecodeString(publicKey)
+	if err == nil && len(buf) == 0 {
+		// the bls binding panics on an empty buffer
+		err = errors.New("empty_buffer", "empty")
+	}
+	if err == nil {
+		err = p.Deserialize(buf)
+	}
+	if err != nil {
+		return errors.Wrap(err, "invalid public key")
+	}
+	return nil
+}
+
+// bls0HashToG1 maps the hash the way bls.SecretKey.Sign does.
+func bls0HashToG1(p *bls.G1, hash string) error {
+	rawHash, err := hex.DecodeString(hash)
+	if err != nil {
+		return err
+	}
+	return p.HashAndMapTo(rawHash)
+}
+
+// randomFr sets a random 128 bits scalar.
+func randomFr(r *bls.Fr) error {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return err
+	}
+	return r.SetLittleEndian(buf[:])
+}
@@ -0,0 +1,117 @@
+package zcncrypto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signHashes signs n hashes, with n keys or with one key if sameKey. The
+// hashes are all the same if sameHash.
+func signHashes(t testing.TB, scheme string, n int, sameKey, sameHash bool) []SignedHash {
+	var (
+		signed = make([]SignedHash, n)
+		signer SignatureScheme
+		w      *Wallet
+		err    error
+	)
+	for i := range signed {
+		if signer == nil || !sameKey {
+			w, err = NewSignatureScheme(scheme).GenerateKeys()
+			require.NoError(t, err)
+			signer = NewSignatureScheme(scheme)
+			require.NoError(t, signer.SetPrivateKey(w.Keys[0].PrivateKey))
+		}
+		hash := Sha3Sum256(data)
+		if !sameHash {
+			hash = Sha3Sum256(fmt.Sprintf("%s:%d", data, i))
+		}
+		sig, err := signer.Sign(hash)
+		require.NoError(t, err)
+		signed[i] = SignedHash{PublicKey: w.ClientKey, Signature: sig, Hash: hash}
+	}
+	return signed
+}
+
+func TestBatchVerify(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519", "secp256k1"} {
+		for _, same := range []bool{false, true} {
+			signed := signHashes(t, scheme, 8, same, same)
+			ok, err := BatchVerify(scheme, signed)
+			require.NoError(t, err)
+			require.True(t, ok, scheme)
+
+			// swapped signatures are all valid, but not for their hashes
+			signed = signHashes(t, scheme, 8, false, same)
+			signed[2].Signature, signed[5].Signature = signed[5].Signature, signed[2].Signature
+			ok, err = BatchVerify(scheme, signed)
+			require.NoError(t, err)
+			require.False(t, ok, scheme)
+		}
+	}
+	_, err := BatchVerify("rsa", nil)
+	require.Error(t, err)
+
+	// an unsigned marker is invalid, not a panic
+	signed := signHashes(t, "bls0chain", 2, false, false)
+	signed[1].Signature = ""
+	_, err = BatchVerify("bls0chain", signed)
+	require.Error(t, err)
+	signed = signHashes(t, "bls0chain", 2, false, false)
+	signed[0].PublicKey = ""
+	_, err = BatchVerify("bls0chain", signed)
+	require.Error(t, err)
+}
+
+func TestBLS0AggregateVerify(t *testing.T) {
+	signed := signHashes(t, "bls0chain", 5, false, false)
+	var (
+		sigs   []string
+		keys   []string
+		hashes []string
+	)
+	for _, s := range signed {
+		sigs = append(sigs, s.Signature)
+		keys = append(keys, s.PublicKey)
+		hashes = append(hashes, s.Hash)
+	}
+	agg, err := AggregateSignatures("bls0chain", sigs...)
+	require.NoError(t, err)
+	ok, err := BLS0AggregateVerify(agg, keys, hashes)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	keys[0], keys[1] = keys[1], keys[0]
+	ok, err = BLS0AggregateVerify(agg, keys, hashes)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	hashes[1] = hashes[0]
+	_, err = BLS0AggregateVerify(agg, keys, hashes)
+	require.Error(t, err)
+}
+
+func benchmarkVerify(b *testing.B, scheme string, n int, sameHash, batch bool) {
+	signed := signHashes(b, scheme, n, false, sameHash)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batch {
+			if ok, err := BatchVerify(scheme, signed); err != nil || !ok {
+				b.Fatal("batch verification failed", err)
+			}
+			continue
+		}
+		for _, s := range signed {
+			v := NewSignatureScheme(scheme)
+			v.SetPublicKey(s.PublicKey)
+			if ok, err := v.Verify(s.Signature, s.Hash); err != nil || !ok {
+				b.Fatal("verification failed", err)
+			}
+		}
+	}
+}
+
+func BenchmarkBLSVerify64(b *testing.B)             { benchmarkVerify(b, "bls0chain", 64, false, false) }
+func BenchmarkBLSBatchVerify64(b *testing.B)        { benchmarkVerify(b, "bls0chain", 64, false, true) }
+func BenchmarkBLSBatchVerifyTickets64(b *testing.B) { benchmarkVerify(b, "bls0chain", 64, true, true) }
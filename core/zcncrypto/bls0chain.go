@@ -25,6 +25,8 @@ type BLS0ChainScheme struct {
 	PublicKey  string `json:"public_key"`
 	PrivateKey string `json:"private_key"`
 	Mnemonic   string `json:"mnemonic"`
+
+	passphrase string
 }
 
 //NewBLS0ChainScheme - create a BLS0ChainScheme object
@@ -47,7 +49,8 @@ func (b0 *BLS0ChainScheme) GenerateKeys() (*Wallet, error) {
 	}
 
 	// Generate a Bip32 HD wallet for the mnemonic and a user supplied password
-	seed := bip39.NewSeed(b0.Mnemonic, "0chain-client-split-key")
+	seed := mnemonicSeed(b0.Mnemonic, "0chain-client-split-key"+b0.passphrase)
+	defer Secret(seed).Zero()
 	r := bytes.NewReader(seed)
 	bls.SetRandFunc(r)
 
@@ -57,6 +60,7 @@ func (b0 *BLS0ChainScheme) GenerateKeys() (*Wallet, error) {
 
 	// Generate pair
 	var sk bls.SecretKey
+	defer wipeBLSKey(&sk)
 	sk.SetByCSPRNG()
 	w.Keys[0].PrivateKey = sk.SerializeToHexStr()
 	pub := sk.GetPublicKey()
@@ -86,6 +90,12 @@ func (b0 *BLS0ChainScheme) RecoverKeys(mnemonic string) (*Wallet, error) {
 	return b0.GenerateKeys()
 }
 
+// RecoverKeysWithPassphrase - implement PassphraseSignatureScheme
+func (b0 *BLS0ChainScheme) RecoverKeysWithPassphrase(mnemonic, passphrase string) (*Wallet, error) {
+	b0.passphrase = passphrase
+	return b0.RecoverKeys(mnemonic)
+}
+
 //SetPrivateKey - implement interface
 func (b0 *BLS0ChainScheme) SetPrivateKey(privateKey string) error {
 	if b0.PublicKey != "" {
@@ -135,6 +145,16 @@ func MiraclToHerumiPK(pk string) string {
 	return p.SerializeToHexStr()
 }
 
+// IsBLSPublicKey reports whether pk is a valid BLS public key, either
+// serialized or in the hex string form the DKG publishes mpks in.
+func IsBLSPublicKey(pk string) bool {
+	var p bls.PublicKey
+	if err := p.DeserializeHexStr(MiraclToHerumiPK(pk)); err == nil {
+		return true
+	}
+	return p.SetHexString(pk) == nil
+}
+
 //GetPublicKey - implement interface
 func (b0 *BLS0ChainScheme) GetPublicKey() string {
 	return b0.PublicKey
@@ -144,8 +164,20 @@ func (b0 *BLS0ChainScheme) GetPrivateKey() string {
 	return b0.PrivateKey
 }
 
+// Zero - implement WipeableSignatureScheme. The key strings can't be
+// wiped, they are dropped.
+func (b0 *BLS0ChainScheme) Zero() {
+	b0.PrivateKey = ""
+	b0.Mnemonic = ""
+}
+
+func wipeBLSKey(sk *bls.SecretKey) {
+	*sk = bls.SecretKey{}
+}
+
 func (b0 *BLS0ChainScheme) rawSign(hash string) (*bls.Sign, error) {
 	var sk bls.SecretKey
+	defer wipeBLSKey(&sk)
 	if b0.PrivateKey == "" {
 		return nil, errors.New("raw_sign", "private key does not exists for signing")
 	}
@@ -304,6 +336,7 @@ func (b0 *BLS0ChainScheme) SplitKeys(numSplits int) (*Wallet, error) {
 	}
 	var primaryFr bls.Fr
 	var primarySk bls.SecretKey
+	defer wipeBLSKey(&primarySk)
 	primarySk.DeserializeHexStr(b0.PrivateKey)
 	primaryFr.SetLittleEndian(primarySk.GetLittleEndian())
 
@@ -311,6 +344,7 @@ func (b0 *BLS0ChainScheme) SplitKeys(numSplits int) (*Wallet, error) {
 	w := &Wallet{}
 	w.Keys = make([]KeyPair, numSplits)
 	var sk bls.SecretKey
+	defer wipeBLSKey(&sk)
 	for i := 0; i < numSplits-1; i++ {
 		var tmpSk bls.SecretKey
 		tmpSk.SetByCSPRNG()
@@ -318,6 +352,7 @@ func (b0 *BLS0ChainScheme) SplitKeys(numSplits int) (*Wallet, error) {
 		pub := tmpSk.GetPublicKey()
 		w.Keys[i].PublicKey = pub.SerializeToHexStr()
 		sk.Add(&tmpSk)
+		wipeBLSKey(&tmpSk)
 	}
 	var aggregateSk bls.Fr
 	aggregateSk.SetLittleEndian(sk.GetLittleEndian())
@@ -331,6 +366,8 @@ func (b0 *BLS0ChainScheme) SplitKeys(numSplits int) (*Wallet, error) {
 	lastSecretKey.SetLittleEndian(lastSk.Serialize())
 	w.Keys[numSplits-1].PrivateKey = lastSecretKey.SerializeToHexStr()
 	w.Keys[numSplits-1].PublicKey = lastSecretKey.GetPublicKey().SerializeToHexStr()
+	wipeBLSKey(&lastSecretKey)
+	primaryFr, aggregateSk, lastSk = bls.Fr{}, bls.Fr{}, bls.Fr{}
 
 	// Generate client ID and public
 	w.ClientKey = primarySk.GetPublicKey().SerializeToHexStr()
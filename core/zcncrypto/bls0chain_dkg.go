@@ -0,0 +1,239 @@
+package zcncrypto
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+// BLS0ChainDKG is one participant of a dealer-less distributed key
+// generation. Every participant deals a share of its own secret to every
+// other participant, the group secret is the sum of the secrets and is
+// never known to anyone.
+//
+// The flow for the participant i of n is:
+//  1. publish Commitments() to all participants,
+//  2. send Share(j) privately to every participant j,
+//  3. AddShare(j, share, commitments) for the share received from every j,
+//  4. Finish() to get the threshold key share and the group public key.
+type BLS0ChainDKG struct {
+	t, n   int
+	index  int
+	msk    []bls.SecretKey
+	shares map[int]bls.SecretKey
+	group  map[int]bls.PublicKey
+}
+
+// NewBLS0ChainDKG creates the participant index, from 1 to n, of a t of n
+// distributed key generation.
+func NewBLS0ChainDKG(t, n, index int) (*BLS0ChainDKG, error) {
+	if t < 1 || t > n {
+		return nil, errors.New("bls0_dkg", "threshold must be between 1 and n")
+	}
+	if index < 1 || index > n {
+		return nil, errors.New("bls0_dkg", "index must be between 1 and n")
+	}
+	var sk bls.SecretKey
+	sk.SetByCSPRNG()
+	return &BLS0ChainDKG{
+		t:      t,
+		n:      n,
+		index:  index,
+		msk:    sk.GetMasterSecretKey(t),
+		shares: make(map[int]bls.SecretKey),
+		group:  make(map[int]bls.PublicKey),
+	}, nil
+}
+
+func dkgID(index int) (bls.ID, error) {
+	var id bls.ID
+	err := id.SetDecString(fmt.Sprint(index))
+	return id, err
+}
+
+// Commitments returns the hex encoded public commitments of the secret
+// polynomial of the participant.
+func (d *BLS0ChainDKG) Commitments() []string {
+	mpk := bls.GetMasterPublicKey(d.msk)
+	commitments := make([]string, len(mpk))
+	for i := range mpk {
+		commitments[i] = mpk[i].SerializeToHexStr()
+	}
+	return commitments
+}
+
+// Share returns the hex encoded secret share for the participant index.
+// It must only be sent to that participant.
+func (d *BLS0ChainDKG) Share(index int) (string, error) {
+	if index < 1 || index > d.n {
+		return "", errors.New("bls0_dkg", "index must be between 1 and n")
+	}
+	id, err := dkgID(index)
+	if err != nil {
+		return "", err
+	}
+	var sk bls.SecretKey
+	if err := sk.Set(d.msk, &id); err != nil {
+		return "", err
+	}
+	return sk.SerializeToHexStr(), nil
+}
+
+// AddShare verifies the share received from the participant from against
+// its commitments and keeps it.
+func (d *BLS0ChainDKG) AddShare(from int, share string, commitments []string) error {
+	if from < 1 || from > d.n {
+		return errors.New("bls0_dkg", "index must be between 1 and n")
+	}
+	if len(commitments) != d.t {
+		return errors.New("bls0_dkg",
+			fmt.Sprintf("participant %d: expected %d commitments, got %d", from, d.t, len(commitments)))
+	}
+	mpk := make([]bls.PublicKey, len(commitments))
+	for i, c := range commitments {
+		if err := mpk[i].DeserializeHexStr(c); err != nil {
+			return errors.Wrap(err, "invalid commitment")
+		}
+	}
+	var sk bls.SecretKey
+	if err := sk.DeserializeHexStr(share); err != nil {
+		return errors.Wrap(err, "invalid share")
+	}
+	id, err := dkgID(d.index)
+	if err != nil {
+		return err
+	}
+	var expected bls.PublicKey
+	if err := expected.Set(mpk, &id); err != nil {
+		return err
+	}
+	if !sk.GetPublicKey().IsEqual(&expected) {
+		return errors.New("bls0_dkg",
+			fmt.Sprintf("share of participant %d doesn't match its commitments", from))
+	}
+	d.shares[from] = sk
+	d.group[from] = mpk[0]
+	return nil
+}
+
+// Finish returns the threshold key share of the participant and the hex
+// encoded group public key, once the shares of all participants are added.
+func (d *BLS0ChainDKG) Finish() (*BLS0ChainThresholdScheme, string, error) {
+	if len(d.shares) != d.n {
+		return nil, "", errors.New("bls0_dkg",
+			fmt.Sprintf("received %d of %d shares", len(d.shares), d.n))
+	}
+	var sk bls.SecretKey
+	var group bls.PublicKey
+	for i := 1; i <= d.n; i++ {
+		share, commitment := d.shares[i], d.group[i]
+		if i == 1 {
+			sk, group = share, commitment
+			continue
+		}
+		sk.Add(&share)
+		group.Add(&commitment)
+	}
+	id, err := dkgID(d.index)
+	if err != nil {
+		return nil, "", err
+	}
+	tss := NewBLS0ChainThresholdScheme()
+	tss.PrivateKey = sk.SerializeToHexStr()
+	tss.PublicKey = sk.GetPublicKey().SerializeToHexStr()
+	tss.id = id
+	tss.Ids = tss.GetID()
+	return tss, group.SerializeToHexStr(), nil
+}
+
+// BLS0RecoverThresholdSignature combines the partial signatures of the
+// hash of at least t signers, keyed by their hex threshold ID, into the
+// group signature. The partial signatures are made by Sign of the
+// threshold key shares, publicKeys holds the public keys of the shares by
+// the same IDs. Partial signatures that don't verify against the public
+// key of their share are skipped. The group signature verifies under the
+// group public key.
+func BLS0RecoverThresholdSignature(t int, hash string, publicKeys, partials map[string]string) (string, error) {
+	if t < 1 {
+		return "", errors.New("bls0_recover_signature", "threshold must be positive")
+	}
+	ids := make([]string, 0, len(partials))
+	for id := range partials {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	valid := ids[:0]
+	for _, id := range ids {
+		verifier := NewBLS0ChainScheme()
+		if err := verifier.SetPublicKey(publicKeys[id]); err != nil {
+			continue
+		}
+		if ok, err := verifier.Verify(partials[id], hash); err != nil || !ok {
+			continue
+		}
+		valid = append(valid, id)
+	}
+	if len(valid) < t {
+		return "", errors.New("bls0_recover_signature",
+			fmt.Sprintf("%d of %d valid partial signatures", len(valid), t))
+	}
+	ids = valid[:t]
+
+	sigVec := make([]bls.Sign, t)
+	idVec := make([]bls.ID, t)
+	for i, id := range ids {
+		if err := idVec[i].SetHexString(id); err != nil {
+			return "", errors.Wrap(err, "invalid threshold id")
+		}
+		if err := sigVec[i].DeserializeHexStr(partials[id]); err != nil {
+			return "", errors.Wrap(err, "invalid partial signature")
+		}
+	}
+	var sig bls.Sign
+	if err := sig.Recover(sigVec, idVec); err != nil {
+		return "", errors.Wrap(err, "recover signature failed")
+	}
+	return sig.SerializeToHexStr(), nil
+}
+
+// BLS0RunDKG runs the distributed key generation of n participants in
+// process, e.g. for tests. It returns the threshold key shares and the hex
+// encoded group public key.
+func BLS0RunDKG(t, n int) ([]*BLS0ChainThresholdScheme, string, error) {
+	participants := make([]*BLS0ChainDKG, n)
+	for i := range participants {
+		d, err := NewBLS0ChainDKG(t, n, i+1)
+		if err != nil {
+			return nil, "", err
+		}
+		participants[i] = d
+	}
+	for i, from := range participants {
+		commitments := from.Commitments()
+		for j, to := range participants {
+			share, err := from.Share(j + 1)
+			if err != nil {
+				return nil, "", err
+			}
+			if err := to.AddShare(i+1, share, commitments); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	var groupKey string
+	shares := make([]*BLS0ChainThresholdScheme, n)
+	for i, d := range participants {
+		tss, group, err := d.Finish()
+		if err != nil {
+			return nil, "", err
+		}
+		if groupKey != "" && groupKey != group {
+			return nil, "", errors.New("bls0_dkg", "participants disagree on the group key")
+		}
+		shares[i], groupKey = tss, group
+	}
+	return shares, groupKey, nil
+}
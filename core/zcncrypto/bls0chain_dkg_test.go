@@ -0,0 +1,81 @@
+package zcncrypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBLS0ThresholdSigning(t *testing.T) {
+	shares, groupKey, err := BLS0RunDKG(2, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+
+	hash := Sha3Sum256(data)
+	verifyScheme := NewSignatureScheme("bls0chain")
+	require.NoError(t, verifyScheme.SetPublicKey(groupKey))
+
+	publicKeys := make(map[string]string)
+	for _, share := range shares {
+		publicKeys[share.GetID()] = share.PublicKey
+	}
+
+	// any 2 of 3 partial signatures make the same group signature
+	var groupSigs []string
+	for _, pair := range [][2]int{{0, 1}, {0, 2}, {1, 2}} {
+		partials := make(map[string]string)
+		for _, i := range pair {
+			sig, err := shares[i].Sign(hash)
+			require.NoError(t, err)
+			partials[shares[i].GetID()] = sig
+		}
+		sig, err := BLS0RecoverThresholdSignature(2, hash, publicKeys, partials)
+		require.NoError(t, err)
+		ok, err := verifyScheme.Verify(sig, hash)
+		require.NoError(t, err)
+		require.True(t, ok)
+		groupSigs = append(groupSigs, sig)
+	}
+	require.Equal(t, groupSigs[0], groupSigs[1])
+	require.Equal(t, groupSigs[1], groupSigs[2])
+
+	// a single partial signature is not enough
+	sig, err := shares[0].Sign(hash)
+	require.NoError(t, err)
+	_, err = BLS0RecoverThresholdSignature(2, hash, publicKeys, map[string]string{shares[0].GetID(): sig})
+	require.Error(t, err)
+
+	// invalid partial signatures are skipped
+	partials := map[string]string{shares[0].GetID(): sig}
+	bad, err := shares[1].Sign(Sha3Sum256("other"))
+	require.NoError(t, err)
+	partials[shares[1].GetID()] = bad
+	_, err = BLS0RecoverThresholdSignature(2, hash, publicKeys, partials)
+	require.Error(t, err)
+
+	partials[shares[2].GetID()], err = shares[2].Sign(hash)
+	require.NoError(t, err)
+	groupSig, err := BLS0RecoverThresholdSignature(2, hash, publicKeys, partials)
+	require.NoError(t, err)
+	require.Equal(t, groupSigs[0], groupSig)
+
+	// partial signatures without public key are skipped
+	delete(publicKeys, shares[2].GetID())
+	_, err = BLS0RecoverThresholdSignature(2, hash, publicKeys, partials)
+	require.Error(t, err)
+}
+
+func TestBLS0DKGRejectsBadShare(t *testing.T) {
+	a, err := NewBLS0ChainDKG(2, 2, 1)
+	require.NoError(t, err)
+	b, err := NewBLS0ChainDKG(2, 2, 2)
+	require.NoError(t, err)
+
+	// the share for participant 1 is sent to participant 2
+	share, err := a.Share(1)
+	require.NoError(t, err)
+	require.Error(t, b.AddShare(1, share, a.Commitments()))
+
+	_, _, err = b.Finish()
+	require.Error(t, err)
+}
@@ -26,7 +26,7 @@ func TestSignatureScheme(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Generate Key failed %s", errors.Top(err))
 	}
-	if w.ClientID == "" || w.ClientKey == "" || len(w.Keys) != 1 || w.Mnemonic == "" {
+	if w.ClientID == "" || w.ClientKey == "" || len(w.Keys) != 1 || len(w.Mnemonic) == 0 {
 		t.Fatalf("Invalid keys generated")
 	}
 	blsWallet = w
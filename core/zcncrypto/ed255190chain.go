@@ -14,9 +14,10 @@ import (
 
 //ED255190chainScheme - a signature scheme based on ED25519
 type ED255190chainScheme struct {
-	privateKey []byte
+	privateKey Secret
 	publicKey  []byte
 	mnemonic   string
+	passphrase string
 }
 
 // NewED255190chainScheme - create a ED255190chainScheme object
@@ -38,12 +39,14 @@ func (ed *ED255190chainScheme) GenerateKeys() (*Wallet, error) {
 		}
 	}
 
-	seed := bip39.NewSeed(ed.mnemonic, "0chain-client-ed25519-key")
+	seed := mnemonicSeed(ed.mnemonic, "0chain-client-ed25519-key"+ed.passphrase)
+	defer Secret(seed).Zero()
 	r := bytes.NewReader(seed)
 	public, private, err := ed25519.GenerateKey(r)
 	if err != nil {
 		return nil, errors.Wrap(err, "Generate keys failed")
 	}
+	defer Secret(private).Zero()
 	// New Wallet
 	w := &Wallet{}
 	w.Keys = make([]KeyPair, 1)
@@ -68,6 +71,12 @@ func (ed *ED255190chainScheme) RecoverKeys(mnemonic string) (*Wallet, error) {
 	return ed.GenerateKeys()
 }
 
+// RecoverKeysWithPassphrase - implement PassphraseSignatureScheme
+func (ed *ED255190chainScheme) RecoverKeysWithPassphrase(mnemonic, passphrase string) (*Wallet, error) {
+	ed.passphrase = passphrase
+	return ed.RecoverKeys(mnemonic)
+}
+
 func (ed *ED255190chainScheme) SetPrivateKey(privateKey string) error {
 	if len(ed.privateKey) > 0 {
 		return errors.New("set_private_key", "cannot set private key when there is a public key")
@@ -76,7 +85,7 @@ func (ed *ED255190chainScheme) SetPrivateKey(privateKey string) error {
 		return errors.New("set_private_key", "private key already exists")
 	}
 	var err error
-	ed.privateKey, err = hex.DecodeString(privateKey)
+	ed.privateKey, err = NewSecret(privateKey)
 	return err
 }
 
@@ -103,7 +112,7 @@ func (ed *ED255190chainScheme) Sign(hash string) (string, error) {
 	if rawHash == nil {
 		return "", errors.New("chain_scheme_sign", "Failed hash while signing")
 	}
-	return hex.EncodeToString(ed25519.Sign(ed.privateKey, rawHash)), nil
+	return hex.EncodeToString(ed25519.Sign(ed25519.PrivateKey(ed.privateKey), rawHash)), nil
 }
 
 func (ed *ED255190chainScheme) Verify(signature, msg string) (bool, error) {
@@ -132,5 +141,11 @@ func (ed *ED255190chainScheme) GetPublicKey() string {
 
 //GetPrivateKey - implement interface
 func (ed *ED255190chainScheme) GetPrivateKey() string {
-	return hex.EncodeToString(ed.privateKey)
+	return ed.privateKey.Hex()
+}
+
+// Zero - implement WipeableSignatureScheme
+func (ed *ED255190chainScheme) Zero() {
+	ed.privateKey.Zero()
+	ed.privateKey = nil
 }
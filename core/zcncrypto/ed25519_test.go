@@ -25,7 +25,7 @@ func TestEd25519GenerateKeys(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Generate keys failed %s", errors.Top(err))
 	}
-	if w.ClientID == "" || w.ClientKey == "" || len(w.Keys) != 1 || w.Mnemonic == "" {
+	if w.ClientID == "" || w.ClientKey == "" || len(w.Keys) != 1 || len(w.Mnemonic) == 0 {
 		t.Fatalf("Invalid keys generated")
 	}
 	edWallet = w
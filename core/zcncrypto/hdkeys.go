@@ -0,0 +1,198 @@
+package zcncrypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/herumi/bls-go-binary/bls"
+	"golang.org/x/crypto/ed25519"
+)
+
+// hardenedOffset is added to the index of the hardened path levels.
+const hardenedOffset uint32 = 0x80000000
+
+// HDSignatureScheme derives many key pairs from one mnemonic by path.
+type HDSignatureScheme interface {
+	SignatureScheme
+	// DeriveKeys derives the keys at path, e.g. "m/0'/3'", from the
+	// mnemonic. The derived wallet has no mnemonic, and the keys of the
+	// scheme are left untouched.
+	DeriveKeys(mnemonic, path string) (*Wallet, error)
+}
+
+// AccountPath returns the derivation path of the account index.
+func AccountPath(index int) string {
+	return fmt.Sprintf("m/%d'", index)
+}
+
+// ParseHDPath parses a derivation path like "m/0'/1'/0" into its indexes.
+// Levels marked with ', h or H are hardened, their index has hardenedOffset
+// added.
+func ParseHDPath(path string) ([]uint32, error) {
+	levels := strings.Split(strings.TrimSpace(path), "/")
+	if levels[0] != "m" {
+		return nil, errors.New("invalid_hd_path", "path must start with m")
+	}
+	indexes := make([]uint32, 0, len(levels)-1)
+	for _, l := range levels[1:] {
+		n := strings.TrimRight(l, "'hH")
+		i, err := strconv.ParseUint(n, 10, 31)
+		if err != nil || len(l)-len(n) > 1 {
+			return nil, errors.New("invalid_hd_path",
+				fmt.Sprintf("invalid path level %q", l))
+		}
+		if n != l {
+			i += uint64(hardenedOffset)
+		}
+		indexes = append(indexes, uint32(i))
+	}
+	return indexes, nil
+}
+
+// normalDerivation returns the data of the non-hardened derivation of the
+// key and combines the derived tweak with the key into the child key.
+type normalDerivation struct {
+	data  func(key []byte) ([]byte, error)
+	child func(key, tweak []byte) ([]byte, error)
+}
+
+// deriveHDKey derives the 32 byte key at path from the seed, following
+// SLIP-0010 with curveKey as the master HMAC key. Non-hardened levels need
+// normal, schemes without it support hardened levels only.
+func deriveHDKey(curveKey string, seed []byte, path string, normal *normalDerivation) ([]byte, error) {
+	indexes, err := ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, []byte(curveKey))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	key, chainCode := I[:32], I[32:]
+	for _, i := range indexes {
+		var data []byte
+		if i >= hardenedOffset {
+			data = make([]byte, 33, 37)
+			copy(data[1:], key)
+		} else if normal == nil {
+			return nil, errors.New("invalid_hd_path",
+				fmt.Sprintf("%s supports hardened derivation only", curveKey))
+		} else if data, err = normal.data(key); err != nil {
+			return nil, err
+		}
+		var index [4]byte
+		binary.BigEndian.PutUint32(index[:], i)
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(append(data, index[:]...))
+		I = mac.Sum(nil)
+		if i >= hardenedOffset {
+			key, chainCode = I[:32], I[32:]
+			continue
+		}
+		if key, err = normal.child(key, I[:32]); err != nil {
+			return nil, err
+		}
+		chainCode = I[32:]
+	}
+	return key, nil
+}
+
+// blsNormalDerivation derives non-hardened BLS keys, the child secret key is
+// the parent one plus the tweak, so the child public key is derivable from
+// the parent public key.
+var blsNormalDerivation = &normalDerivation{
+	data: func(key []byte) ([]byte, error) {
+		var sk bls.SecretKey
+		defer wipeBLSKey(&sk)
+		if err := sk.SetLittleEndianMod(key); err != nil {
+			return nil, errors.Wrap(err, "Derive keys failed")
+		}
+		return sk.GetPublicKey().Serialize(), nil
+	},
+	child: func(key, tweak []byte) ([]byte, error) {
+		var sk, t bls.SecretKey
+		defer wipeBLSKey(&sk)
+		defer wipeBLSKey(&t)
+		if err := sk.SetLittleEndianMod(key); err != nil {
+			return nil, errors.Wrap(err, "Derive keys failed")
+		}
+		if err := t.SetLittleEndianMod(tweak); err != nil {
+			return nil, errors.Wrap(err, "Derive keys failed")
+		}
+		t.Add(&sk)
+		return t.GetLittleEndian(), nil
+	},
+}
+
+func hdSeed(mnemonic, passphrase string) ([]byte, error) {
+	if !IsMnemonicValid(mnemonic) {
+		return nil, errors.New("derive_keys", "Invalid mnemonic")
+	}
+	return mnemonicSeed(mnemonic, passphrase), nil
+}
+
+//DeriveKeys - implement interface
+func (b0 *BLS0ChainScheme) DeriveKeys(mnemonic, path string) (*Wallet, error) {
+	seed, err := hdSeed(mnemonic, "0chain-client-split-key")
+	if err != nil {
+		return nil, err
+	}
+	defer Secret(seed).Zero()
+	key, err := deriveHDKey("bls0chain seed", seed, path, blsNormalDerivation)
+	if err != nil {
+		return nil, err
+	}
+	defer Secret(key).Zero()
+	var sk bls.SecretKey
+	defer wipeBLSKey(&sk)
+	if err := sk.SetLittleEndianMod(key); err != nil {
+		return nil, errors.Wrap(err, "Derive keys failed")
+	}
+	pub := sk.GetPublicKey()
+
+	w := &Wallet{}
+	w.Keys = []KeyPair{{
+		PublicKey:  pub.SerializeToHexStr(),
+		PrivateKey: sk.SerializeToHexStr(),
+	}}
+	w.ClientKey = w.Keys[0].PublicKey
+	w.ClientID = encryption.Hash(pub.Serialize())
+	w.Version = CryptoVersion
+	w.DateCreated = time.Now().String()
+	return w, nil
+}
+
+//DeriveKeys - implement interface
+func (ed *ED255190chainScheme) DeriveKeys(mnemonic, path string) (*Wallet, error) {
+	seed, err := hdSeed(mnemonic, "0chain-client-ed25519-key")
+	if err != nil {
+		return nil, err
+	}
+	defer Secret(seed).Zero()
+	key, err := deriveHDKey("ed25519 seed", seed, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer Secret(key).Zero()
+	private := ed25519.NewKeyFromSeed(key)
+	defer Secret(private).Zero()
+	public := private.Public().(ed25519.PublicKey)
+
+	w := &Wallet{}
+	w.Keys = []KeyPair{{
+		PublicKey:  hex.EncodeToString(public),
+		PrivateKey: hex.EncodeToString(private),
+	}}
+	w.ClientKey = w.Keys[0].PublicKey
+	w.ClientID = encryption.Hash([]byte(public))
+	w.Version = CryptoVersion
+	w.DateCreated = time.Now().String()
+	return w, nil
+}
@@ -0,0 +1,112 @@
+package zcncrypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/herumi/bls-go-binary/bls"
+	"github.com/stretchr/testify/require"
+)
+
+const hdMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveHDKeySLIP10Vector(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	key, err := deriveHDKey("ed25519 seed", seed, "m", nil)
+	require.NoError(t, err)
+	require.Equal(t, "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", hex.EncodeToString(key))
+	key, err = deriveHDKey("ed25519 seed", seed, "m/0'", nil)
+	require.NoError(t, err)
+	require.Equal(t, "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", hex.EncodeToString(key))
+}
+
+func TestParseHDPath(t *testing.T) {
+	indexes, err := ParseHDPath("m/0'/1/2h")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{hardenedOffset, 1, hardenedOffset + 2}, indexes)
+	_, err = ParseHDPath("0'/1'")
+	require.Error(t, err)
+	_, err = ParseHDPath("m/1''")
+	require.Error(t, err)
+	_, err = ParseHDPath("m/x'")
+	require.Error(t, err)
+}
+
+func TestDeriveKeys(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519"} {
+		hd := NewSignatureScheme(scheme).(HDSignatureScheme)
+		w0, err := hd.DeriveKeys(hdMnemonic, AccountPath(0))
+		require.NoError(t, err)
+		w1, err := hd.DeriveKeys(hdMnemonic, AccountPath(1))
+		require.NoError(t, err)
+		require.NotEqual(t, w0.ClientID, w1.ClientID)
+		require.Empty(t, w0.Mnemonic)
+
+		again, err := NewSignatureScheme(scheme).(HDSignatureScheme).DeriveKeys(hdMnemonic, AccountPath(1))
+		require.NoError(t, err)
+		require.Equal(t, w1.ClientID, again.ClientID)
+		require.Equal(t, w1.Keys, again.Keys)
+
+		signer := NewSignatureScheme(scheme)
+		require.NoError(t, signer.SetPrivateKey(w1.Keys[0].PrivateKey))
+		hash := Sha3Sum256("derived")
+		sig, err := signer.Sign(hash)
+		require.NoError(t, err)
+		verifier := NewSignatureScheme(scheme)
+		require.NoError(t, verifier.SetPublicKey(w1.ClientKey))
+		ok, err := verifier.Verify(sig, hash)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, err = hd.DeriveKeys("not a mnemonic", AccountPath(0))
+		require.Error(t, err)
+	}
+}
+
+func TestDeriveKeysNonHardened(t *testing.T) {
+	bls0 := NewSignatureScheme("bls0chain").(HDSignatureScheme)
+	parent, err := bls0.DeriveKeys(hdMnemonic, "m/0'")
+	require.NoError(t, err)
+	c0, err := bls0.DeriveKeys(hdMnemonic, "m/0'/0")
+	require.NoError(t, err)
+	c1, err := bls0.DeriveKeys(hdMnemonic, "m/0'/1")
+	require.NoError(t, err)
+	require.NotEqual(t, parent.ClientID, c0.ClientID)
+	require.NotEqual(t, c0.ClientID, c1.ClientID)
+
+	// the child public key is the parent one plus the public key of the tweak
+	seed, err := hdSeed(hdMnemonic, "0chain-client-split-key")
+	require.NoError(t, err)
+	mac := hmac.New(sha512.New, []byte("bls0chain seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	mac = hmac.New(sha512.New, I[32:])
+	mac.Write(append(append([]byte{0}, I[:32]...), 0x80, 0, 0, 0))
+	I = mac.Sum(nil)
+	data, err := blsNormalDerivation.data(I[:32])
+	require.NoError(t, err)
+	mac = hmac.New(sha512.New, I[32:])
+	mac.Write(append(data, 0, 0, 0, 0))
+	var tweak bls.SecretKey
+	require.NoError(t, tweak.SetLittleEndianMod(mac.Sum(nil)[:32]))
+	var pub bls.PublicKey
+	require.NoError(t, pub.DeserializeHexStr(parent.ClientKey))
+	pub.Add(tweak.GetPublicKey())
+	require.Equal(t, c0.ClientKey, pub.SerializeToHexStr())
+
+	signer := NewSignatureScheme("bls0chain")
+	require.NoError(t, signer.SetPrivateKey(c1.Keys[0].PrivateKey))
+	hash := Sha3Sum256("derived")
+	sig, err := signer.Sign(hash)
+	require.NoError(t, err)
+	verifier := NewSignatureScheme("bls0chain")
+	require.NoError(t, verifier.SetPublicKey(c1.ClientKey))
+	ok, err := verifier.Verify(sig, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = NewSignatureScheme("ed25519").(HDSignatureScheme).DeriveKeys(hdMnemonic, "m/0'/0")
+	require.Error(t, err)
+}
@@ -0,0 +1,303 @@
+package zcncrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedWalletVersion is the version of the encrypted wallet format.
+const EncryptedWalletVersion = 1
+
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+
+	CipherAES256GCM = "aes-256-gcm"
+)
+
+const (
+	keyLen  = 32
+	saltLen = 32
+)
+
+// Maximum KDF parameters accepted, so that a crafted encrypted wallet
+// can't exhaust the memory or the CPU on decryption.
+const (
+	maxScryptN     = 1 << 20
+	maxScryptRP    = 1 << 6 // r*p
+	maxArgonTime   = 16
+	maxArgonMemory = 1 << 21 // 2 GiB in KiB
+)
+
+var (
+	ErrInvalidPassphrase = errors.New("invalid_passphrase", "invalid passphrase or corrupted wallet")
+	ErrEncryptedWallet   = errors.New("encrypted_wallet", "wallet is encrypted, a passphrase is required")
+)
+
+// KDFParams of the key derivation. N, R and P are the scrypt parameters;
+// Time, Memory (KiB) and Threads the argon2id ones.
+type KDFParams struct {
+	Salt    string `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// EncryptedWallet is a wallet encrypted with a key derived from a
+// passphrase. The client ID and key are kept in clear to identify the
+// wallet. The MAC authenticates the header and the ciphertext with a
+// second derived key, so a wrong passphrase is told apart from a
+// corrupted ciphertext.
+type EncryptedWallet struct {
+	Version    int       `json:"version"`
+	ClientID   string    `json:"client_id"`
+	ClientKey  string    `json:"client_key"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdf_params"`
+	Cipher     string    `json:"cipher"`
+	Nonce      string    `json:"nonce"`
+	CipherText string    `json:"ciphertext"`
+	MAC        string    `json:"mac"`
+}
+
+type encryptOptions struct {
+	kdf    string
+	params KDFParams
+}
+
+// EncryptOption configures the wallet encryption.
+type EncryptOption func(*encryptOptions)
+
+// WithScrypt derives the key with scrypt. The default is scrypt with
+// N=2^18, r=8, p=1.
+func WithScrypt(n, r, p int) EncryptOption {
+	return func(o *encryptOptions) {
+		o.kdf = KDFScrypt
+		o.params = KDFParams{N: n, R: r, P: p}
+	}
+}
+
+// WithArgon2id derives the key with argon2id, memory in KiB.
+func WithArgon2id(time, memory uint32, threads uint8) EncryptOption {
+	return func(o *encryptOptions) {
+		o.kdf = KDFArgon2id
+		o.params = KDFParams{Time: time, Memory: memory, Threads: threads}
+	}
+}
+
+// deriveKeys returns the encryption key and the MAC key.
+func deriveKeys(kdf string, params *KDFParams, passphrase string) (encKey, macKey []byte, err error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, nil, errors.New("invalid_kdf_params", "invalid salt")
+	}
+	var key []byte
+	switch kdf {
+	case KDFScrypt:
+		if params.N > maxScryptN || params.R > maxScryptRP || params.P > maxScryptRP ||
+			params.R*params.P > maxScryptRP {
+			return nil, nil, errors.New("invalid_kdf_params", "scrypt parameters exceed the maximum")
+		}
+		key, err = scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, 2*keyLen)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errors.New("invalid_kdf_params", "invalid scrypt parameters"))
+		}
+	case KDFArgon2id:
+		if params.Time == 0 || params.Memory == 0 || params.Threads == 0 {
+			return nil, nil, errors.New("invalid_kdf_params", "invalid argon2id parameters")
+		}
+		if params.Time > maxArgonTime || params.Memory > maxArgonMemory {
+			return nil, nil, errors.New("invalid_kdf_params", "argon2id parameters exceed the maximum")
+		}
+		key = argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, 2*keyLen)
+	default:
+		return nil, nil, errors.New("invalid_kdf", fmt.Sprintf("unsupported kdf %s", kdf))
+	}
+	return key[:keyLen], key[keyLen:], nil
+}
+
+// header returns the authenticated data of the encrypted wallet.
+func (ew *EncryptedWallet) header() []byte {
+	p := ew.KDFParams
+	return []byte(fmt.Sprintf("%d:%s:%s:%s:%s:%d:%d:%d:%d:%d:%d:%s:%s", ew.Version,
+		ew.ClientID, ew.ClientKey, ew.KDF, p.Salt, p.N, p.R, p.P, p.Time,
+		p.Memory, p.Threads, ew.Cipher, ew.Nonce))
+}
+
+func (ew *EncryptedWallet) mac(macKey, cipherText []byte) []byte {
+	h := hmac.New(sha256.New, macKey)
+	h.Write(ew.header())
+	h.Write(cipherText)
+	return h.Sum(nil)
+}
+
+// EncryptWallet encrypts the wallet with the passphrase.
+func EncryptWallet(w *Wallet, passphrase string, opts ...EncryptOption) (*EncryptedWallet, error) {
+	if passphrase == "" {
+		return nil, errors.New("invalid_passphrase", "empty passphrase")
+	}
+	var o = encryptOptions{kdf: KDFScrypt, params: KDFParams{N: 1 << 18, R: 8, P: 1}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	plain, err := json.Marshal(w)
+	if err != nil {
+		return nil, errors.New("wallet_marshal", "Invalid Wallet")
+	}
+	defer Secret(plain).Zero()
+
+	var salt = make([]byte, saltLen)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "generating salt")
+	}
+	o.params.Salt = hex.EncodeToString(salt)
+
+	encKey, macKey, err := deriveKeys(o.kdf, &o.params, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(encKey)
+	if err != nil {
+		return nil, err
+	}
+	var nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generating nonce")
+	}
+
+	var ew = &EncryptedWallet{
+		Version:   EncryptedWalletVersion,
+		ClientID:  w.ClientID,
+		ClientKey: w.ClientKey,
+		KDF:       o.kdf,
+		KDFParams: o.params,
+		Cipher:    CipherAES256GCM,
+		Nonce:     hex.EncodeToString(nonce),
+	}
+	cipherText := aead.Seal(nil, nonce, plain, ew.header())
+	ew.CipherText = hex.EncodeToString(cipherText)
+	ew.MAC = hex.EncodeToString(ew.mac(macKey, cipherText))
+	return ew, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher")
+	}
+	return cipher.NewGCM(block)
+}
+
+// Decrypt decrypts the wallet with the passphrase.
+func (ew *EncryptedWallet) Decrypt(passphrase string) (*Wallet, error) {
+	if ew.Version != EncryptedWalletVersion {
+		return nil, errors.New("invalid_version",
+			fmt.Sprintf("unsupported encrypted wallet version %d", ew.Version))
+	}
+	if ew.Cipher != CipherAES256GCM {
+		return nil, errors.New("invalid_cipher", fmt.Sprintf("unsupported cipher %s", ew.Cipher))
+	}
+	encKey, macKey, err := deriveKeys(ew.KDF, &ew.KDFParams, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(ew.CipherText)
+	if err != nil {
+		return nil, errors.New("invalid_ciphertext", "ciphertext is not hex encoded")
+	}
+	mac, err := hex.DecodeString(ew.MAC)
+	if err != nil || !hmac.Equal(mac, ew.mac(macKey, cipherText)) {
+		return nil, ErrInvalidPassphrase
+	}
+	nonce, err := hex.DecodeString(ew.Nonce)
+	if err != nil {
+		return nil, errors.New("invalid_nonce", "nonce is not hex encoded")
+	}
+	aead, err := newAEAD(encKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid_nonce", "invalid nonce size")
+	}
+	plain, err := aead.Open(nil, nonce, cipherText, ew.header())
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	var w Wallet
+	if err = json.Unmarshal(plain, &w); err != nil {
+		return nil, errors.Wrap(err, "decoding decrypted wallet")
+	}
+	if w.ClientID != ew.ClientID || w.ClientKey != ew.ClientKey {
+		return nil, errors.New("invalid_wallet", "decrypted wallet does not match its client")
+	}
+	return &w, nil
+}
+
+// Marshal returns json string
+func (ew *EncryptedWallet) Marshal() (string, error) {
+	b, err := json.Marshal(ew)
+	if err != nil {
+		return "", errors.New("wallet_marshal", "Invalid encrypted wallet")
+	}
+	return string(b), nil
+}
+
+// UnmarshalEncryptedWallet decodes an encrypted wallet.
+func UnmarshalEncryptedWallet(data string) (*EncryptedWallet, error) {
+	var ew EncryptedWallet
+	if err := json.Unmarshal([]byte(data), &ew); err != nil {
+		return nil, errors.Wrap(err, "decoding encrypted wallet")
+	}
+	if ew.CipherText == "" || ew.KDF == "" {
+		return nil, errors.New("invalid_encrypted_wallet", "missing ciphertext or kdf")
+	}
+	return &ew, nil
+}
+
+// IsEncryptedWallet reports whether the json string is an encrypted wallet.
+func IsEncryptedWallet(data string) bool {
+	var probe struct {
+		CipherText string `json:"ciphertext"`
+		KDF        string `json:"kdf"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &probe); err != nil {
+		return false
+	}
+	return probe.CipherText != "" && probe.KDF != ""
+}
+
+// LoadWallet decodes a wallet json string, plain or encrypted. The
+// passphrase is only used for encrypted wallets.
+func LoadWallet(data, passphrase string) (*Wallet, error) {
+	if !IsEncryptedWallet(data) {
+		var w Wallet
+		if err := json.Unmarshal([]byte(data), &w); err != nil {
+			return nil, errors.Wrap(err, "decoding wallet")
+		}
+		return &w, nil
+	}
+	if passphrase == "" {
+		return nil, ErrEncryptedWallet
+	}
+	ew, err := UnmarshalEncryptedWallet(data)
+	if err != nil {
+		return nil, err
+	}
+	return ew.Decrypt(passphrase)
+}
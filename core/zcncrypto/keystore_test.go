@@ -0,0 +1,119 @@
+package zcncrypto
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// light KDF parameters, for tests only
+var testKDFs = map[string]EncryptOption{
+	KDFScrypt:   WithScrypt(1<<10, 8, 1),
+	KDFArgon2id: WithArgon2id(1, 1024, 1),
+}
+
+func newTestWallet(t *testing.T) *Wallet {
+	w, err := NewSignatureScheme("ed25519").GenerateKeys()
+	require.NoError(t, err)
+	return w
+}
+
+func TestEncryptWallet(t *testing.T) {
+	w := newTestWallet(t)
+
+	for kdf, opt := range testKDFs {
+		t.Run(kdf, func(t *testing.T) {
+			ew, err := EncryptWallet(w, "secret", opt)
+			require.NoError(t, err)
+			require.Equal(t, kdf, ew.KDF)
+
+			data, err := ew.Marshal()
+			require.NoError(t, err)
+			require.NotContains(t, data, w.Keys[0].PrivateKey)
+			require.NotContains(t, data, w.Mnemonic)
+			require.True(t, IsEncryptedWallet(data))
+
+			got, err := LoadWallet(data, "secret")
+			require.NoError(t, err)
+			require.Equal(t, w, got)
+
+			_, err = LoadWallet(data, "wrong")
+			require.Equal(t, ErrInvalidPassphrase, err)
+
+			_, err = LoadWallet(data, "")
+			require.Equal(t, ErrEncryptedWallet, err)
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		ew, err := EncryptWallet(w, "secret", testKDFs[KDFScrypt])
+		require.NoError(t, err)
+		ew.ClientID = "other"
+		_, err = ew.Decrypt("secret")
+		require.Error(t, err)
+	})
+
+	t.Run("excessive kdf params", func(t *testing.T) {
+		for kdf, params := range map[string]KDFParams{
+			KDFScrypt:   {N: 1 << 30, R: 8, P: 1},
+			KDFArgon2id: {Time: 1, Memory: 1 << 30, Threads: 1},
+		} {
+			ew, err := EncryptWallet(w, "secret", testKDFs[kdf])
+			require.NoError(t, err)
+			params.Salt = ew.KDFParams.Salt
+			ew.KDFParams = params
+			_, err = ew.Decrypt("secret")
+			require.Error(t, err, kdf)
+			require.NotEqual(t, ErrInvalidPassphrase, err, kdf)
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		data, err := w.Marshal()
+		require.NoError(t, err)
+		require.False(t, IsEncryptedWallet(data))
+		got, err := LoadWallet(data, "")
+		require.NoError(t, err)
+		require.Equal(t, w, got)
+	})
+}
+
+func TestKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ks, err := OpenKeystore(dir)
+	require.NoError(t, err)
+
+	var (
+		w1  = newTestWallet(t)
+		w2  = newTestWallet(t)
+		kdf = testKDFs[KDFScrypt]
+	)
+	require.NoError(t, ks.Store("ops", w1, "pass1", kdf))
+	require.NoError(t, ks.Store("faucet", w2, "pass2", kdf))
+	require.Error(t, ks.Store("ops", w2, "pass2", kdf))
+	require.Error(t, ks.Store("../escape", w2, "pass2", kdf))
+
+	names, err := ks.Names()
+	require.NoError(t, err)
+	require.Equal(t, []string{"faucet", "ops"}, names)
+
+	got, err := ks.Load("ops", "pass1")
+	require.NoError(t, err)
+	require.Equal(t, w1.ClientID, got.ClientID)
+
+	require.NoError(t, ks.ChangePassphrase("ops", "pass1", "pass3", kdf))
+	_, err = ks.Load("ops", "pass1")
+	require.Error(t, err)
+	_, err = ks.Load("ops", "pass3")
+	require.NoError(t, err)
+
+	require.NoError(t, ks.Delete("faucet"))
+	_, err = ks.Load("faucet", "pass2")
+	require.Equal(t, "wallet_not_found", err.(*errors.Error).Code)
+}
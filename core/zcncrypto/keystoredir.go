@@ -0,0 +1,170 @@
+package zcncrypto
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+const keystoreExt = ".json"
+
+var keystoreNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Keystore is a directory of named encrypted wallets, one file per wallet.
+type Keystore struct {
+	dir string
+}
+
+// OpenKeystore opens the keystore directory, creating it if needed.
+func OpenKeystore(dir string) (*Keystore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "creating keystore directory")
+	}
+	return &Keystore{dir: dir}, nil
+}
+
+func (ks *Keystore) path(name string) (string, error) {
+	if !keystoreNameRe.MatchString(name) || strings.HasSuffix(name, keystoreExt) {
+		return "", errors.New("invalid_wallet_name", fmt.Sprintf("invalid wallet name %q", name))
+	}
+	return filepath.Join(ks.dir, name+keystoreExt), nil
+}
+
+// Names returns the names of the stored wallets, sorted.
+func (ks *Keystore) Names() ([]string, error) {
+	files, err := ioutil.ReadDir(ks.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading keystore directory")
+	}
+	var names []string
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, keystoreExt) {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, keystoreExt))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Has reports whether a wallet is stored with the name.
+func (ks *Keystore) Has(name string) bool {
+	p, err := ks.path(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(p)
+	return err == nil
+}
+
+// Store encrypts the wallet and stores it with the name. It fails if a
+// wallet is already stored with the name.
+func (ks *Keystore) Store(name string, w *Wallet, passphrase string, opts ...EncryptOption) error {
+	ew, err := EncryptWallet(w, passphrase, opts...)
+	if err != nil {
+		return err
+	}
+	return ks.Import(name, ew)
+}
+
+// Import stores the encrypted wallet with the name. It fails if a wallet
+// is already stored with the name.
+func (ks *Keystore) Import(name string, ew *EncryptedWallet) error {
+	if ks.Has(name) {
+		return errors.New("wallet_exists", fmt.Sprintf("wallet %q already exists", name))
+	}
+	return ks.write(name, ew)
+}
+
+func (ks *Keystore) write(name string, ew *EncryptedWallet) error {
+	p, err := ks.path(name)
+	if err != nil {
+		return err
+	}
+	data, err := ew.Marshal()
+	if err != nil {
+		return err
+	}
+	// write and rename, so a stored wallet is never partially written
+	tmp, err := ioutil.TempFile(ks.dir, "."+name+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating wallet file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.WriteString(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		return errors.Wrap(err, "writing wallet file")
+	}
+	return nil
+}
+
+// Export returns the encrypted wallet stored with the name.
+func (ks *Keystore) Export(name string) (*EncryptedWallet, error) {
+	p, err := ks.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, errors.New("wallet_not_found", fmt.Sprintf("wallet %q not found", name))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading wallet file")
+	}
+	return UnmarshalEncryptedWallet(string(data))
+}
+
+// Load decrypts the wallet stored with the name.
+func (ks *Keystore) Load(name, passphrase string) (*Wallet, error) {
+	ew, err := ks.Export(name)
+	if err != nil {
+		return nil, err
+	}
+	return ew.Decrypt(passphrase)
+}
+
+// ChangePassphrase encrypts the wallet stored with the name with a new
+// passphrase.
+func (ks *Keystore) ChangePassphrase(name, oldPassphrase, newPassphrase string, opts ...EncryptOption) error {
+	w, err := ks.Load(name, oldPassphrase)
+	if err != nil {
+		return err
+	}
+	ew, err := EncryptWallet(w, newPassphrase, opts...)
+	if err != nil {
+		return err
+	}
+	return ks.write(name, ew)
+}
+
+// Delete removes the wallet stored with the name.
+func (ks *Keystore) Delete(name string) error {
+	p, err := ks.path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(p); os.IsNotExist(err) {
+		return errors.New("wallet_not_found", fmt.Sprintf("wallet %q not found", name))
+	} else if err != nil {
+		return errors.Wrap(err, "deleting wallet file")
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package zcncrypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MnemonicLanguages are the BIP39 word lists mnemonics can be written in.
+var MnemonicLanguages = map[string][]string{
+	"english":             wordlists.English,
+	"chinese_simplified":  wordlists.ChineseSimplified,
+	"chinese_traditional": wordlists.ChineseTraditional,
+	"italian":             wordlists.Italian,
+	"japanese":            wordlists.Japanese,
+	"korean":              wordlists.Korean,
+	"spanish":             wordlists.Spanish,
+}
+
+// mnemonicLanguageOrder is the order languages are tried in, english
+// first as its words are also in other lists.
+var mnemonicLanguageOrder = []string{"english", "chinese_simplified",
+	"chinese_traditional", "italian", "japanese", "korean", "spanish"}
+
+// mnemonicWordIndex indexes the NFKD normalized words of every language.
+var mnemonicWordIndex = make(map[string]map[string]int, len(MnemonicLanguages))
+
+func init() {
+	for lang, list := range MnemonicLanguages {
+		index := make(map[string]int, len(list))
+		for i, w := range list {
+			index[norm.NFKD.String(w)] = i
+		}
+		mnemonicWordIndex[lang] = index
+	}
+}
+
+// mnemonicWords returns the NFKD normalized words of the mnemonic.
+func mnemonicWords(mnemonic string) []string {
+	return strings.Fields(norm.NFKD.String(mnemonic))
+}
+
+// mnemonicSeed returns the BIP39 seed of the mnemonic and the passphrase,
+// both NFKD normalized as BIP39 asks.
+func mnemonicSeed(mnemonic, passphrase string) []byte {
+	return bip39.NewSeed(norm.NFKD.String(mnemonic), norm.NFKD.String(passphrase))
+}
+
+// PassphraseSignatureScheme generates keys from a mnemonic and a BIP39
+// passphrase. An empty passphrase generates the keys RecoverKeys does.
+type PassphraseSignatureScheme interface {
+	SignatureScheme
+	RecoverKeysWithPassphrase(mnemonic, passphrase string) (*Wallet, error)
+}
+
+// NewMnemonic returns a new 24 words mnemonic in the language.
+func NewMnemonic(language string) (string, error) {
+	list, ok := MnemonicLanguages[language]
+	if !ok {
+		return "", errors.New("new_mnemonic", "unknown mnemonic language "+language)
+	}
+	entropy := make([]byte, 32)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", errors.Wrap(err, "Getting entropy failed")
+	}
+	var (
+		sum  = sha256.Sum256(entropy)
+		bits = new(big.Int).SetBytes(append(entropy, sum[0]))
+		mask = big.NewInt(2047)
+		idx  = new(big.Int)
+	)
+	words := make([]string, 24)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = list[idx.And(bits, mask).Int64()]
+		bits.Rsh(bits, 11)
+	}
+	return strings.Join(words, mnemonicSeparator(language)), nil
+}
+
+// mnemonicSeparator is the ideographic space for japanese, as BIP39 asks.
+func mnemonicSeparator(language string) string {
+	if language == "japanese" {
+		return "　"
+	}
+	return " "
+}
+
+// MnemonicLanguage returns the language of the mnemonic words, empty if
+// they are not all in one word list.
+func MnemonicLanguage(mnemonic string) string {
+	words := mnemonicWords(mnemonic)
+	if len(words) == 0 {
+		return ""
+	}
+	for _, lang := range mnemonicLanguageOrder {
+		if mnemonicInLanguage(words, lang) {
+			return lang
+		}
+	}
+	return ""
+}
+
+func mnemonicInLanguage(words []string, language string) bool {
+	index := mnemonicWordIndex[language]
+	for _, w := range words {
+		if _, ok := index[w]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsMnemonicValid reports whether the mnemonic has 12 to 24 words, a
+// multiple of 3, all in the word list of one language. The checksum is
+// not checked, see IsMnemonicChecksumValid.
+func IsMnemonicValid(mnemonic string) bool {
+	n := len(mnemonicWords(mnemonic))
+	if n%3 != 0 || n < 12 || n > 24 {
+		return false
+	}
+	return MnemonicLanguage(mnemonic) != ""
+}
+
+// IsMnemonicChecksumValid reports whether the mnemonic is valid and its
+// last bits are the checksum of its entropy.
+func IsMnemonicChecksumValid(mnemonic string) bool {
+	if !IsMnemonicValid(mnemonic) {
+		return false
+	}
+	return mnemonicChecksumValid(mnemonicWords(mnemonic), mnemonicWordIndex[MnemonicLanguage(mnemonic)])
+}
+
+func mnemonicChecksumValid(words []string, index map[string]int) bool {
+	var (
+		bits   = new(big.Int)
+		csBits = uint(len(words) / 3)
+	)
+	for _, w := range words {
+		i, ok := index[w]
+		if !ok {
+			return false
+		}
+		bits.Lsh(bits, 11).Or(bits, big.NewInt(int64(i)))
+	}
+	checksum := new(big.Int).And(bits, big.NewInt(1<<csBits-1)).Int64()
+	entropy := make([]byte, len(words)*4/3)
+	raw := new(big.Int).Rsh(bits, csBits).Bytes()
+	copy(entropy[len(entropy)-len(raw):], raw)
+	sum := sha256.Sum256(entropy)
+	return int64(sum[0]>>(8-csBits)) == checksum
+}
+
+// SuggestMnemonics returns the mnemonics with a valid checksum that are
+// one mistyped word, or two swapped adjacent words, away from the
+// mnemonic. A mistyped word is replaced by the words of the list at most
+// two edits away. Suggestions closest to the mnemonic come first.
+func SuggestMnemonics(mnemonic string) ([]string, error) {
+	words := mnemonicWords(mnemonic)
+	if n := len(words); n%3 != 0 || n < 12 || n > 24 {
+		return nil, errors.New("suggest_mnemonics", "mnemonic must have 12, 15, 18, 21 or 24 words")
+	}
+	lang, unknown := mnemonicBestLanguage(words)
+	if len(unknown) > 1 {
+		return nil, errors.New("suggest_mnemonics", "more than one unknown word")
+	}
+	var (
+		index       = mnemonicWordIndex[lang]
+		sep         = mnemonicSeparator(lang)
+		suggestions []suggestion
+		candidate   = make([]string, len(words))
+	)
+	try := func(distance int) {
+		if mnemonicChecksumValid(candidate, index) {
+			suggestions = append(suggestions, suggestion{strings.Join(candidate, sep), distance})
+		}
+	}
+	positions := unknown
+	if len(positions) == 0 {
+		if mnemonicChecksumValid(words, index) {
+			return []string{strings.Join(words, sep)}, nil
+		}
+		for i := range words {
+			positions = append(positions, i)
+		}
+		// swapped words
+		for i := 0; i+1 < len(words); i++ {
+			copy(candidate, words)
+			candidate[i], candidate[i+1] = candidate[i+1], candidate[i]
+			try(1)
+		}
+	}
+	for _, pos := range positions {
+		copy(candidate, words)
+		for _, w := range MnemonicLanguages[lang] {
+			w = norm.NFKD.String(w)
+			if w == words[pos] {
+				continue
+			}
+			d := editDistance(words[pos], w)
+			if d > 2 {
+				continue
+			}
+			candidate[pos] = w
+			try(d)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+	result := make([]string, 0, len(suggestions))
+	seen := make(map[string]struct{}, len(suggestions))
+	for _, s := range suggestions {
+		if _, ok := seen[s.mnemonic]; !ok {
+			seen[s.mnemonic] = struct{}{}
+			result = append(result, s.mnemonic)
+		}
+	}
+	return result, nil
+}
+
+type suggestion struct {
+	mnemonic string
+	distance int
+}
+
+// mnemonicBestLanguage returns the language with the fewest unknown words
+// in the mnemonic and their positions.
+func mnemonicBestLanguage(words []string) (string, []int) {
+	var (
+		best    string
+		unknown []int
+	)
+	for _, lang := range mnemonicLanguageOrder {
+		index := mnemonicWordIndex[lang]
+		var u []int
+		for i, w := range words {
+			if _, ok := index[w]; !ok {
+				u = append(u, i)
+			}
+		}
+		if best == "" || len(u) < len(unknown) {
+			best, unknown = lang, u
+		}
+	}
+	return best, unknown
+}
+
+// editDistance is the Damerau-Levenshtein distance of the words, with
+// adjacent transpositions.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,107 @@
+package zcncrypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/unicode/norm"
+)
+
+var bip39Mnemonic = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+func TestMnemonicChecksum(t *testing.T) {
+	require.True(t, IsMnemonicChecksumValid(bip39Mnemonic))
+	require.True(t, IsMnemonicValid("legal winner thank year wave sausage worth useful legal winner thank year"))
+	require.False(t, IsMnemonicChecksumValid("legal winner thank year wave sausage worth useful legal winner thank year"))
+	require.False(t, IsMnemonicValid("legal winner thank year"))
+
+	for lang := range MnemonicLanguages {
+		m, err := NewMnemonic(lang)
+		require.NoError(t, err)
+		require.Len(t, strings.Fields(m), 24)
+		require.True(t, IsMnemonicChecksumValid(m), lang)
+		if !strings.HasPrefix(lang, "chinese") {
+			require.Equal(t, lang, MnemonicLanguage(m))
+		}
+	}
+	_, err := NewMnemonic("klingon")
+	require.Error(t, err)
+}
+
+func TestMnemonicNormalization(t *testing.T) {
+	var m string
+	for !strings.ContainsAny(norm.NFC.String(m), "áéíóúñ") {
+		var err error
+		m, err = NewMnemonic("spanish")
+		require.NoError(t, err)
+	}
+	nfc, nfkd := norm.NFC.String(m), norm.NFKD.String(m)
+	require.NotEqual(t, nfc, nfkd)
+	for _, mnemonic := range []string{nfc, nfkd} {
+		require.True(t, IsMnemonicChecksumValid(mnemonic))
+		require.Equal(t, "spanish", MnemonicLanguage(mnemonic))
+	}
+
+	for _, scheme := range []string{"bls0chain", "ed25519"} {
+		a, err := NewSignatureScheme(scheme).(PassphraseSignatureScheme).
+			RecoverKeysWithPassphrase(nfc, norm.NFC.String("contraseña"))
+		require.NoError(t, err)
+		b, err := NewSignatureScheme(scheme).(PassphraseSignatureScheme).
+			RecoverKeysWithPassphrase(nfkd, norm.NFKD.String("contraseña"))
+		require.NoError(t, err)
+		require.Equal(t, a.Keys, b.Keys, scheme)
+	}
+
+	// the language of words in several lists doesn't depend on map order
+	chinese := strings.Join(MnemonicLanguages["chinese_simplified"][:12], " ")
+	for i := 0; i < 10; i++ {
+		require.Equal(t, MnemonicLanguage(chinese), MnemonicLanguage(chinese))
+	}
+}
+
+func TestRecoverKeysWithPassphrase(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519", "secp256k1"} {
+		w, err := NewSignatureScheme(scheme).RecoverKeys(bip39Mnemonic)
+		require.NoError(t, err)
+		ps := NewSignatureScheme(scheme).(PassphraseSignatureScheme)
+		pw, err := ps.RecoverKeysWithPassphrase(bip39Mnemonic, "")
+		require.NoError(t, err)
+		require.Equal(t, w.Keys, pw.Keys, "empty passphrase must recover existing wallets")
+
+		ps = NewSignatureScheme(scheme).(PassphraseSignatureScheme)
+		pw, err = ps.RecoverKeysWithPassphrase(bip39Mnemonic, "TREZOR")
+		require.NoError(t, err)
+		require.NotEqual(t, w.ClientID, pw.ClientID)
+	}
+}
+
+func TestSuggestMnemonics(t *testing.T) {
+	s, err := SuggestMnemonics(bip39Mnemonic)
+	require.NoError(t, err)
+	require.Equal(t, []string{bip39Mnemonic}, s)
+
+	// mistyped word
+	s, err = SuggestMnemonics(strings.Replace(bip39Mnemonic, "wave", "wavs", 1))
+	require.NoError(t, err)
+	require.Contains(t, s, bip39Mnemonic)
+
+	// swapped words, the checksum catches most of them
+	for {
+		m, err := NewMnemonic("english")
+		require.NoError(t, err)
+		words := strings.Fields(m)
+		words[3], words[4] = words[4], words[3]
+		swapped := strings.Join(words, " ")
+		if IsMnemonicChecksumValid(swapped) {
+			continue
+		}
+		s, err = SuggestMnemonics(swapped)
+		require.NoError(t, err)
+		require.Contains(t, s, m)
+		break
+	}
+
+	_, err = SuggestMnemonics("legal winner thank year wavs sausage worth useful legal winnr thank yellow")
+	require.Error(t, err)
+}
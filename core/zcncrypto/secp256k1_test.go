@@ -0,0 +1,74 @@
+package zcncrypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+// private key 1, its public key is the generator point
+var secpPrivateKey = `0000000000000000000000000000000000000000000000000000000000000001`
+var secpPublicKey = `0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798`
+
+func TestSecp256k1KeyFromSeed(t *testing.T) {
+	// BIP-32 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	private, err := secp256k1KeyFromSeed(seed)
+	require.NoError(t, err)
+	require.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		hex.EncodeToString(private.Serialize()))
+	require.Equal(t, "0339a36013301597daef41fbe593a02cc513d0b55527ec2df1050e2e8ff49c85c2",
+		hex.EncodeToString(private.PubKey().SerializeCompressed()))
+}
+
+func TestSecp256k1SignKnownAnswer(t *testing.T) {
+	// RFC 6979 deterministic signature of sha256("Satoshi Nakamoto")
+	hash := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	signer := NewSignatureScheme("secp256k1")
+	require.NoError(t, signer.SetPrivateKey(secpPrivateKey))
+	require.Equal(t, secpPublicKey, signer.GetPublicKey())
+	signature, err := signer.Sign(hex.EncodeToString(hash[:]))
+	require.NoError(t, err)
+	require.Len(t, signature, 130)
+	require.Equal(t, "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"+
+		"2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5", signature[2:])
+
+	verifier := NewSignatureScheme("secp256k1")
+	require.NoError(t, verifier.SetPublicKey(secpPublicKey))
+	ok, err := verifier.Verify(signature, hex.EncodeToString(hash[:]))
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = verifier.Verify(signature, Sha3Sum256(data))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestSecp256k1GenerateAndRecoverKeys(t *testing.T) {
+	w, err := NewSignatureScheme("secp256k1").GenerateKeys()
+	require.NoError(t, err)
+	require.Len(t, w.Keys, 1)
+	pk, _ := hex.DecodeString(w.ClientKey)
+	require.Equal(t, encryption.Hash(pk), w.ClientID)
+	clientID, err := ClientIDFromPublicKey(w.ClientKey)
+	require.NoError(t, err)
+	require.Equal(t, w.ClientID, clientID)
+
+	r, err := NewSignatureScheme("secp256k1").RecoverKeys(w.Mnemonic)
+	require.NoError(t, err)
+	require.Equal(t, w.ClientID, r.ClientID)
+	require.Equal(t, w.Keys, r.Keys)
+
+	hash := Sha3Sum256(data)
+	signer := NewSignatureScheme("secp256k1")
+	require.NoError(t, signer.SetPrivateKey(w.Keys[0].PrivateKey))
+	signature, err := signer.Sign(hash)
+	require.NoError(t, err)
+	verifier := NewSignatureScheme("secp256k1")
+	require.NoError(t, verifier.SetPublicKey(w.ClientKey))
+	ok, err := verifier.Verify(signature, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,209 @@
+package zcncrypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/tyler-smith/go-bip39"
+)
+
+//Secp256k1chainScheme - a signature scheme based on secp256k1 ECDSA. Public
+//keys are compressed, signatures are 65 bytes compact recoverable ones.
+type Secp256k1chainScheme struct {
+	privateKey *btcec.PrivateKey
+	publicKey  *btcec.PublicKey
+	mnemonic   string
+	passphrase string
+}
+
+// NewSecp256k1chainScheme - create a Secp256k1chainScheme object
+func NewSecp256k1chainScheme() *Secp256k1chainScheme {
+	return &Secp256k1chainScheme{}
+}
+
+// secp256k1KeyFromSeed returns the BIP-32 master key of the seed.
+func secp256k1KeyFromSeed(seed []byte) (*btcec.PrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	k := mac.Sum(nil)[:32]
+	if !validSecp256k1Key(k) {
+		return nil, errors.New("generate_keys", "Invalid key for the seed")
+	}
+	private, _ := btcec.PrivKeyFromBytes(btcec.S256(), k)
+	return private, nil
+}
+
+func validSecp256k1Key(k []byte) bool {
+	var n = btcec.S256().N
+	var d = new(big.Int).SetBytes(k)
+	return d.Sign() > 0 && d.Cmp(n) < 0
+}
+
+//GenerateKeys - implement interface
+func (sc *Secp256k1chainScheme) GenerateKeys() (*Wallet, error) {
+	// Check for recovery
+	if len(sc.mnemonic) == 0 {
+		entropy, err := bip39.NewEntropy(256)
+		if err != nil {
+			return nil, errors.New("generate_keys", "Getting entropy failed")
+		}
+		sc.mnemonic, err = bip39.NewMnemonic(entropy)
+		if err != nil {
+			return nil, errors.New("generate_keys", "Getting mnemonic failed")
+		}
+	}
+
+	seed := mnemonicSeed(sc.mnemonic, "0chain-client-secp256k1-key"+sc.passphrase)
+	defer Secret(seed).Zero()
+	private, err := secp256k1KeyFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	public := private.PubKey().SerializeCompressed()
+	// New Wallet
+	w := &Wallet{}
+	w.Keys = make([]KeyPair, 1)
+	w.Keys[0].PublicKey = hex.EncodeToString(public)
+	w.Keys[0].PrivateKey = hex.EncodeToString(private.Serialize())
+	w.ClientKey = w.Keys[0].PublicKey
+	w.ClientID = encryption.Hash(public)
+	w.Mnemonic = sc.mnemonic
+	w.Version = CryptoVersion
+	w.DateCreated = time.Now().String()
+	return w, nil
+}
+
+func (sc *Secp256k1chainScheme) RecoverKeys(mnemonic string) (*Wallet, error) {
+	if mnemonic == "" {
+		return nil, errors.New("chain_scheme_recover_keys", "Set mnemonic key failed")
+	}
+	if sc.privateKey != nil || sc.publicKey != nil {
+		return nil, errors.New("chain_scheme_recover_keys", "Cannot recover when there are keys")
+	}
+	sc.mnemonic = mnemonic
+	return sc.GenerateKeys()
+}
+
+// RecoverKeysWithPassphrase - implement PassphraseSignatureScheme
+func (sc *Secp256k1chainScheme) RecoverKeysWithPassphrase(mnemonic, passphrase string) (*Wallet, error) {
+	sc.passphrase = passphrase
+	return sc.RecoverKeys(mnemonic)
+}
+
+func (sc *Secp256k1chainScheme) SetPrivateKey(privateKey string) error {
+	if sc.publicKey != nil {
+		return errors.New("set_private_key", "cannot set private key when there is a public key")
+	}
+	if sc.privateKey != nil {
+		return errors.New("set_private_key", "private key already exists")
+	}
+	k, err := hex.DecodeString(privateKey)
+	if err != nil {
+		return err
+	}
+	defer Secret(k).Zero()
+	if len(k) != btcec.PrivKeyBytesLen || !validSecp256k1Key(k) {
+		return errors.New("set_private_key", "invalid secp256k1 private key")
+	}
+	sc.privateKey, _ = btcec.PrivKeyFromBytes(btcec.S256(), k)
+	return nil
+}
+
+func (sc *Secp256k1chainScheme) SetPublicKey(publicKey string) error {
+	if sc.privateKey != nil {
+		return errors.New("set_public_key", "cannot set public key when there is a private key")
+	}
+	if sc.publicKey != nil {
+		return errors.New("set_public_key", "public key already exists")
+	}
+	k, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	sc.publicKey, err = btcec.ParsePubKey(k, btcec.S256())
+	return err
+}
+
+func (sc *Secp256k1chainScheme) Sign(hash string) (string, error) {
+	if sc.privateKey == nil {
+		return "", errors.New("chain_scheme_sign", "private key does not exists for signing")
+	}
+	rawHash, err := hex.DecodeString(hash)
+	if err != nil {
+		return "", err
+	}
+	if rawHash == nil {
+		return "", errors.New("chain_scheme_sign", "Failed hash while signing")
+	}
+	sig, err := btcec.SignCompact(btcec.S256(), sc.privateKey, rawHash, true)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+func (sc *Secp256k1chainScheme) Verify(signature, msg string) (bool, error) {
+	if sc.publicKey == nil {
+		return false, errors.New("chain_scheme_verify", "public key does not exists for verification")
+	}
+	sign, err := hex.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+	data, err := hex.DecodeString(msg)
+	if err != nil {
+		return false, err
+	}
+	public, _, err := btcec.RecoverCompact(btcec.S256(), sign, data)
+	if err != nil {
+		return false, nil
+	}
+	return public.IsEqual(sc.publicKey), nil
+}
+
+func (sc *Secp256k1chainScheme) Add(signature, msg string) (string, error) {
+	return "", errors.New("chain_scheme_add", "Not supported by signature scheme")
+}
+
+//GetPublicKey - implement interface
+func (sc *Secp256k1chainScheme) GetPublicKey() string {
+	switch {
+	case sc.publicKey != nil:
+		return hex.EncodeToString(sc.publicKey.SerializeCompressed())
+	case sc.privateKey != nil:
+		return hex.EncodeToString(sc.privateKey.PubKey().SerializeCompressed())
+	}
+	return ""
+}
+
+//GetPrivateKey - implement interface
+func (sc *Secp256k1chainScheme) GetPrivateKey() string {
+	if sc.privateKey == nil {
+		return ""
+	}
+	return hex.EncodeToString(sc.privateKey.Serialize())
+}
+
+// Zero - implement WipeableSignatureScheme
+func (sc *Secp256k1chainScheme) Zero() {
+	if sc.privateKey == nil {
+		return
+	}
+	wipeBigInt(sc.privateKey.D)
+	sc.privateKey = nil
+}
+
+func wipeBigInt(d *big.Int) {
+	words := d.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	d.SetInt64(0)
+}
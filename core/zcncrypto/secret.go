@@ -0,0 +1,139 @@
+package zcncrypto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret is key material. It is redacted when formatted or logged, wipe it
+// with Zero once used.
+type Secret []byte
+
+// NewSecret decodes the hex encoded key.
+func NewSecret(hexKey string) (Secret, error) {
+	return hex.DecodeString(hexKey)
+}
+
+// Hex returns the hex encoded key. Go strings can't be wiped, only call it
+// to store the key.
+func (s Secret) Hex() string {
+	return hex.EncodeToString(s)
+}
+
+// Zero wipes the key.
+func (s Secret) Zero() {
+	for i := range s {
+		s[i] = 0
+	}
+}
+
+func (s Secret) String() string { return redacted }
+
+func (s Secret) GoString() string { return redacted }
+
+// Format redacts the key for every verb, %x included.
+func (s Secret) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
+
+// MarshalJSON encodes the key in hex.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Hex())
+}
+
+// UnmarshalJSON decodes the hex encoded key.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var hexKey string
+	if err := json.Unmarshal(data, &hexKey); err != nil {
+		return err
+	}
+	if hexKey == redacted {
+		return errRedacted
+	}
+	key, err := NewSecret(hexKey)
+	if err != nil {
+		return err
+	}
+	*s = key
+	return nil
+}
+
+// SecretPhrase is a secret text, the wallet mnemonic. It is redacted like
+// Secret.
+type SecretPhrase []byte
+
+// Text returns the phrase. Go strings can't be wiped, only call it to use
+// or store the phrase.
+func (p SecretPhrase) Text() string {
+	return string(p)
+}
+
+// Zero wipes the phrase.
+func (p SecretPhrase) Zero() {
+	Secret(p).Zero()
+}
+
+func (p SecretPhrase) String() string { return redacted }
+
+func (p SecretPhrase) GoString() string { return redacted }
+
+func (p SecretPhrase) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
+
+func (p SecretPhrase) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Text())
+}
+
+func (p *SecretPhrase) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	if text == redacted {
+		return errRedacted
+	}
+	*p = SecretPhrase(text)
+	return nil
+}
+
+// errRedacted is returned when decoding redacted output as a secret.
+var errRedacted = errors.New("redacted_secret", "the secret was redacted")
+
+func redactString(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// formatRedacted formats v, a copy of a value with its secrets redacted,
+// with the verb and the flags of f.
+func formatRedacted(f fmt.State, verb rune, v interface{}) {
+	var format = "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			format += string(flag)
+		}
+	}
+	fmt.Fprintf(f, format+string(verb), v)
+}
+
+// WipeableSignatureScheme wipes the private key it holds.
+type WipeableSignatureScheme interface {
+	SignatureScheme
+	Zero()
+}
+
+// WipeKeys wipes the private key of the signature scheme, if it can.
+func WipeKeys(ss SignatureScheme) {
+	if w, ok := ss.(WipeableSignatureScheme); ok {
+		w.Zero()
+	}
+}
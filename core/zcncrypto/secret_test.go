@@ -0,0 +1,91 @@
+package zcncrypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	s, err := NewSecret(signPrivatekey)
+	require.NoError(t, err)
+	require.Equal(t, signPrivatekey, s.Hex())
+	for _, f := range []string{"%v", "%+v", "%#v", "%s", "%x", "%q"} {
+		require.Equal(t, redacted, fmt.Sprintf(f, s), f)
+	}
+	// JSON is lossless and refuses redacted output
+	b, err := json.Marshal(struct{ Key Secret }{s})
+	require.NoError(t, err)
+	var got struct{ Key Secret }
+	require.NoError(t, json.Unmarshal(b, &got))
+	require.Equal(t, s, got.Key)
+	require.Error(t, json.Unmarshal([]byte(`{"Key":"`+fmt.Sprint(s)+`"}`), &got))
+
+	p := SecretPhrase("phrase")
+	b, err = json.Marshal(struct{ Phrase SecretPhrase }{p})
+	require.NoError(t, err)
+	var gotPhrase struct{ Phrase SecretPhrase }
+	require.NoError(t, json.Unmarshal(b, &gotPhrase))
+	require.Equal(t, p, gotPhrase.Phrase)
+	require.Error(t, json.Unmarshal([]byte(`{"Phrase":"`+fmt.Sprint(p)+`"}`), &gotPhrase))
+
+	s.Zero()
+	require.Equal(t, make(Secret, len(s)), s)
+}
+
+func TestWalletRedaction(t *testing.T) {
+	w, err := NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	privateKey, mnemonic := w.Keys[0].PrivateKey, w.Mnemonic
+	for _, f := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range []interface{}{w, *w, w.Keys, w.Keys[0]} {
+			out := fmt.Sprintf(f, v)
+			require.NotContains(t, out, privateKey, f)
+			require.NotContains(t, out, strings.Fields(mnemonic)[0]+" ", f)
+		}
+	}
+	// json.Marshal and the storage format keep the keys
+	b, err := json.Marshal(w)
+	require.NoError(t, err)
+	ws, err := w.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, string(b), ws)
+	require.Contains(t, ws, privateKey)
+	require.Contains(t, ws, mnemonic)
+	var got Wallet
+	require.NoError(t, json.Unmarshal([]byte(ws), &got))
+	require.Equal(t, *w, got)
+}
+
+func TestWipeKeys(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519", "secp256k1"} {
+		w, err := NewSignatureScheme(scheme).GenerateKeys()
+		require.NoError(t, err)
+		ss := NewSignatureScheme(scheme)
+		require.NoError(t, ss.SetPrivateKey(w.Keys[0].PrivateKey))
+		_, err = ss.Sign(Sha3Sum256(data))
+		require.NoError(t, err)
+
+		WipeKeys(ss)
+		_, err = ss.Sign(Sha3Sum256(data))
+		require.Error(t, err, scheme)
+	}
+
+	ed := NewED255190chainScheme()
+	w, err := ed.GenerateKeys()
+	require.NoError(t, err)
+	require.NoError(t, ed.SetPrivateKey(w.Keys[0].PrivateKey))
+	key := ed.privateKey
+	ed.Zero()
+	require.Equal(t, make(Secret, len(key)), key)
+
+	b0 := NewBLS0ChainScheme()
+	_, err = b0.GenerateKeys()
+	require.NoError(t, err)
+	b0.Zero()
+	require.Empty(t, b0.PrivateKey)
+	require.Empty(t, b0.Mnemonic)
+}
@@ -0,0 +1,192 @@
+package zcncrypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+// Signer signs hashes with a key it holds. The key can live in memory, in
+// an HSM, in a KMS or in a signing daemon.
+type Signer interface {
+	// PublicKey returns the hex encoded public key of the signing key.
+	PublicKey() string
+	// Sign signs the hash and returns the hex encoded signature.
+	Sign(hash string) (string, error)
+}
+
+// SignerFunc is a Signer of a public key and a signing function.
+type SignerFunc struct {
+	Key  string
+	Func func(hash string) (string, error)
+}
+
+func (sf *SignerFunc) PublicKey() string { return sf.Key }
+
+func (sf *SignerFunc) Sign(hash string) (string, error) { return sf.Func(hash) }
+
+// ClientIDFromPublicKey returns the client ID of the hex encoded public key.
+func ClientIDFromPublicKey(publicKey string) (string, error) {
+	pk, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return "", errors.New("invalid_public_key", "public key is not hex encoded")
+	}
+	return encryption.Hash(pk), nil
+}
+
+// AggregateSignatures combines the signatures of the same message. Only
+// bls0chain signatures can be combined.
+func AggregateSignatures(scheme string, signatures ...string) (string, error) {
+	if scheme != "bls0chain" {
+		return "", errors.New("invalid_signature_scheme",
+			fmt.Sprintf("%s signatures can't be aggregated", scheme))
+	}
+	if len(signatures) == 0 {
+		return "", errors.New("invalid_signature", "no signature to aggregate")
+	}
+	var agg bls.Sign
+	if err := agg.DeserializeHexStr(signatures[0]); err != nil {
+		return "", errors.Wrap(err, "invalid signature")
+	}
+	for _, s := range signatures[1:] {
+		var sign bls.Sign
+		if err := sign.DeserializeHexStr(s); err != nil {
+			return "", errors.Wrap(err, "invalid signature")
+		}
+		agg.Add(&sign)
+	}
+	return agg.SerializeToHexStr(), nil
+}
+
+// keySigner signs with in-memory private keys. Signatures of several keys
+// are aggregated.
+type keySigner struct {
+	scheme    string
+	publicKey string
+	keys      []KeyPair
+}
+
+// NewKeySigner returns a signer of the in-memory keys, with the given
+// public key. Several keys are only supported by bls0chain, their
+// signatures are aggregated.
+func NewKeySigner(scheme, publicKey string, keys ...KeyPair) Signer {
+	return &keySigner{scheme: scheme, publicKey: publicKey, keys: keys}
+}
+
+// NewWalletSigner returns a signer of all the keys of the wallet.
+func NewWalletSigner(scheme string, w *Wallet) Signer {
+	return NewKeySigner(scheme, w.ClientKey, w.Keys...)
+}
+
+func (ks *keySigner) PublicKey() string {
+	return ks.publicKey
+}
+
+func (ks *keySigner) Sign(hash string) (string, error) {
+	var signature string
+	for _, kv := range ks.keys {
+		ss := NewSignatureScheme(ks.scheme)
+		if err := ss.SetPrivateKey(kv.PrivateKey); err != nil {
+			return "", err
+		}
+		var err error
+		if len(signature) == 0 {
+			signature, err = ss.Sign(hash)
+		} else {
+			signature, err = ss.Add(signature, hash)
+		}
+		WipeKeys(ss)
+		if err != nil {
+			return "", err
+		}
+	}
+	return signature, nil
+}
+
+// RemoteSigner signs with a signing service over HTTP. The service is
+// sent {"public_key", "hash"} and responds {"signature"}. The returned
+// signature is verified against the public key.
+type RemoteSigner struct {
+	URL      string
+	Scheme   string
+	Key      string
+	Header   http.Header
+	Client   *http.Client
+	NoVerify bool
+}
+
+// NewRemoteSigner returns a signer of the signing service.
+func NewRemoteSigner(url, scheme, publicKey string) *RemoteSigner {
+	return &RemoteSigner{
+		URL:    url,
+		Scheme: scheme,
+		Key:    publicKey,
+		Header: make(http.Header),
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (rs *RemoteSigner) PublicKey() string {
+	return rs.Key
+}
+
+type remoteSignRequest struct {
+	PublicKey string `json:"public_key"`
+	Hash      string `json:"hash"`
+}
+
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+func (rs *RemoteSigner) Sign(hash string) (string, error) {
+	body, err := json.Marshal(&remoteSignRequest{PublicKey: rs.Key, Hash: hash})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, rs.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "creating sign request")
+	}
+	for k, v := range rs.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rs.Client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "remote signer not reachable")
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "reading sign response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("remote_signer",
+			fmt.Sprintf("status %d: %s", resp.StatusCode, respBody))
+	}
+	var sr remoteSignResponse
+	if err = json.Unmarshal(respBody, &sr); err != nil || sr.Signature == "" {
+		return "", errors.New("remote_signer", "invalid sign response")
+	}
+	if rs.NoVerify {
+		return sr.Signature, nil
+	}
+	ss := NewSignatureScheme(rs.Scheme)
+	if err = ss.SetPublicKey(rs.Key); err != nil {
+		return "", err
+	}
+	if ok, err := ss.Verify(sr.Signature, hash); err != nil || !ok {
+		return "", errors.New("remote_signer", "invalid signature from remote signer")
+	}
+	return sr.Signature, nil
+}
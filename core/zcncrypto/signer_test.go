@@ -0,0 +1,81 @@
+package zcncrypto
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeSigningDaemon serves the remote signer protocol with the wallet.
+func newFakeSigningDaemon(t *testing.T, w *Wallet) *httptest.Server {
+	signer := NewWalletSigner("bls0chain", w)
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req remoteSignRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.PublicKey != w.ClientKey {
+			http.Error(rw, "unknown key", http.StatusNotFound)
+			return
+		}
+		sig, err := signer.Sign(req.Hash)
+		require.NoError(t, err)
+		json.NewEncoder(rw).Encode(&remoteSignResponse{Signature: sig})
+	}))
+}
+
+func TestSigner(t *testing.T) {
+	w, err := NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+
+	var hash = encryption.Hash("data")
+
+	verify := func(sig string) {
+		ss := NewSignatureScheme("bls0chain")
+		require.NoError(t, ss.SetPublicKey(w.ClientKey))
+		ok, err := ss.Verify(sig, hash)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	clientID, err := ClientIDFromPublicKey(w.ClientKey)
+	require.NoError(t, err)
+	require.Equal(t, w.ClientID, clientID)
+
+	t.Run("wallet", func(t *testing.T) {
+		sig, err := NewWalletSigner("bls0chain", w).Sign(hash)
+		require.NoError(t, err)
+		verify(sig)
+	})
+
+	t.Run("remote", func(t *testing.T) {
+		daemon := newFakeSigningDaemon(t, w)
+		defer daemon.Close()
+
+		sig, err := NewRemoteSigner(daemon.URL, "bls0chain", w.ClientKey).Sign(hash)
+		require.NoError(t, err)
+		verify(sig)
+
+		other, err := NewSignatureScheme("bls0chain").GenerateKeys()
+		require.NoError(t, err)
+		_, err = NewRemoteSigner(daemon.URL, "bls0chain", other.ClientKey).Sign(hash)
+		require.Error(t, err)
+	})
+
+	t.Run("split keys", func(t *testing.T) {
+		ss := NewBLS0ChainScheme()
+		require.NoError(t, ss.SetPrivateKey(w.Keys[0].PrivateKey))
+		sw, err := ss.SplitKeys(2)
+		require.NoError(t, err)
+
+		first, err := NewKeySigner("bls0chain", sw.ClientKey, sw.Keys[0]).Sign(hash)
+		require.NoError(t, err)
+		second, err := NewKeySigner("bls0chain", sw.ClientKey, sw.Keys[1]).Sign(hash)
+		require.NoError(t, err)
+		sig, err := AggregateSignatures("bls0chain", first, second)
+		require.NoError(t, err)
+		verify(sig)
+	})
+}
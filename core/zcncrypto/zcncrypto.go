@@ -6,18 +6,18 @@ import (
 
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/encryption"
-	"github.com/tyler-smith/go-bip39"
 )
 
 const CryptoVersion = "1.0"
 
-// KeyPair private and publickey
+// KeyPair private and publickey. The private key is redacted by fmt.
 type KeyPair struct {
 	PublicKey  string `json:"public_key"`
 	PrivateKey string `json:"private_key"`
 }
 
-// Wallet structure
+// Wallet structure. The private keys and the mnemonic are redacted by fmt,
+// Marshal and json.Marshal keep them.
 type Wallet struct {
 	ClientID    string    `json:"client_id"`
 	ClientKey   string    `json:"client_key"`
@@ -27,6 +27,22 @@ type Wallet struct {
 	DateCreated string    `json:"date_created"`
 }
 
+// Format redacts the private key.
+func (kp KeyPair) Format(f fmt.State, verb rune) {
+	type keyPair KeyPair
+	r := keyPair(kp)
+	r.PrivateKey = redactString(r.PrivateKey)
+	formatRedacted(f, verb, r)
+}
+
+// Format redacts the private keys and the mnemonic.
+func (w Wallet) Format(f fmt.State, verb rune) {
+	type wallet Wallet
+	r := wallet(w)
+	r.Mnemonic = redactString(r.Mnemonic)
+	formatRedacted(f, verb, r)
+}
+
 //SignatureScheme - an encryption scheme for signing and verifying messages
 type SignatureScheme interface {
 	// Generate fresh keys
@@ -54,6 +70,16 @@ type SplitSignatureScheme interface {
 	SplitKeys(numSplits int) (*Wallet, error)
 }
 
+// IsSignatureSchemeSupported reports whether NewSignatureScheme knows the
+// signature scheme.
+func IsSignatureSchemeSupported(sigScheme string) bool {
+	switch sigScheme {
+	case "ed25519", "bls0chain", "secp256k1":
+		return true
+	}
+	return false
+}
+
 // NewSignatureScheme creates an instance for using signature functions
 func NewSignatureScheme(sigScheme string) SignatureScheme {
 	switch sigScheme {
@@ -61,6 +87,8 @@ func NewSignatureScheme(sigScheme string) SignatureScheme {
 		return NewED255190chainScheme()
 	case "bls0chain":
 		return NewBLS0ChainScheme()
+	case "secp256k1":
+		return NewSecp256k1chainScheme()
 	default:
 		panic(fmt.Sprintf("unknown signature scheme: %v", sigScheme))
 	}
@@ -75,10 +103,6 @@ func (w *Wallet) Marshal() (string, error) {
 	return string(ws), nil
 }
 
-func IsMnemonicValid(mnemonic string) bool {
-	return bip39.IsMnemonicValid(mnemonic)
-}
-
 func Sha3Sum256(data string) string {
 	return encryption.Hash(data)
 }
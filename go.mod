@@ -1,8 +1,10 @@
 module github.com/0chain/gosdk
 
 require (
+	github.com/btcsuite/btcd v0.22.1
 	github.com/h2non/filetype v1.0.9
 	github.com/herumi/bls-go-binary v0.0.0-20191119080710-898950e1a520
+	github.com/klauspost/compress v1.11.13
 	github.com/klauspost/cpuid v1.2.0 // indirect
 	github.com/klauspost/reedsolomon v1.9.2
 	github.com/mitchellh/mapstructure v1.1.2
@@ -10,7 +12,8 @@ require (
 	github.com/tyler-smith/go-bip39 v1.0.0
 	go.dedis.ch/kyber/v3 v3.0.5
 	go.uber.org/zap v1.15.0
-	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
+	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
+	golang.org/x/text v0.3.6
 	golang.org/x/tools v0.0.0-20200117012304-6edc0a871e69 // indirect
 )
 
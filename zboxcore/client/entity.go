@@ -3,26 +3,85 @@ package client
 import (
 	"encoding/json"
 
+	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/zcncrypto"
 )
 
 type Client struct {
 	*zcncrypto.Wallet
 	signatureSchemeString string
+	signer                zcncrypto.Signer
 }
 
 var client *Client
 
+var errUnsupportedScheme = errors.New("invalid/unsupported signature scheme")
+
 func init() {
 	client = &Client{}
 }
 
 func PopulateClient(clientjson string, signatureScheme string) error {
+	if !zcncrypto.IsSignatureSchemeSupported(signatureScheme) {
+		return errUnsupportedScheme
+	}
 	err := json.Unmarshal([]byte(clientjson), &client)
 	client.signatureSchemeString = signatureScheme
+	client.signer = nil
 	return err
 }
 
+// PopulateEncryptedClient sets the client from a wallet json string, plain
+// or encrypted with the passphrase.
+func PopulateEncryptedClient(clientjson, passphrase string, signatureScheme string) error {
+	if !zcncrypto.IsSignatureSchemeSupported(signatureScheme) {
+		return errUnsupportedScheme
+	}
+	w, err := zcncrypto.LoadWallet(clientjson, passphrase)
+	if err != nil {
+		return err
+	}
+	client.Wallet = w
+	client.signatureSchemeString = signatureScheme
+	client.signer = nil
+	return nil
+}
+
+// PopulateClientWithSigner sets the client of the external signer, the
+// private key is not known to the SDK.
+func PopulateClientWithSigner(signer zcncrypto.Signer, signatureScheme string) error {
+	if !zcncrypto.IsSignatureSchemeSupported(signatureScheme) {
+		return errUnsupportedScheme
+	}
+	clientID, err := zcncrypto.ClientIDFromPublicKey(signer.PublicKey())
+	if err != nil {
+		return err
+	}
+	client.Wallet = &zcncrypto.Wallet{
+		ClientID:  clientID,
+		ClientKey: signer.PublicKey(),
+		Keys:      []zcncrypto.KeyPair{{PublicKey: signer.PublicKey()}},
+		Version:   zcncrypto.CryptoVersion,
+	}
+	client.signatureSchemeString = signatureScheme
+	client.signer = signer
+	return nil
+}
+
+// SetSigner routes the signing of the client through the signer, nil
+// signs with the wallet keys.
+func SetSigner(signer zcncrypto.Signer) {
+	client.signer = signer
+}
+
+// GetSigner returns the signer of the client.
+func GetSigner() zcncrypto.Signer {
+	if client.signer != nil {
+		return client.signer
+	}
+	return zcncrypto.NewWalletSigner(client.signatureSchemeString, client.Wallet)
+}
+
 func GetClient() *Client {
 	return client
 }
@@ -36,21 +95,7 @@ func GetClientPublicKey() string {
 }
 
 func Sign(hash string) (string, error) {
-	retSignature := ""
-	for _, kv := range client.Keys {
-		ss := zcncrypto.NewSignatureScheme(client.signatureSchemeString)
-		ss.SetPrivateKey(kv.PrivateKey)
-		var err error
-		if len(retSignature) == 0 {
-			retSignature, err = ss.Sign(hash)
-		} else {
-			retSignature, err = ss.Add(retSignature, hash)
-		}
-		if err != nil {
-			return "", err
-		}
-	}
-	return retSignature, nil
+	return GetSigner().Sign(hash)
 }
 
 func VerifySignature(signature string, msg string) (bool, error) {
@@ -58,3 +103,9 @@ func VerifySignature(signature string, msg string) (bool, error) {
 	ss.SetPublicKey(client.ClientKey)
 	return ss.Verify(signature, msg)
 }
+
+// BatchVerifySignatures verifies the signatures with the client signature
+// scheme, at once for bls0chain, see zcncrypto.BatchVerify.
+func BatchVerifySignatures(signed []zcncrypto.SignedHash) (bool, error) {
+	return zcncrypto.BatchVerify(client.signatureSchemeString, signed)
+}
@@ -0,0 +1,217 @@
+// Package compression compresses files before they are encrypted and
+// erasure coded. Files are compressed in frames of a fixed uncompressed
+// size, each compressed independently, so a range of the file is read
+// from the frames holding it only.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+
+	// DefaultFrameSize is the uncompressed size of the frames.
+	DefaultFrameSize = 1024 * 1024
+)
+
+// Meta describes the compression of a file, it is recorded in the file
+// metadata.
+type Meta struct {
+	Algorithm string `json:"algorithm"`
+	FrameSize int64  `json:"frame_size"`
+	// Size and Hash are the size and the sha1 hash of the uncompressed
+	// file.
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+	// Frames are the compressed sizes of the frames, uvarints in base64.
+	Frames string `json:"frames"`
+}
+
+// IsSupported returns whether the compression algorithm is known.
+func IsSupported(algorithm string) bool {
+	return algorithm == Gzip || algorithm == Zstd
+}
+
+// Compress compresses r to w in frames of the frame size.
+func Compress(algorithm string, r io.Reader, w io.Writer, frameSize int64) (*Meta, error) {
+	if !IsSupported(algorithm) {
+		return nil, errors.New("compression_error", "unknown compression algorithm "+algorithm)
+	}
+	if frameSize <= 0 {
+		return nil, errors.New("compression_error", "invalid frame size")
+	}
+	meta := &Meta{Algorithm: algorithm, FrameSize: frameSize}
+	var zenc *zstd.Encoder
+	if algorithm == Zstd {
+		var err error
+		if zenc, err = zstd.NewWriter(nil); err != nil {
+			return nil, err
+		}
+		defer zenc.Close()
+	}
+
+	h := sha1.New()
+	frame := make([]byte, frameSize)
+	var frames []byte
+	var varint [binary.MaxVarintLen64]byte
+	for {
+		n, err := io.ReadFull(r, frame)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, errors.Wrap(err, "reading the file")
+		}
+		h.Write(frame[:n])
+		meta.Size += int64(n)
+
+		var compressed []byte
+		if zenc != nil {
+			compressed = zenc.EncodeAll(frame[:n], nil)
+		} else {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			if _, err = gw.Write(frame[:n]); err != nil {
+				return nil, err
+			}
+			if err = gw.Close(); err != nil {
+				return nil, err
+			}
+			compressed = buf.Bytes()
+		}
+		if _, err = w.Write(compressed); err != nil {
+			return nil, errors.Wrap(err, "writing the compressed file")
+		}
+		frames = append(frames, varint[:binary.PutUvarint(varint[:], uint64(len(compressed)))]...)
+		if n < len(frame) {
+			break
+		}
+	}
+	meta.Hash = hex.EncodeToString(h.Sum(nil))
+	meta.Frames = base64.StdEncoding.EncodeToString(frames)
+	return meta, nil
+}
+
+// FrameSizes returns the compressed sizes of the frames.
+func (m *Meta) FrameSizes() ([]int64, error) {
+	b, err := base64.StdEncoding.DecodeString(m.Frames)
+	if err != nil {
+		return nil, errors.New("invalid_compression", "invalid frame sizes")
+	}
+	var sizes []int64
+	for len(b) > 0 {
+		size, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("invalid_compression", "invalid frame sizes")
+		}
+		sizes = append(sizes, int64(size))
+		b = b[n:]
+	}
+	if m.FrameSize <= 0 || int64(len(sizes)) != (m.Size+m.FrameSize-1)/m.FrameSize {
+		return nil, errors.New("invalid_compression", "frame sizes don't match the file size")
+	}
+	return sizes, nil
+}
+
+// CompressedSize returns the size of the compressed file.
+func (m *Meta) CompressedSize() (int64, error) {
+	sizes, err := m.FrameSizes()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, s := range sizes {
+		size += s
+	}
+	return size, nil
+}
+
+// Range returns the compressed range [start, end) of the frames holding
+// the uncompressed range [offset, offset+length).
+func (m *Meta) Range(offset, length int64) (start, end int64, err error) {
+	first, last, sizes, err := m.frames(offset, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := 0; i <= last; i++ {
+		if i < first {
+			start += sizes[i]
+		}
+		end += sizes[i]
+	}
+	return start, end, nil
+}
+
+func (m *Meta) frames(offset, length int64) (first, last int, sizes []int64, err error) {
+	if offset < 0 || length <= 0 || offset+length > m.Size {
+		return 0, 0, nil, errors.New("invalid_range", "range out of the file")
+	}
+	if sizes, err = m.FrameSizes(); err != nil {
+		return 0, 0, nil, err
+	}
+	return int(offset / m.FrameSize), int((offset + length - 1) / m.FrameSize), sizes, nil
+}
+
+// Decompress writes the uncompressed range [offset, offset+length) to w,
+// r reads the compressed file from the start Range returns.
+func Decompress(m *Meta, r io.Reader, w io.Writer, offset, length int64) error {
+	first, last, sizes, err := m.frames(offset, length)
+	if err != nil {
+		return err
+	}
+	var zdec *zstd.Decoder
+	if m.Algorithm == Zstd {
+		if zdec, err = zstd.NewReader(nil); err != nil {
+			return err
+		}
+		defer zdec.Close()
+	} else if m.Algorithm != Gzip {
+		return errors.New("compression_error", "unknown compression algorithm "+m.Algorithm)
+	}
+
+	for i := first; i <= last; i++ {
+		compressed := make([]byte, sizes[i])
+		if _, err = io.ReadFull(r, compressed); err != nil {
+			return errors.Wrap(err, "reading the compressed file")
+		}
+		var frame []byte
+		if zdec != nil {
+			frame, err = zdec.DecodeAll(compressed, nil)
+		} else {
+			var gr *gzip.Reader
+			if gr, err = gzip.NewReader(bytes.NewReader(compressed)); err == nil {
+				frame, err = ioutil.ReadAll(gr)
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, "decompressing the file")
+		}
+		frameStart := int64(i) * m.FrameSize
+		if int64(len(frame)) != m.FrameSize && frameStart+int64(len(frame)) != m.Size {
+			return errors.New("invalid_compression", "frame of invalid size")
+		}
+		from, to := int64(0), int64(len(frame))
+		if offset > frameStart {
+			from = offset - frameStart
+		}
+		if offset+length < frameStart+to {
+			to = offset + length - frameStart
+		}
+		if _, err = w.Write(frame[from:to]); err != nil {
+			return errors.Wrap(err, "writing the file")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressRange(t *testing.T) {
+	var data []byte
+	for i := 0; len(data) < 5000; i++ {
+		data = append(data, fmt.Sprintf(`{"line":%d,"level":"info"}`, i)...)
+	}
+	hash := sha1.Sum(data)
+
+	for _, algorithm := range []string{Gzip, Zstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			var compressed bytes.Buffer
+			meta, err := Compress(algorithm, bytes.NewReader(data), &compressed, 1000)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(data)), meta.Size)
+			require.Equal(t, hex.EncodeToString(hash[:]), meta.Hash)
+			size, err := meta.CompressedSize()
+			require.NoError(t, err)
+			require.Equal(t, int64(compressed.Len()), size)
+			require.Less(t, size, meta.Size)
+
+			for _, r := range [][2]int64{{0, meta.Size}, {0, 1}, {999, 2}, {2500, 1700}, {meta.Size - 1, 1}} {
+				start, end, err := meta.Range(r[0], r[1])
+				require.NoError(t, err)
+				var out bytes.Buffer
+				err = Decompress(meta, bytes.NewReader(compressed.Bytes()[start:end]), &out, r[0], r[1])
+				require.NoError(t, err)
+				require.Equal(t, data[r[0]:r[0]+r[1]], out.Bytes(), "%v", r)
+			}
+
+			_, _, err = meta.Range(meta.Size-1, 2)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCompressUnknown(t *testing.T) {
+	_, err := Compress("lz4", bytes.NewReader(nil), &bytes.Buffer{}, DefaultFrameSize)
+	require.Error(t, err)
+}
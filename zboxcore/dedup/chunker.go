@@ -0,0 +1,76 @@
+// Package dedup splits files into content-defined chunks, stored once per
+// allocation and referenced by the files holding them.
+package dedup
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	MinChunkSize = 256 * 1024
+	AvgChunkSize = 1024 * 1024
+	MaxChunkSize = 4 * 1024 * 1024
+)
+
+// gear is the table of the rolling hash, it never changes, chunks of
+// different uploads must have the same boundaries.
+var gear [256]uint64
+
+func init() {
+	for i := range gear {
+		h := sha256.Sum256([]byte{'z', 'b', 'o', 'x', 'g', 'e', 'a', 'r', byte(i)})
+		gear[i] = binary.BigEndian.Uint64(h[:8])
+	}
+}
+
+// Chunker splits a stream into chunks at content-defined boundaries, an
+// insertion in the stream only changes the chunks around it.
+type Chunker struct {
+	r        *bufio.Reader
+	min, max int
+	bits     uint
+}
+
+// NewChunker returns a chunker of chunks of the default sizes.
+func NewChunker(r io.Reader) *Chunker {
+	return newChunker(r, MinChunkSize, AvgChunkSize, MaxChunkSize)
+}
+
+// newChunker returns a chunker, the average size is a power of 2.
+func newChunker(r io.Reader, min, avg, max int) *Chunker {
+	var bits uint
+	for 1<<bits < avg {
+		bits++
+	}
+	return &Chunker{r: bufio.NewReader(r), min: min, max: max, bits: bits}
+}
+
+// Next returns the next chunk, io.EOF after the last one.
+func (c *Chunker) Next() ([]byte, error) {
+	var (
+		chunk []byte
+		h     uint64
+	)
+	for len(chunk) < c.max {
+		b, err := c.r.ReadByte()
+		if err == io.EOF {
+			if len(chunk) == 0 {
+				return nil, io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunk = append(chunk, b)
+		h = h<<1 + gear[b]
+		// the high bits depend on the last 64 bytes
+		if len(chunk) >= c.min && h>>(64-c.bits) == 0 {
+			break
+		}
+	}
+	return chunk, nil
+}
@@ -0,0 +1,53 @@
+package dedup
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func chunkHashes(t *testing.T, data []byte) []string {
+	c := newChunker(bytes.NewReader(data), 1024, 4096, 16384)
+	var (
+		hashes []string
+		joined []byte
+	)
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.True(t, len(chunk) <= 16384)
+		hashes = append(hashes, ChunkHash(chunk))
+		joined = append(joined, chunk...)
+	}
+	require.Equal(t, data, joined)
+	return hashes
+}
+
+func TestChunkerContentDefined(t *testing.T) {
+	data := make([]byte, 200*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	hashes := chunkHashes(t, data)
+	require.True(t, len(hashes) > 10)
+
+	// an insertion at the start only changes the first chunks
+	shifted := chunkHashes(t, append([]byte("inserted"), data...))
+	common := make(map[string]bool)
+	for _, h := range hashes {
+		common[h] = true
+	}
+	var same int
+	for _, h := range shifted {
+		if common[h] {
+			same++
+		}
+	}
+	require.True(t, same >= len(hashes)-2, "%d of %d chunks kept", same, len(hashes))
+
+	require.Empty(t, chunkHashes(t, nil))
+}
@@ -0,0 +1,144 @@
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+const (
+	// Dir is the allocation directory of the chunks and of the manifest.
+	Dir = "/.dedup"
+	// ManifestPath is the path of the allocation manifest.
+	ManifestPath = Dir + "/manifest"
+	// LockPath is the path of the lock of the client changing the manifest.
+	LockPath = Dir + "/lock"
+	// ChunksDir is the allocation directory of the chunks.
+	ChunksDir = Dir + "/chunks"
+)
+
+// Meta is the metadata of a deduplicated file, whose content is its
+// recipe. Size and Hash are the size and sha1 hash of the file.
+type Meta struct {
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+}
+
+// Chunk is a chunk of a deduplicated file.
+type Chunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// ChunkHash returns the hash chunks are stored by.
+func ChunkHash(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// ChunkPath returns the allocation path of the chunk.
+func ChunkPath(hash string) string {
+	return ChunksDir + "/" + hash
+}
+
+// Recipe lists the chunks of a deduplicated file, in order.
+type Recipe struct {
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Range returns the chunks holding the bytes [offset, offset+length) and
+// the offset of the first one in the file.
+func (r *Recipe) Range(offset, length int64) ([]Chunk, int64) {
+	var (
+		chunks []Chunk
+		first  int64
+		pos    int64
+	)
+	for _, c := range r.Chunks {
+		if pos+c.Size > offset && pos < offset+length {
+			if len(chunks) == 0 {
+				first = pos
+			}
+			chunks = append(chunks, c)
+		}
+		pos += c.Size
+	}
+	return chunks, first
+}
+
+// ChunkRef is a chunk of the manifest, Refs is the number of files
+// referencing it.
+type ChunkRef struct {
+	Size int64 `json:"size"`
+	Refs int   `json:"refs"`
+}
+
+// Manifest tracks the chunks stored in the allocation.
+type Manifest struct {
+	Chunks map[string]*ChunkRef `json:"chunks"`
+}
+
+// NewManifest returns the manifest of an allocation without chunks.
+func NewManifest() *Manifest {
+	return &Manifest{Chunks: make(map[string]*ChunkRef)}
+}
+
+// Has returns whether the chunk is stored.
+func (m *Manifest) Has(hash string) bool {
+	_, ok := m.Chunks[hash]
+	return ok
+}
+
+// AddRefs references the chunks of the recipe, once per file.
+func (m *Manifest) AddRefs(r *Recipe) {
+	for _, c := range r.unique() {
+		ref, ok := m.Chunks[c.Hash]
+		if !ok {
+			ref = &ChunkRef{Size: c.Size}
+			m.Chunks[c.Hash] = ref
+		}
+		ref.Refs++
+	}
+}
+
+// RemoveRefs dereferences the chunks of the recipe. It returns the chunks
+// no longer referenced, removed from the manifest.
+func (m *Manifest) RemoveRefs(r *Recipe) []string {
+	var unreferenced []string
+	for _, c := range r.unique() {
+		ref, ok := m.Chunks[c.Hash]
+		if !ok {
+			continue
+		}
+		if ref.Refs--; ref.Refs <= 0 {
+			delete(m.Chunks, c.Hash)
+			unreferenced = append(unreferenced, c.Hash)
+		}
+	}
+	sort.Strings(unreferenced)
+	return unreferenced
+}
+
+// Unreferenced returns the chunks stored which are not in the manifest.
+func (m *Manifest) Unreferenced(stored []string) []string {
+	var unreferenced []string
+	for _, hash := range stored {
+		if !m.Has(hash) {
+			unreferenced = append(unreferenced, hash)
+		}
+	}
+	sort.Strings(unreferenced)
+	return unreferenced
+}
+
+func (r *Recipe) unique() []Chunk {
+	seen := make(map[string]bool)
+	var chunks []Chunk
+	for _, c := range r.Chunks {
+		if !seen[c.Hash] {
+			seen[c.Hash] = true
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,41 @@
+package dedup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestRefs(t *testing.T) {
+	a := &Recipe{Chunks: []Chunk{{"1", 10}, {"2", 20}, {"1", 10}}}
+	b := &Recipe{Chunks: []Chunk{{"2", 20}, {"3", 30}}}
+	m := NewManifest()
+	m.AddRefs(a)
+	m.AddRefs(b)
+	require.Equal(t, 1, m.Chunks["1"].Refs)
+	require.Equal(t, 2, m.Chunks["2"].Refs)
+
+	require.Equal(t, []string{"1"}, m.RemoveRefs(a))
+	require.False(t, m.Has("1"))
+	require.True(t, m.Has("2"))
+	require.Equal(t, []string{"2", "3"}, m.RemoveRefs(b))
+	require.Empty(t, m.Chunks)
+	require.Empty(t, m.RemoveRefs(b))
+}
+
+func TestManifestUnreferenced(t *testing.T) {
+	m := NewManifest()
+	m.AddRefs(&Recipe{Chunks: []Chunk{{"1", 10}, {"2", 20}}})
+	require.Equal(t, []string{"0", "3"}, m.Unreferenced([]string{"3", "2", "1", "0"}))
+	require.Empty(t, m.Unreferenced([]string{"1"}))
+}
+
+func TestRecipeRange(t *testing.T) {
+	r := &Recipe{Chunks: []Chunk{{"1", 10}, {"2", 20}, {"3", 30}}}
+	chunks, first := r.Range(15, 20)
+	require.Equal(t, []Chunk{{"2", 20}, {"3", 30}}, chunks)
+	require.Equal(t, int64(10), first)
+	chunks, first = r.Range(0, 10)
+	require.Equal(t, []Chunk{{"1", 10}}, chunks)
+	require.Equal(t, int64(0), first)
+}
@@ -0,0 +1,184 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+const (
+	// LegacyChunkVersion chunks have a 2 KB header with the hex checksums,
+	// comma separated and NUL padded.
+	LegacyChunkVersion = 0
+	// ChunkVersion chunks have a fixed binary header, their position in the
+	// file is bound to the ciphertext.
+	ChunkVersion = 2
+
+	legacyChunkHeaderSize = 2 * 1024
+	// ChunkHeaderSize is the magic, the version and the raw checksums.
+	ChunkHeaderSize = 4 + 2*sha512.Size
+	// gcmTagSize is the AES-GCM authentication tag of the encrypted data.
+	gcmTagSize = 16
+)
+
+var chunkMagic = []byte("ZCE")
+
+// ChunkPosition is the position of an encrypted chunk in the file, the
+// chunks can't be reordered, swapped between shards or with the thumbnail,
+// or truncated.
+type ChunkPosition struct {
+	Shard     int
+	Index     int64
+	Final     bool
+	Thumbnail bool
+}
+
+// associatedDataScheme encrypts with associated data, the chunk position.
+type associatedDataScheme interface {
+	encryptWithAD(data []byte, ad []byte) (*EncryptedMessage, error)
+	decryptWithAD(encMsg *EncryptedMessage, ad []byte) ([]byte, error)
+}
+
+// fileKeyScheme encrypts with the key of an encrypted file.
+type fileKeyScheme interface {
+	initForFileKey(tag, encryptedKey string) error
+}
+
+// InitForFileKey inits the scheme of the owner of the file to encrypt with
+// its key: a chunk encrypted again at its position is the one stored, so
+// that a lost shard is rebuilt as it was and the shares of the file still
+// decrypt it.
+func InitForFileKey(scheme EncryptionScheme, encryptedKey string) error {
+	fks, ok := scheme.(fileKeyScheme)
+	if !ok {
+		return errors.New("encryption_error", "encryption scheme can't encrypt with the key of a file")
+	}
+	return fks.initForFileKey(FileTag(encryptedKey), encryptedKey)
+}
+
+// SchemeChunkVersion returns the chunk version new files are encrypted in
+// with the scheme.
+func SchemeChunkVersion(scheme EncryptionScheme) int {
+	if _, ok := scheme.(associatedDataScheme); ok {
+		return ChunkVersion
+	}
+	return LegacyChunkVersion
+}
+
+// FileChunkVersion returns the chunk version of the file, recorded in its
+// encrypted key.
+func FileChunkVersion(encryptedKey string) int {
+	_, _, _, version := splitEncryptedKey(encryptedKey)
+	if v, err := strconv.Atoi(version); err == nil {
+		return v
+	}
+	return LegacyChunkVersion
+}
+
+// ChunkOverhead returns the bytes encryption adds to a chunk.
+func ChunkOverhead(version int) int64 {
+	if version == ChunkVersion {
+		return ChunkHeaderSize + gcmTagSize
+	}
+	return legacyChunkHeaderSize + gcmTagSize
+}
+
+func (pos ChunkPosition) associatedData() []byte {
+	ad := make([]byte, 0, ChunkHeaderSize)
+	ad = append(ad, chunkMagic...)
+	ad = append(ad, ChunkVersion)
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(pos.Shard))
+	ad = append(ad, b[:4]...)
+	binary.BigEndian.PutUint64(b[:], uint64(pos.Index))
+	ad = append(ad, b[:]...)
+	var flags byte
+	if pos.Final {
+		flags |= 1
+	}
+	if pos.Thumbnail {
+		flags |= 2
+	}
+	return append(ad, flags)
+}
+
+// EncryptChunk encrypts the chunk in the format of the version.
+func EncryptChunk(scheme EncryptionScheme, version int, data []byte, pos ChunkPosition) ([]byte, error) {
+	if version != ChunkVersion {
+		encMsg, err := scheme.Encrypt(data)
+		if err != nil {
+			return nil, err
+		}
+		header := make([]byte, legacyChunkHeaderSize)
+		copy(header[:], encMsg.MessageChecksum+","+encMsg.OverallChecksum)
+		return append(header, encMsg.EncryptedData...), nil
+	}
+	ads, ok := scheme.(associatedDataScheme)
+	if !ok {
+		return nil, errors.New("chunk_version_error", "encryption scheme without chunk version 2")
+	}
+	encMsg, err := ads.encryptWithAD(data, pos.associatedData())
+	if err != nil {
+		return nil, err
+	}
+	chunk := make([]byte, ChunkHeaderSize, ChunkHeaderSize+len(encMsg.EncryptedData))
+	copy(chunk, chunkMagic)
+	chunk[len(chunkMagic)] = ChunkVersion
+	if err = decodeChecksum(chunk[4:4+sha512.Size], encMsg.MessageChecksum); err != nil {
+		return nil, err
+	}
+	if err = decodeChecksum(chunk[4+sha512.Size:ChunkHeaderSize], encMsg.OverallChecksum); err != nil {
+		return nil, err
+	}
+	return append(chunk, encMsg.EncryptedData...), nil
+}
+
+func decodeChecksum(dst []byte, checksum string) error {
+	b, err := hex.DecodeString(checksum)
+	if err != nil || len(b) != len(dst) {
+		return errors.New("chunk_checksum_error", "invalid checksum")
+	}
+	copy(dst, b)
+	return nil
+}
+
+// DecryptChunk decrypts the chunk in the format of the version. The
+// re-encryption key is the one of the auth ticket the file is shared with.
+func DecryptChunk(scheme EncryptionScheme, version int, chunk []byte, pos ChunkPosition, reEncryptionKey string) ([]byte, error) {
+	encMsg := &EncryptedMessage{
+		EncryptedKey:    scheme.GetEncryptedKey(),
+		ReEncryptionKey: reEncryptionKey,
+	}
+	if version != ChunkVersion {
+		if len(chunk) < legacyChunkHeaderSize {
+			return nil, errors.New("invalid_chunk", "chunk shorter than its header")
+		}
+		header := string(bytes.Trim(chunk[:legacyChunkHeaderSize], "\x00"))
+		checksums := strings.Split(header, ",")
+		if len(checksums) != 2 {
+			return nil, errors.New("invalid_chunk", "chunk has invalid header")
+		}
+		encMsg.MessageChecksum, encMsg.OverallChecksum = checksums[0], checksums[1]
+		encMsg.EncryptedData = chunk[legacyChunkHeaderSize:]
+		return scheme.Decrypt(encMsg)
+	}
+	ads, ok := scheme.(associatedDataScheme)
+	if !ok {
+		return nil, errors.New("chunk_version_error", "encryption scheme without chunk version 2")
+	}
+	if len(chunk) < ChunkHeaderSize || !bytes.Equal(chunk[:len(chunkMagic)], chunkMagic) {
+		return nil, errors.New("invalid_chunk", "chunk has invalid header")
+	}
+	if chunk[len(chunkMagic)] != ChunkVersion {
+		return nil, errors.New("invalid_chunk", "unknown chunk version")
+	}
+	encMsg.MessageChecksum = hex.EncodeToString(chunk[4 : 4+sha512.Size])
+	encMsg.OverallChecksum = hex.EncodeToString(chunk[4+sha512.Size : ChunkHeaderSize])
+	encMsg.EncryptedData = chunk[ChunkHeaderSize:]
+	return ads.decryptWithAD(encMsg, pos.associatedData())
+}
@@ -0,0 +1,121 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("chunk test data "), 1024)
+	owner := NewEncryptionScheme()
+	require.NoError(t, owner.Initialize(testMnemonic))
+	owner.InitForEncryption(LegacyTag)
+	require.Equal(t, ChunkVersion, SchemeChunkVersion(owner))
+
+	for _, version := range []int{LegacyChunkVersion, ChunkVersion} {
+		pos := ChunkPosition{Shard: 1, Index: 3, Final: true}
+		chunk, err := EncryptChunk(owner, version, data, pos)
+		require.NoError(t, err)
+		require.Len(t, chunk, len(data)+int(ChunkOverhead(version)))
+
+		reader := NewEncryptionScheme()
+		require.NoError(t, reader.Initialize(testMnemonic))
+		require.NoError(t, reader.InitForDecryption(LegacyTag, owner.GetEncryptedKey()))
+		got, err := DecryptChunk(reader, version, chunk, pos, "")
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	}
+}
+
+func TestInitForFileKey(t *testing.T) {
+	data := bytes.Repeat([]byte("chunk test data "), 1024)
+	tag, err := NewFileTag()
+	require.NoError(t, err)
+	owner := NewEncryptionScheme()
+	require.NoError(t, owner.Initialize(testMnemonic))
+	owner.InitForEncryption(tag)
+	encryptedKey := FormatEncryptedKey("", owner.GetEncryptedKey(), tag, ChunkVersion)
+
+	for _, version := range []int{LegacyChunkVersion, ChunkVersion} {
+		pos := ChunkPosition{Shard: 2, Index: 1}
+		chunk, err := EncryptChunk(owner, version, data, pos)
+		require.NoError(t, err)
+
+		rebuild := NewEncryptionScheme()
+		require.NoError(t, rebuild.Initialize(testMnemonic))
+		require.NoError(t, InitForFileKey(rebuild, encryptedKey))
+		require.Equal(t, owner.GetEncryptedKey(), rebuild.GetEncryptedKey())
+		rebuilt, err := EncryptChunk(rebuild, version, data, pos)
+		require.NoError(t, err)
+		require.Equal(t, chunk, rebuilt)
+	}
+}
+
+func TestChunkPositionBound(t *testing.T) {
+	data := []byte("chunk test data")
+	owner := NewEncryptionScheme()
+	require.NoError(t, owner.Initialize(testMnemonic))
+	owner.InitForEncryption(LegacyTag)
+	pos := ChunkPosition{Shard: 1, Index: 3}
+	chunk, err := EncryptChunk(owner, ChunkVersion, data, pos)
+	require.NoError(t, err)
+
+	for _, moved := range []ChunkPosition{
+		{Shard: 1, Index: 4},
+		{Shard: 2, Index: 3},
+		{Shard: 1, Index: 3, Final: true},
+		{Shard: 1, Index: 3, Thumbnail: true},
+	} {
+		_, err = DecryptChunk(owner, ChunkVersion, chunk, moved, "")
+		require.Error(t, err, "%+v", moved)
+	}
+	_, err = DecryptChunk(owner, ChunkVersion, chunk[:ChunkHeaderSize-1], pos, "")
+	require.Error(t, err)
+	_, err = DecryptChunk(owner, LegacyChunkVersion, chunk, pos, "")
+	require.Error(t, err)
+}
+
+func TestChunkReEncryption(t *testing.T) {
+	data := []byte("chunk test data")
+	owner := NewEncryptionScheme()
+	require.NoError(t, owner.Initialize(testMnemonic))
+	owner.InitForEncryption(LegacyTag)
+	pos := ChunkPosition{Index: 7, Final: true}
+	chunk, err := EncryptChunk(owner, ChunkVersion, data, pos)
+	require.NoError(t, err)
+
+	kr, err := NewKeyring()
+	require.NoError(t, err)
+	reader, err := kr.Current().Scheme()
+	require.NoError(t, err)
+	readerKey, err := reader.GetPublicKey()
+	require.NoError(t, err)
+	reKey, err := owner.GetReGenKey(readerKey, LegacyTag)
+	require.NoError(t, err)
+
+	require.NoError(t, reader.InitForDecryption(LegacyTag, owner.GetEncryptedKey()))
+	got, err := DecryptChunk(reader, ChunkVersion, chunk, pos, reKey)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	_, err = DecryptChunk(reader, ChunkVersion, chunk, ChunkPosition{Index: 6}, reKey)
+	require.Error(t, err)
+}
+
+func TestFileChunkVersion(t *testing.T) {
+	for _, tag := range []string{"", LegacyTag, "file:abc"} {
+		for _, version := range []int{LegacyChunkVersion, ChunkVersion} {
+			encryptedKey := FormatEncryptedKey("id", "key", tag, version)
+			require.Equal(t, version, FileChunkVersion(encryptedKey))
+			require.Equal(t, FileTag(FormatEncryptedKey("", "", tag, LegacyChunkVersion)), FileTag(encryptedKey))
+			keyID, key, _ := ParseEncryptedKey(encryptedKey)
+			require.Equal(t, "id", keyID)
+			require.Equal(t, "key", key)
+		}
+	}
+	require.Equal(t, LegacyChunkVersion, FileChunkVersion("key"))
+	require.Error(t, ValidateTag("a;b"))
+	require.NoError(t, ValidateTag(DirectoryTag("hash")))
+}
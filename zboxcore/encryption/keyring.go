@@ -0,0 +1,235 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+// keyIDSeparator separates the key ID from the encrypted key of a file. It
+// is not in the base64 alphabet.
+const keyIDSeparator = ":"
+
+// fieldSeparator separates the tag and the chunk version from the
+// encrypted key of a file. It is neither in the base64 alphabet nor in
+// tags.
+const fieldSeparator = ";"
+
+// EncryptionKey is a private key of a keyring. Its ID is derived from its
+// public key.
+type EncryptionKey struct {
+	ID         string `json:"id"`
+	PrivateKey string `json:"private_key"`
+	// Mnemonic keys are derived from the wallet mnemonic, they decrypt the
+	// files uploaded before keyrings, which have no key ID.
+	Mnemonic  bool  `json:"mnemonic,omitempty"`
+	CreatedAt int64 `json:"created_at"`
+}
+
+// Keyring holds the proxy re-encryption keys of a client, independent from
+// its wallet keys and mnemonic. The last key encrypts new files, every key
+// decrypts the files it encrypted.
+type Keyring struct {
+	Keys []*EncryptionKey `json:"keys"`
+	// NameKey is the hex key file and directory names are encrypted with,
+	// it is kept when the keyring rotates.
+	NameKey string `json:"name_key,omitempty"`
+}
+
+// NewKeyring returns a keyring with a new random key.
+func NewKeyring() (*Keyring, error) {
+	nameKey := make([]byte, 32)
+	if _, err := rand.Read(nameKey); err != nil {
+		return nil, err
+	}
+	kr := &Keyring{NameKey: hex.EncodeToString(nameKey)}
+	if _, err := kr.Rotate(); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+// UnmarshalKeyring decodes an exported keyring.
+func UnmarshalKeyring(data string) (*Keyring, error) {
+	var kr Keyring
+	if err := json.Unmarshal([]byte(data), &kr); err != nil {
+		return nil, errors.Wrap(err, "decoding keyring")
+	}
+	if len(kr.Keys) == 0 {
+		return nil, errors.New("invalid_keyring", "keyring without keys")
+	}
+	return &kr, nil
+}
+
+// Marshal exports the keyring, the private keys included.
+func (kr *Keyring) Marshal() (string, error) {
+	b, err := json.Marshal(kr)
+	if err != nil {
+		return "", errors.New("keyring_marshal", "Invalid keyring")
+	}
+	return string(b), nil
+}
+
+// Rotate adds a new random key, new files are encrypted with it. The
+// previous keys are kept to decrypt the files they encrypted. It returns
+// the ID of the new key.
+func (kr *Keyring) Rotate() (string, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	key, err := newEncryptionKey(suite.Scalar().Pick(suite.RandomStream()))
+	if err != nil {
+		return "", err
+	}
+	kr.Keys = append(kr.Keys, key)
+	return key.ID, nil
+}
+
+// ImportMnemonic adds the key derived from the wallet mnemonic, the key
+// files were encrypted with before keyrings. It only encrypts new files if
+// the keyring has no other key.
+func (kr *Keyring) ImportMnemonic(mnemonic string) error {
+	if mnemonic == "" {
+		return errors.New("invalid_mnemonic", "empty mnemonic")
+	}
+	pre := &PREEncryptionScheme{}
+	pre.Initialize(mnemonic)
+	key, err := newEncryptionKey(pre.PrivateKey)
+	if err != nil {
+		return err
+	}
+	key.Mnemonic = true
+	if k := kr.mnemonicKey(); k != nil {
+		*k = *key
+		return nil
+	}
+	kr.Keys = append([]*EncryptionKey{key}, kr.Keys...)
+	return nil
+}
+
+// Current returns the key new files are encrypted with.
+func (kr *Keyring) Current() *EncryptionKey {
+	if len(kr.Keys) == 0 {
+		return nil
+	}
+	return kr.Keys[len(kr.Keys)-1]
+}
+
+// Key returns the key of the ID. The empty ID is the mnemonic key.
+func (kr *Keyring) Key(id string) (*EncryptionKey, error) {
+	if id == "" {
+		if k := kr.mnemonicKey(); k != nil {
+			return k, nil
+		}
+		return nil, errors.New("encryption_key_not_found", "keyring without mnemonic key")
+	}
+	for _, k := range kr.Keys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return nil, errors.New("encryption_key_not_found", "no encryption key "+id)
+}
+
+func (kr *Keyring) mnemonicKey() *EncryptionKey {
+	for _, k := range kr.Keys {
+		if k.Mnemonic {
+			return k
+		}
+	}
+	return nil
+}
+
+// Scheme returns the encryption scheme of the key.
+func (k *EncryptionKey) Scheme() (EncryptionScheme, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	b, err := base64.StdEncoding.DecodeString(k.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+	sk := suite.Scalar()
+	if err = sk.UnmarshalBinary(b); err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+	pre := &PREEncryptionScheme{}
+	pre.initializeWithKey(suite, sk)
+	return pre, nil
+}
+
+func newEncryptionKey(sk kyber.Scalar) (*EncryptionKey, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	private, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	public, err := suite.Point().Mul(sk, nil).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptionKey{
+		ID:         keyID(public),
+		PrivateKey: base64.StdEncoding.EncodeToString(private),
+		CreatedAt:  time.Now().Unix(),
+	}, nil
+}
+
+func keyID(public []byte) string {
+	return encryption.Hash(public)[:16]
+}
+
+// KeyIDOf returns the ID of the key of the encryption public key, the
+// recipient of a share records it to pick the key to decrypt with.
+func KeyIDOf(encPublicKey string) (string, error) {
+	public, err := base64.StdEncoding.DecodeString(encPublicKey)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid encryption public key")
+	}
+	return keyID(public), nil
+}
+
+// FormatEncryptedKey records the key ID, the proxy re-encryption tag and
+// the chunk version in the encrypted key of a file, blobbers store it as
+// is. An empty ID or tag and the legacy chunk version are left out.
+func FormatEncryptedKey(keyID, encryptedKey, tag string, version int) string {
+	if keyID != "" {
+		encryptedKey = keyID + keyIDSeparator + encryptedKey
+	}
+	if tag != "" || version != LegacyChunkVersion {
+		encryptedKey += fieldSeparator + tag
+	}
+	if version != LegacyChunkVersion {
+		encryptedKey += fieldSeparator + strconv.Itoa(version)
+	}
+	return encryptedKey
+}
+
+// ParseEncryptedKey splits the encrypted key of a file into its key ID, the
+// encrypted key and its tag. Files encrypted before keyrings have no key
+// ID, they were encrypted with the mnemonic key. Files encrypted before
+// per-file tags have no tag, see FileTag.
+func ParseEncryptedKey(s string) (keyID, encryptedKey, tag string) {
+	keyID, encryptedKey, tag, _ = splitEncryptedKey(s)
+	return keyID, encryptedKey, tag
+}
+
+func splitEncryptedKey(s string) (keyID, encryptedKey, tag, version string) {
+	fields := strings.SplitN(s, fieldSeparator, 3)
+	s = fields[0]
+	if len(fields) > 1 {
+		tag = fields[1]
+	}
+	if len(fields) > 2 {
+		version = fields[2]
+	}
+	if i := strings.Index(s, keyIDSeparator); i >= 0 {
+		return s[:i], s[i+1:], tag, version
+	}
+	return "", s, tag, version
+}
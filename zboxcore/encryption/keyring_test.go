@@ -0,0 +1,81 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "travel twenty hen negative fresh sentence hen flat swift embody increase juice eternal satisfy want vivid power transfer"
+
+func encryptWith(t *testing.T, encscheme EncryptionScheme, keyID string, data []byte) (*EncryptedMessage, string) {
+	encscheme.InitForEncryption("filetype:audio")
+	msg, err := encscheme.Encrypt(data)
+	require.NoError(t, err)
+	return msg, FormatEncryptedKey(keyID, encscheme.GetEncryptedKey(), "", LegacyChunkVersion)
+}
+
+func decryptWith(t *testing.T, kr *Keyring, msg *EncryptedMessage, encryptedKey string) []byte {
+	keyID, _, _ := ParseEncryptedKey(encryptedKey)
+	key, err := kr.Key(keyID)
+	require.NoError(t, err)
+	encscheme, err := key.Scheme()
+	require.NoError(t, err)
+	require.NoError(t, encscheme.InitForDecryption("filetype:audio", encryptedKey))
+	msg.EncryptedKey = encscheme.GetEncryptedKey()
+	data, err := encscheme.Decrypt(msg)
+	require.NoError(t, err)
+	return data
+}
+
+func TestKeyringRotation(t *testing.T) {
+	data := []byte("keyring test data")
+	kr, err := NewKeyring()
+	require.NoError(t, err)
+	first := kr.Current()
+	encscheme, err := first.Scheme()
+	require.NoError(t, err)
+	msg, encKey := encryptWith(t, encscheme, first.ID, data)
+
+	id, err := kr.Rotate()
+	require.NoError(t, err)
+	require.NotEqual(t, first.ID, id)
+	require.Equal(t, id, kr.Current().ID)
+
+	// exported and imported, the old key still decrypts
+	s, err := kr.Marshal()
+	require.NoError(t, err)
+	kr, err = UnmarshalKeyring(s)
+	require.NoError(t, err)
+	require.Equal(t, data, decryptWith(t, kr, msg, encKey))
+
+	_, err = kr.Key("unknown")
+	require.Error(t, err)
+}
+
+func TestKeyringMnemonicKey(t *testing.T) {
+	data := []byte("keyring test data")
+	legacy := NewEncryptionScheme()
+	require.NoError(t, legacy.Initialize(testMnemonic))
+	msg, encKey := encryptWith(t, legacy, "", data)
+	keyID, _, _ := ParseEncryptedKey(encKey)
+	require.Empty(t, keyID)
+
+	kr, err := NewKeyring()
+	require.NoError(t, err)
+	_, err = kr.Key("")
+	require.Error(t, err)
+	require.NoError(t, kr.ImportMnemonic(testMnemonic))
+	require.False(t, kr.Current().Mnemonic, "the mnemonic key must not encrypt new files")
+	require.Equal(t, data, decryptWith(t, kr, msg, encKey))
+
+	key, err := kr.Key("")
+	require.NoError(t, err)
+	encscheme, err := key.Scheme()
+	require.NoError(t, err)
+	pub, err := encscheme.GetPublicKey()
+	require.NoError(t, err)
+	legacyPub, err := legacy.GetPublicKey()
+	require.NoError(t, err)
+	require.Equal(t, legacyPub, pub)
+}
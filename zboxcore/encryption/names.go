@@ -0,0 +1,233 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+// NameCipher encrypts the names of a directory and of its subdirectories.
+// Each directory has its own key, derived from the key of its parent and
+// its name, so the same name encrypts differently in different
+// directories. Names are encrypted deterministically, the encrypted path of
+// a path is always the same and blobbers can look it up.
+type NameCipher struct {
+	key     []byte
+	metaKey []byte
+}
+
+// NewNameCipher returns the cipher of the root directory of the key.
+func NewNameCipher(rootKey []byte) *NameCipher {
+	return &NameCipher{
+		key:     hmacSum(rootKey, "dir:/"),
+		metaKey: hmacSum(rootKey, "meta"),
+	}
+}
+
+// ImportNameCipher decodes a cipher exported with Export or ExportMeta.
+func ImportNameCipher(s string) (*NameCipher, error) {
+	b, err := hex.DecodeString(s)
+	switch {
+	case err == nil && len(b) == 2*sha256.Size:
+		return &NameCipher{key: b[:sha256.Size], metaKey: b[sha256.Size:]}, nil
+	case err == nil && len(b) == sha256.Size:
+		return &NameCipher{metaKey: b}, nil
+	}
+	return nil, errors.New("invalid_name_key", "invalid name encryption key")
+}
+
+// Export encodes the cipher, it decrypts the names of the directory and of
+// its subdirectories, not of its parents.
+func (c *NameCipher) Export() string {
+	return hex.EncodeToString(append(append([]byte{}, c.key...), c.metaKey...))
+}
+
+// ExportMeta encodes the cipher of the metadata only, it decrypts no name.
+func (c *NameCipher) ExportMeta() string {
+	return hex.EncodeToString(c.metaKey)
+}
+
+// Dir returns the cipher of the subdirectory, its path is relative to the
+// directory of c.
+func (c *NameCipher) Dir(path string) *NameCipher {
+	if c.key == nil {
+		return c
+	}
+	key := c.key
+	for _, name := range pathNames(path) {
+		key = hmacSum(key, "dir:"+name)
+	}
+	return &NameCipher{key: key, metaKey: c.metaKey}
+}
+
+// EncryptName encrypts the name of an entry of the directory.
+func (c *NameCipher) EncryptName(name string) (string, error) {
+	return sealDeterministic(c.key, name)
+}
+
+// DecryptName decrypts the name of an entry of the directory.
+func (c *NameCipher) DecryptName(encName string) (string, error) {
+	if c.key == nil {
+		return "", errors.New("invalid_name_key", "cipher of the metadata only")
+	}
+	return openDeterministic(c.key, encName)
+}
+
+// EncryptPath encrypts the path, relative to the directory of c, name by
+// name.
+func (c *NameCipher) EncryptPath(path string) (string, error) {
+	var sb strings.Builder
+	dir := c
+	for _, name := range pathNames(path) {
+		encName, err := dir.EncryptName(name)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("/")
+		sb.WriteString(encName)
+		dir = dir.Dir(name)
+	}
+	if sb.Len() == 0 {
+		return "/", nil
+	}
+	return sb.String(), nil
+}
+
+// DecryptPath decrypts the path encrypted with EncryptPath.
+func (c *NameCipher) DecryptPath(encPath string) (string, error) {
+	var sb strings.Builder
+	dir := c
+	for _, encName := range pathNames(encPath) {
+		name, err := dir.DecryptName(encName)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("/")
+		sb.WriteString(name)
+		dir = dir.Dir(name)
+	}
+	if sb.Len() == 0 {
+		return "/", nil
+	}
+	return sb.String(), nil
+}
+
+// EncryptMeta encrypts file metadata, as the mime type. Its key is the same
+// in all the directories, the metadata stays readable when files move.
+func (c *NameCipher) EncryptMeta(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	return sealDeterministic(c.metaKey, value)
+}
+
+// DecryptMeta decrypts the metadata encrypted with EncryptMeta.
+func (c *NameCipher) DecryptMeta(encValue string) (string, error) {
+	if encValue == "" {
+		return "", nil
+	}
+	return openDeterministic(c.metaKey, encValue)
+}
+
+func pathNames(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func hmacSum(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sealDeterministic encrypts with a nonce derived from the plaintext, equal
+// plaintexts give equal ciphertexts. The result is URL and path safe.
+func sealDeterministic(key []byte, plaintext string) (string, error) {
+	if key == nil {
+		return "", errors.New("invalid_name_key", "cipher of the metadata only")
+	}
+	nonce := hmacSum(key, "nonce:"+plaintext)[:12]
+	aesgcm, err := symAEAD(hmacSum(key, "enc"))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aesgcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+func openDeterministic(key []byte, ciphertext string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil || len(b) < 12 {
+		return "", errors.New("invalid_encrypted_name", "invalid encrypted name")
+	}
+	aesgcm, err := symAEAD(hmacSum(key, "enc"))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aesgcm.Open(nil, b[:12], b[12:], nil)
+	if err != nil {
+		return "", errors.New("invalid_encrypted_name", "name not encrypted with this key")
+	}
+	return string(plaintext), nil
+}
+
+// SealForPublicKey encrypts the data for the encryption public key, as
+// GetPublicKey returns it. Only OpenSealed with its private key opens it.
+func SealForPublicKey(encPublicKey string, data []byte) (string, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	keyBytes, err := base64.StdEncoding.DecodeString(encPublicKey)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid encryption public key")
+	}
+	pub := suite.Point()
+	if err = pub.UnmarshalBinary(keyBytes); err != nil {
+		return "", errors.Wrap(err, "invalid encryption public key")
+	}
+	r := suite.Scalar().Pick(suite.RandomStream())
+	R, err := suite.Point().Mul(r, nil).MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	ct, err := symEnc(data, sealKey(suite.Point().Mul(r, pub)), R)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(R, ct...)), nil
+}
+
+// OpenSealed decrypts the data sealed for the public key of the scheme.
+func OpenSealed(scheme EncryptionScheme, sealed string) ([]byte, error) {
+	pre, ok := scheme.(*PREEncryptionScheme)
+	if !ok {
+		return nil, errors.New("open_sealed_error", "encryption scheme can't open sealed data")
+	}
+	b, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sealed data")
+	}
+	R := pre.SuiteObj.Point()
+	size := R.MarshalSize()
+	if len(b) < size {
+		return nil, errors.New("open_sealed_error", "invalid sealed data")
+	}
+	if err = R.UnmarshalBinary(b[:size]); err != nil {
+		return nil, errors.Wrap(err, "invalid sealed data")
+	}
+	return symDec(b[size:], sealKey(pre.SuiteObj.Point().Mul(pre.PrivateKey, R)), b[:size])
+}
+
+func sealKey(shared interface{ MarshalBinary() ([]byte, error) }) []byte {
+	b, _ := shared.MarshalBinary()
+	h := sha512.Sum512(b)
+	return h[:]
+}
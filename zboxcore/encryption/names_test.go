@@ -0,0 +1,88 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameCipherPath(t *testing.T) {
+	names := NewNameCipher([]byte("allocation name key"))
+	enc := func(s string, err error) string {
+		require.NoError(t, err)
+		return s
+	}
+	encPath := enc(names.EncryptPath("/docs/reports/2021.pdf"))
+	require.Equal(t, encPath, enc(names.EncryptPath("/docs//reports/2021.pdf/")))
+	require.NotContains(t, encPath, "docs")
+	require.Len(t, pathNames(encPath), 3)
+
+	path, err := names.DecryptPath(encPath)
+	require.NoError(t, err)
+	require.Equal(t, "/docs/reports/2021.pdf", path)
+
+	// the same name encrypts differently in another directory
+	encName := enc(names.Dir("/docs").EncryptName("a"))
+	require.NotEqual(t, encName, enc(names.Dir("/pics").EncryptName("a")))
+	_, err = names.Dir("/pics").DecryptName(encName)
+	require.Error(t, err)
+	_, err = NewNameCipher([]byte("other key")).DecryptPath(encPath)
+	require.Error(t, err)
+
+	root, err := names.DecryptPath(enc(names.EncryptPath("/")))
+	require.NoError(t, err)
+	require.Equal(t, "/", root)
+}
+
+func TestNameCipherExport(t *testing.T) {
+	names := NewNameCipher([]byte("allocation name key"))
+	enc := func(s string, err error) string {
+		require.NoError(t, err)
+		return s
+	}
+	encPath := enc(names.EncryptPath("/docs/reports/2021.pdf"))
+	encMime := enc(names.EncryptMeta("application/pdf"))
+
+	shared, err := ImportNameCipher(names.Dir("/docs").Export())
+	require.NoError(t, err)
+	path, err := shared.DecryptPath(encPath[len(enc(names.EncryptPath("/docs"))):])
+	require.NoError(t, err)
+	require.Equal(t, "/reports/2021.pdf", path)
+	mime, err := shared.DecryptMeta(encMime)
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", mime)
+
+	meta, err := ImportNameCipher(names.Dir("/docs").ExportMeta())
+	require.NoError(t, err)
+	mime, err = meta.DecryptMeta(encMime)
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", mime)
+	_, err = meta.DecryptPath(encPath)
+	require.Error(t, err)
+	_, err = meta.EncryptName("2021.pdf")
+	require.Error(t, err)
+
+	_, err = ImportNameCipher("invalid")
+	require.Error(t, err)
+}
+
+func TestSealForPublicKey(t *testing.T) {
+	kr, err := NewKeyring()
+	require.NoError(t, err)
+	require.Len(t, kr.NameKey, 64)
+	reader, err := kr.Current().Scheme()
+	require.NoError(t, err)
+	readerKey, err := reader.GetPublicKey()
+	require.NoError(t, err)
+
+	sealed, err := SealForPublicKey(readerKey, []byte("name key"))
+	require.NoError(t, err)
+	data, err := OpenSealed(reader, sealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("name key"), data)
+
+	other := NewEncryptionScheme()
+	require.NoError(t, other.Initialize(testMnemonic))
+	_, err = OpenSealed(other, sealed)
+	require.Error(t, err)
+}
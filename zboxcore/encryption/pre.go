@@ -120,13 +120,16 @@ func (pre *PREEncryptionScheme) Initialize(mnemonic string) error {
 	rand := suite.XOF([]byte(mnemonic))
 
 	// Create a public/private keypair (X,x)
-	pre.PrivateKey = suite.Scalar().Pick(rand)
-	pre.PublicKey = suite.Point().Mul(pre.PrivateKey, nil)
-	pre.SuiteObj = suite
-
+	pre.initializeWithKey(suite, suite.Scalar().Pick(rand))
 	return nil
 }
 
+func (pre *PREEncryptionScheme) initializeWithKey(suite Suite, sk kyber.Scalar) {
+	pre.PrivateKey = sk
+	pre.PublicKey = suite.Point().Mul(sk, nil)
+	pre.SuiteObj = suite
+}
+
 func (pre *PREEncryptionScheme) InitForEncryption(tag string) {
 	pre.Tag = []byte(tag)
 
@@ -138,6 +141,7 @@ func (pre *PREEncryptionScheme) InitForEncryption(tag string) {
 
 func (pre *PREEncryptionScheme) InitForDecryption(tag string, encryptedKey string) error {
 	pre.Tag = []byte(tag)
+	_, encryptedKey, _ = ParseEncryptedKey(encryptedKey)
 
 	var g kyber.Group = pre.SuiteObj
 	keyBytes, err := base64.StdEncoding.DecodeString(encryptedKey)
@@ -153,6 +157,17 @@ func (pre *PREEncryptionScheme) InitForDecryption(tag string, encryptedKey strin
 	return nil
 }
 
+// initForFileKey restores the key T of the encrypted key, C1 = T + Ht.
+func (pre *PREEncryptionScheme) initForFileKey(tag, encryptedKey string) error {
+	if err := pre.InitForDecryption(tag, encryptedKey); err != nil {
+		return err
+	}
+	var g kyber.Group = pre.SuiteObj
+	pre.Ht = pre.hash1(pre.SuiteObj, pre.Tag, pre.PrivateKey)
+	pre.T = g.Point().Sub(pre.EncryptedKey, pre.Ht)
+	return nil
+}
+
 //--------------------------------H1: Maps to Point on Elliptic Curve---------------------------------------
 func (pre *PREEncryptionScheme) hash1(s Suite, tagA []byte, skA kyber.Scalar) kyber.Point {
 	var g kyber.Group = s
@@ -244,15 +259,15 @@ func (pre *PREEncryptionScheme) hash7(g kyber.Group, X kyber.Point, D2 []byte, D
 	return g.Scalar().SetBytes(h.Sum(nil))
 }
 
-func (pre *PREEncryptionScheme) encrypt(msg []byte) (*PREEncryptedMessage, error) {
+func (pre *PREEncryptionScheme) encrypt(msg []byte, ad []byte) (*PREEncryptedMessage, error) {
 	var C = new(PREEncryptedMessage)
 	C.TagA = pre.Tag
 	T := pre.T
 	var g kyber.Group = pre.SuiteObj
 	C.EncryptedKey = pre.EncryptedKey
 
-	key := pre.hash2(g, T)             // key = H2(T)
-	C2, err := pre.SymEnc(g, msg, key) // C2  = Sym.Encrypt(msg,key)
+	key := pre.hash2(g, T)          // key = H2(T)
+	C2, err := symEnc(msg, key, ad) // C2  = Sym.Encrypt(msg,key)
 	C.EncryptedData = C2
 	if err != nil {
 		return nil, err
@@ -266,47 +281,60 @@ func (pre *PREEncryptionScheme) encrypt(msg []byte) (*PREEncryptedMessage, error
 
 //---------------------------------Symmetric Encryption using AES with GCM mode---------------------------------
 func (pre *PREEncryptionScheme) SymEnc(group kyber.Group, message []byte, keyhash []byte) ([]byte, error) {
-	len := 32 + 12
-	key := keyhash[:32]
-	nonce := keyhash[32:len]
-	aes, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-	aesgcm, err := cipher.NewGCM(aes)
+	return symEnc(message, keyhash, nil)
+}
+
+//---------------------------------Symmetric Decryption using AES with GCM mode---------------------------------
+func (pre *PREEncryptionScheme) SymDec(group kyber.Group, ctx []byte, keyhash []byte) ([]byte, error) {
+	return symDec(ctx, keyhash, nil)
+}
+
+// symEnc encrypts with the key hash and authenticates the associated data.
+func symEnc(message []byte, keyhash []byte, ad []byte) ([]byte, error) {
+	aesgcm, err := symAEAD(keyhash)
 	if err != nil {
 		return nil, err
 	}
-	c := aesgcm.Seal(nil, nonce, message, nil)
-	var ctx bytes.Buffer
-	_, err = ctx.Write(c)
+	return aesgcm.Seal(nil, symNonce(keyhash, ad), message, ad), nil
+}
+
+func symDec(ctx []byte, keyhash []byte, ad []byte) ([]byte, error) {
+	aesgcm, err := symAEAD(keyhash)
 	if err != nil {
 		return nil, err
 	}
-	return ctx.Bytes(), nil
+	return aesgcm.Open(nil, symNonce(keyhash, ad), ctx, ad)
 }
 
-//---------------------------------Symmetric Decryption using AES with GCM mode---------------------------------
-func (pre *PREEncryptionScheme) SymDec(group kyber.Group, ctx []byte, keyhash []byte) ([]byte, error) {
-	len := 32 + 12
-	key := keyhash[:32]
-	nonce := keyhash[32:len]
-
-	// Decrypt message using AES-GCM
-	aes, err := aes.NewCipher(key)
+func symAEAD(keyhash []byte) (cipher.AEAD, error) {
+	aes, err := aes.NewCipher(keyhash[:32])
 	if err != nil {
 		return nil, err
 	}
-	aesgcm, err := cipher.NewGCM(aes)
-	if err != nil {
-		return nil, err
+	return cipher.NewGCM(aes)
+}
+
+// symNonce is the nonce of the key hash, unique per associated data: all
+// the chunks of a file share the key hash.
+func symNonce(keyhash []byte, ad []byte) []byte {
+	if len(ad) == 0 {
+		return keyhash[32 : 32+12]
 	}
-	return aesgcm.Open(nil, nonce, ctx, nil)
+	h := sha256.New()
+	h.Write(keyhash[32:])
+	h.Write(ad)
+	return h.Sum(nil)[:12]
 }
 
 func (pre *PREEncryptionScheme) Encrypt(data []byte) (*EncryptedMessage, error) {
+	return pre.encryptWithAD(data, nil)
+}
+
+// encryptWithAD encrypts the data and binds the associated data to it,
+// decryptWithAD needs the same associated data.
+func (pre *PREEncryptionScheme) encryptWithAD(data []byte, ad []byte) (*EncryptedMessage, error) {
 	//condA := []byte("filetype:audio")
-	encryptedMsg, err := pre.encrypt(data)
+	encryptedMsg, err := pre.encrypt(data, ad)
 	if err != nil {
 		return nil, err
 	}
@@ -318,7 +346,7 @@ func (pre *PREEncryptionScheme) Encrypt(data []byte) (*EncryptedMessage, error)
 	//return encryptedMsg.EncryptedData, err
 }
 
-func (pre *PREEncryptionScheme) decrypt(encMsg *EncryptedMessage) ([]byte, error) {
+func (pre *PREEncryptionScheme) decrypt(encMsg *EncryptedMessage, ad []byte) ([]byte, error) {
 	var g kyber.Group = pre.SuiteObj
 	C := &PREEncryptedMessage{}
 	C.EncryptedKey = pre.EncryptedKey
@@ -347,7 +375,7 @@ func (pre *PREEncryptionScheme) decrypt(encMsg *EncryptedMessage) ([]byte, error
 	Ht := pre.hash1(pre.SuiteObj, pre.Tag, pre.PrivateKey) // Ht  = H1(tagA,skA)
 	T := g.Point().Sub(C.EncryptedKey, Ht)                 // T   = C1 - Ht
 	key := pre.hash2(g, T)                                 // key = H2(T)
-	recmsg, err2 := pre.SymDec(g, C.EncryptedData, key)    // recover message using Sym.Decrypt(C2,key)
+	recmsg, err2 := symDec(C.EncryptedData, key, ad)       // recover message using Sym.Decrypt(C2,key)
 	if err2 == nil {
 		chk2 := pre.hash3(g, recmsg, T)
 		if !bytes.Equal(chk2, C.MessageChecksum) { // Check if C3 = H3(m,T)
@@ -408,7 +436,7 @@ func (pre *PREEncryptionScheme) reEncrypt(encMsg *EncryptedMessage, reGenKey str
 }
 
 //-----------------------------------------------ReDecryption-------------------------------------------------
-func (pre *PREEncryptionScheme) reDecrypt(D *reEncryptedMessage) ([]byte, error) {
+func (pre *PREEncryptionScheme) reDecrypt(D *reEncryptedMessage, ad []byte) ([]byte, error) {
 	s := pre.SuiteObj
 	tXj := s.Point().Mul(pre.PrivateKey, D.D5) // tXj   = skB.D5
 	var g kyber.Group = s
@@ -421,7 +449,7 @@ func (pre *PREEncryptionScheme) reDecrypt(D *reEncryptedMessage) ([]byte, error)
 	T := g.Point().Sub(T1, T2) // T     = bet^(-1).D1 - skB^(-1).D4
 	key := pre.hash2(g, T)     // key   = H2(T)
 
-	recmsg, err2 := pre.SymDec(g, D.D2, key) // recover message using Sym.Decrypt(D2,key)
+	recmsg, err2 := symDec(D.D2, key, ad) // recover message using Sym.Decrypt(D2,key)
 	if err2 == nil {
 		chk2 := pre.hash3(g, recmsg, T)
 		if !bytes.Equal(chk2, D.D3) { // Check if D3 = H3(m,T)
@@ -434,18 +462,22 @@ func (pre *PREEncryptionScheme) reDecrypt(D *reEncryptedMessage) ([]byte, error)
 }
 
 func (pre *PREEncryptionScheme) Decrypt(encMsg *EncryptedMessage) ([]byte, error) {
+	return pre.decryptWithAD(encMsg, nil)
+}
+
+func (pre *PREEncryptionScheme) decryptWithAD(encMsg *EncryptedMessage, ad []byte) ([]byte, error) {
 	if len(encMsg.ReEncryptionKey) > 0 {
 		reEncMsg, err := pre.reEncrypt(encMsg, encMsg.ReEncryptionKey)
 		if err != nil {
 			return nil, err
 		}
-		decryptedMessage, err := pre.reDecrypt(reEncMsg)
+		decryptedMessage, err := pre.reDecrypt(reEncMsg, ad)
 		if err != nil {
 			return nil, err
 		}
 		return decryptedMessage, nil
 	}
-	decryptedMessage, err := pre.decrypt(encMsg)
+	decryptedMessage, err := pre.decrypt(encMsg, ad)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,49 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+// LegacyTag is the proxy re-encryption tag of the files uploaded before
+// per-file tags, a re-encryption key for it unlocks all of them.
+const LegacyTag = "filetype:audio"
+
+// NewFileTag returns a random tag, the re-encryption keys issued for it
+// only unlock the file encrypted with it.
+func NewFileTag() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generating encryption tag")
+	}
+	return "file:" + hex.EncodeToString(b), nil
+}
+
+// DirectoryTag returns the tag of the files encrypted for the directory of
+// the lookup hash, the re-encryption keys issued for it unlock all of them.
+// Tags are not secret, re-encryption keys also need the owner's key.
+func DirectoryTag(dirLookupHash string) string {
+	return "dir:" + dirLookupHash
+}
+
+// ValidateTag checks the tag can be recorded in the encrypted key of a
+// file.
+func ValidateTag(tag string) error {
+	if strings.Contains(tag, fieldSeparator) {
+		return errors.New("invalid_encryption_tag", "encryption tag contains "+fieldSeparator)
+	}
+	return nil
+}
+
+// FileTag returns the tag a file was encrypted with, recorded in its
+// encrypted key.
+func FileTag(encryptedKey string) string {
+	_, _, tag := ParseEncryptedKey(encryptedKey)
+	if tag == "" {
+		return LegacyTag
+	}
+	return tag
+}
@@ -0,0 +1,52 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReEncryptionKeyTag(t *testing.T) {
+	data := []byte("tag test data")
+	owner := NewEncryptionScheme()
+	require.NoError(t, owner.Initialize(testMnemonic))
+	fileTag, err := NewFileTag()
+	require.NoError(t, err)
+	otherTag, err := NewFileTag()
+	require.NoError(t, err)
+	require.NotEqual(t, fileTag, otherTag)
+
+	kr, err := NewKeyring()
+	require.NoError(t, err)
+	reader, err := kr.Current().Scheme()
+	require.NoError(t, err)
+	readerKey, err := reader.GetPublicKey()
+	require.NoError(t, err)
+	reKey, err := owner.GetReGenKey(readerKey, fileTag)
+	require.NoError(t, err)
+
+	decrypt := func(tag string) error {
+		owner.InitForEncryption(tag)
+		msg, err := owner.Encrypt(data)
+		require.NoError(t, err)
+		require.NoError(t, reader.InitForDecryption(tag, msg.EncryptedKey))
+		msg.ReEncryptionKey = reKey
+		got, err := reader.Decrypt(msg)
+		if err == nil {
+			require.Equal(t, data, got)
+		}
+		return err
+	}
+	require.NoError(t, decrypt(fileTag))
+	require.Error(t, decrypt(otherTag), "the re-encryption key must only unlock its tag")
+	require.Error(t, decrypt(LegacyTag))
+
+	require.Equal(t, LegacyTag, FileTag(""))
+	require.Equal(t, LegacyTag, FileTag(FormatEncryptedKey("id", "key", "", LegacyChunkVersion)))
+	encryptedKey := FormatEncryptedKey("", "key", fileTag, LegacyChunkVersion)
+	require.Equal(t, fileTag, FileTag(encryptedKey))
+	keyID, key, tag := ParseEncryptedKey(encryptedKey)
+	require.Equal(t, []string{"", "key", fileTag}, []string{keyID, key, tag})
+	keyID, key, tag = ParseEncryptedKey(FormatEncryptedKey("id", "key", fileTag, LegacyChunkVersion))
+	require.Equal(t, []string{"id", "key", fileTag}, []string{keyID, key, tag})
+}
@@ -18,6 +18,16 @@ type AuthTicket struct {
 	Timestamp       int64  `json:"timestamp"`
 	ReEncryptionKey string `json:"re_encryption_key"`
 	Signature       string `json:"signature"`
+	// NameKey is the sealed key of the encrypted names of the shared
+	// path, not signed since blobbers don't use it.
+	NameKey string `json:"name_key,omitempty"`
+	// EncryptionKeyID is the ID of the recipient key the re-encryption key
+	// and the name key are for, not signed since blobbers don't use it.
+	EncryptionKeyID string `json:"encryption_key_id,omitempty"`
+	// ReEncryptionKeys are the re-encryption keys of a directory share by
+	// the ID of the owner key its files were encrypted with, recorded in
+	// their encrypted keys. Not signed since blobbers don't use them.
+	ReEncryptionKeys map[string]string `json:"re_encryption_keys,omitempty"`
 }
 
 func (rm *AuthTicket) GetHashData() string {
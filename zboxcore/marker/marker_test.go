@@ -0,0 +1,104 @@
+package marker
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestClient(t *testing.T, scheme string) *zcncrypto.Wallet {
+	w, err := zcncrypto.NewSignatureScheme(scheme).GenerateKeys()
+	require.NoError(t, err)
+	wStr, err := w.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, client.PopulateClient(wStr, scheme))
+	return w
+}
+
+func TestVerifyWriteMarkers(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519"} {
+		w := setupTestClient(t, scheme)
+		var wms []*WriteMarker
+		for i := int64(0); i < 4; i++ {
+			wm := &WriteMarker{
+				AllocationRoot: "root",
+				AllocationID:   "alloc",
+				BlobberID:      "blobber",
+				ClientID:       w.ClientID,
+				Size:           i,
+				Timestamp:      i,
+			}
+			require.NoError(t, wm.Sign())
+			require.NoError(t, wm.VerifySignature(w.ClientKey), scheme)
+			wms = append(wms, wm)
+		}
+		require.NoError(t, VerifyWriteMarkers(wms, w.ClientKey), scheme)
+
+		other, err := zcncrypto.NewSignatureScheme(scheme).GenerateKeys()
+		require.NoError(t, err)
+		require.Error(t, wms[0].VerifySignature(other.ClientKey), scheme)
+		require.Error(t, VerifyWriteMarkers(wms, other.ClientKey), scheme)
+
+		wms[2].Size++
+		require.Error(t, wms[2].VerifySignature(w.ClientKey), scheme)
+		require.Error(t, VerifyWriteMarkers(wms, w.ClientKey), scheme)
+	}
+}
+
+func TestVerifyReadMarkers(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519"} {
+		var rms []*ReadMarker
+		for i := int64(0); i < 4; i++ {
+			w := setupTestClient(t, scheme)
+			rm := &ReadMarker{
+				ClientID:        w.ClientID,
+				ClientPublicKey: w.ClientKey,
+				BlobberID:       "blobber",
+				AllocationID:    "alloc",
+				OwnerID:         "owner",
+				Timestamp:       common.Timestamp(i),
+				ReadCounter:     i,
+			}
+			require.NoError(t, rm.Sign())
+			require.NoError(t, rm.VerifySignature(), scheme)
+			rms = append(rms, rm)
+		}
+		require.NoError(t, VerifyReadMarkers(rms), scheme)
+
+		rms[1].ReadCounter++
+		require.Error(t, rms[1].VerifySignature(), scheme)
+		require.Error(t, VerifyReadMarkers(rms), scheme)
+	}
+}
+
+func TestReadMarkerVerifyClient(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519"} {
+		w := setupTestClient(t, scheme)
+		rm := &ReadMarker{
+			ClientID:        w.ClientID,
+			ClientPublicKey: w.ClientKey,
+			BlobberID:       "blobber",
+			AllocationID:    "alloc",
+			ReadCounter:     10,
+		}
+		require.NoError(t, rm.Sign())
+		require.NoError(t, rm.VerifyClient(w.ClientID, w.ClientKey, "blobber", "alloc"), scheme)
+		require.Error(t, rm.VerifyClient(w.ClientID, w.ClientKey, "other", "alloc"), scheme)
+		require.Error(t, rm.VerifyClient(w.ClientID, w.ClientKey, "blobber", "other"), scheme)
+
+		// a marker signed by another key verifies on its own, but not as
+		// the client's
+		forger := setupTestClient(t, scheme)
+		forged := *rm
+		forged.ClientPublicKey = forger.ClientKey
+		forged.ReadCounter = 1000
+		require.NoError(t, forged.Sign())
+		require.NoError(t, forged.VerifySignature(), scheme)
+		require.Error(t, forged.VerifyClient(w.ClientID, w.ClientKey, "blobber", "alloc"), scheme)
+		forged.ClientPublicKey = w.ClientKey
+		require.Error(t, forged.VerifyClient(w.ClientID, w.ClientKey, "blobber", "alloc"), scheme)
+	}
+}
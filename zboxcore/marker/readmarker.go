@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
 	"github.com/0chain/gosdk/zboxcore/client"
 )
 
@@ -31,3 +33,38 @@ func (rm *ReadMarker) Sign() error {
 	rm.Signature, err = client.Sign(rm.GetHash())
 	return err
 }
+
+// VerifySignature verifies the signature of the read marker with its
+// client public key.
+func (rm *ReadMarker) VerifySignature() error {
+	return VerifyReadMarkers([]*ReadMarker{rm})
+}
+
+// VerifyClient checks that the read marker was signed by the client for
+// the blobber and the allocation. The signature is verified with the given
+// client public key, not with the key the marker carries.
+func (rm *ReadMarker) VerifyClient(clientID, clientPublicKey, blobberID, allocationID string) error {
+	if rm.ClientID != clientID || rm.ClientPublicKey != clientPublicKey ||
+		rm.BlobberID != blobberID || rm.AllocationID != allocationID {
+		return errors.New("read_marker_validation_failed", "Read marker of another client, blobber or allocation")
+	}
+	return verifyMarkers("read_marker_validation_failed", []zcncrypto.SignedHash{{
+		PublicKey: clientPublicKey,
+		Signature: rm.Signature,
+		Hash:      rm.GetHash(),
+	}})
+}
+
+// VerifyReadMarkers verifies the signatures of the read markers at once,
+// each one with its client public key.
+func VerifyReadMarkers(rms []*ReadMarker) error {
+	signed := make([]zcncrypto.SignedHash, 0, len(rms))
+	for _, rm := range rms {
+		signed = append(signed, zcncrypto.SignedHash{
+			PublicKey: rm.ClientPublicKey,
+			Signature: rm.Signature,
+			Hash:      rm.GetHash(),
+		})
+	}
+	return verifyMarkers("read_marker_validation_failed", signed)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
 	"github.com/0chain/gosdk/zboxcore/client"
 )
 
@@ -35,15 +36,33 @@ func (wm *WriteMarker) Sign() error {
 	return err
 }
 
+// VerifySignature verifies the signature of the write marker with the
+// client public key.
 func (wm *WriteMarker) VerifySignature(clientPublicKey string) error {
-	hashData := wm.GetHashData()
-	signatureHash := encryption.Hash(hashData)
-	sigOK, err := client.VerifySignature(wm.Signature, signatureHash)
+	return VerifyWriteMarkers([]*WriteMarker{wm}, clientPublicKey)
+}
+
+// VerifyWriteMarkers verifies the signatures of the write markers, all
+// signed by the client public key, at once.
+func VerifyWriteMarkers(wms []*WriteMarker, clientPublicKey string) error {
+	signed := make([]zcncrypto.SignedHash, 0, len(wms))
+	for _, wm := range wms {
+		signed = append(signed, zcncrypto.SignedHash{
+			PublicKey: clientPublicKey,
+			Signature: wm.Signature,
+			Hash:      wm.GetHash(),
+		})
+	}
+	return verifyMarkers("write_marker_validation_failed", signed)
+}
+
+func verifyMarkers(code string, signed []zcncrypto.SignedHash) error {
+	sigOK, err := client.BatchVerifySignatures(signed)
 	if err != nil {
-		return errors.New("write_marker_validation_failed", "Error during verifying signature. "+err.Error())
+		return errors.New(code, "Error during verifying signatures. "+err.Error())
 	}
 	if !sigOK {
-		return errors.New("write_marker_validation_failed", "Write marker signature is not valid")
+		return errors.New(code, "Marker signature is not valid")
 	}
 	return nil
 }
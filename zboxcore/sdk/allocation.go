@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	pathpkg "path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -21,6 +22,8 @@ import (
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/transaction"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
+	"github.com/0chain/gosdk/zboxcore/compression"
+	"github.com/0chain/gosdk/zboxcore/encryption"
 	. "github.com/0chain/gosdk/zboxcore/logger"
 	"github.com/0chain/gosdk/zboxcore/marker"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
@@ -77,10 +80,19 @@ type ConsolidatedFileMeta struct {
 	Size            int64
 	ActualFileSize  int64
 	ActualNumBlocks int64
+	ThumbnailSize   int64
 	EncryptedKey    string
+	EncryptionTag   string
 	CommitMetaTxns  []fileref.CommitMetaTxn
 	Collaborators   []fileref.Collaborator
 	Attributes      fileref.Attributes
+	// Compression is the algorithm the file is compressed with, Size its
+	// uncompressed size and CompressedSize the size uploaded.
+	Compression    string
+	CompressedSize int64
+	// Dedup is set for deduplicated files, Size is the size of the file,
+	// not of its recipe.
+	Dedup bool
 }
 
 type AllocationStats struct {
@@ -173,6 +185,16 @@ type Allocation struct {
 	downloadProgressMap     map[string]*DownloadRequest
 	repairRequestInProgress *RepairRequest
 	initialized             bool
+	nameCipher              *encryption.NameCipher
+	// renameStatePath is the local file pending renames are saved in, see
+	// SetRenameStatePath
+	renameStatePath string
+	// pendingRename is the rename of a directory whose names are not all
+	// re-encrypted yet
+	pendingRename *PendingRename
+	// dedupMutex serializes the updates of the dedup manifest, set when
+	// deduplication is enabled
+	dedupMutex *sync.Mutex
 }
 
 func (a *Allocation) GetStats() *AllocationStats {
@@ -317,13 +339,75 @@ func (a *Allocation) EncryptAndUploadFileWithThumbnail(localpath string,
 		thumbnailpath, true, false, attrs)
 }
 
+// EncryptAndUploadFileWithTag uploads the file encrypted with the proxy
+// re-encryption tag. The re-encryption keys of its shares unlock the files
+// with the same tag, see DirectoryEncryptionTag. An empty tag is a new tag
+// for the file.
+func (a *Allocation) EncryptAndUploadFileWithTag(localpath string, remotepath string,
+	encryptionTag string, attrs fileref.Attributes, status StatusCallback) error {
+
+	return a.uploadOrUpdateFileWithTag(localpath, remotepath, status, false, "",
+		true, encryptionTag, "", false, attrs, nil)
+}
+
+// EncryptAndUpdateFileWithTag updates the file encrypted with the proxy
+// re-encryption tag, see EncryptAndUploadFileWithTag.
+func (a *Allocation) EncryptAndUpdateFileWithTag(localpath string, remotepath string,
+	encryptionTag string, attrs fileref.Attributes, status StatusCallback) error {
+
+	return a.uploadOrUpdateFileWithTag(localpath, remotepath, status, true, "",
+		true, encryptionTag, "", false, attrs, nil)
+}
+
+// CompressAndUploadFile uploads the file compressed with the algorithm,
+// compression.Gzip or compression.Zstd, before it is encrypted and erasure
+// coded. Downloads decompress it.
+func (a *Allocation) CompressAndUploadFile(localpath string, remotepath string,
+	algorithm string, encrypt bool, attrs fileref.Attributes, status StatusCallback) error {
+
+	return a.uploadOrUpdateFileWithTag(localpath, remotepath, status, false, "",
+		encrypt, "", algorithm, false, attrs, nil)
+}
+
+// CompressAndUpdateFile updates the file compressed with the algorithm, see
+// CompressAndUploadFile.
+func (a *Allocation) CompressAndUpdateFile(localpath string, remotepath string,
+	algorithm string, encrypt bool, attrs fileref.Attributes, status StatusCallback) error {
+
+	return a.uploadOrUpdateFileWithTag(localpath, remotepath, status, true, "",
+		encrypt, "", algorithm, false, attrs, nil)
+}
+
+// DirectoryEncryptionTag returns the proxy re-encryption tag of the
+// directory. The encrypted share of the directory unlocks the files
+// uploaded with it, whatever their path.
+func (a *Allocation) DirectoryEncryptionTag(dirPath string) (string, error) {
+	remotePath, err := a.remotePath(zboxutil.RemoteClean(dirPath))
+	if err != nil {
+		return "", err
+	}
+	return encryption.DirectoryTag(fileref.GetReferenceLookup(a.ID, remotePath)), nil
+}
+
 func (a *Allocation) uploadOrUpdateFile(localpath string, remotepath string,
 	status StatusCallback, isUpdate bool, thumbnailpath string, encryption bool,
 	isRepair bool, attrs fileref.Attributes) error {
 
+	return a.uploadOrUpdateFileWithTag(localpath, remotepath, status, isUpdate,
+		thumbnailpath, encryption, "", "", isRepair, attrs, nil)
+}
+
+func (a *Allocation) uploadOrUpdateFileWithTag(localpath string, remotepath string,
+	status StatusCallback, isUpdate bool, thumbnailpath string, encryption bool,
+	encryptionTag string, compressionAlgorithm string, isRepair bool,
+	attrs fileref.Attributes, recipe *recipeUpload) error {
+
 	if !a.isInitialized() {
 		return notInitialized
 	}
+	if compressionAlgorithm != "" && !compression.IsSupported(compressionAlgorithm) {
+		return errors.New("invalid_compression", "Unknown compression algorithm "+compressionAlgorithm)
+	}
 
 	fileInfo, err := GetFileInfo(localpath)
 	if err != nil {
@@ -347,31 +431,28 @@ func (a *Allocation) uploadOrUpdateFile(localpath string, remotepath string,
 		return errors.New("invalid_path", "Path should be valid and absolute")
 	}
 	remotepath = zboxutil.GetFullRemotePath(localpath, remotepath)
+	if a.dedupMutex != nil && recipe == nil && !encryption && compressionAlgorithm == "" &&
+		!isRepair && !isDedupPath(remotepath) {
+		go a.dedupUpload(localpath, remotepath, thumbnailpath, isUpdate, attrs, status)
+		return nil
+	}
+	if remotepath, err = a.remotePath(remotepath); err != nil {
+		return err
+	}
 
-	var fileName string
-	_, fileName = filepath.Split(remotepath)
-	uploadReq := &UploadRequest{}
-	uploadReq.remotefilepath = remotepath
+	uploadReq := a.newUploadRequest(remotepath, fileInfo.Size(), thumbnailSize, isUpdate, attrs, status)
 	uploadReq.thumbnailpath = thumbnailpath
 	uploadReq.filepath = localpath
-	uploadReq.filemeta = &UploadFileMeta{}
-	uploadReq.filemeta.Name = fileName
-	uploadReq.filemeta.Size = fileInfo.Size()
-	uploadReq.filemeta.Path = remotepath
-	uploadReq.filemeta.ThumbnailSize = thumbnailSize
-	uploadReq.filemeta.Attributes = attrs
-	uploadReq.remaining = uploadReq.filemeta.Size
-	uploadReq.thumbRemaining = uploadReq.filemeta.ThumbnailSize
-	uploadReq.isUpdate = isUpdate
 	uploadReq.isRepair = isRepair
-	uploadReq.connectionID = zboxutil.NewConnectionId()
-	uploadReq.statusCallback = status
-	uploadReq.datashards = a.DataShards
-	uploadReq.parityshards = a.ParityShards
-	uploadReq.setUploadMask(len(a.Blobbers))
-	uploadReq.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
-	uploadReq.fullconsensus = float32(a.DataShards + a.ParityShards)
 	uploadReq.isEncrypted = encryption
+	uploadReq.encTag = encryptionTag
+	uploadReq.compression = compressionAlgorithm
+	if recipe != nil {
+		if err = uploadReq.setContent(recipe.path); err != nil {
+			return errors.Wrap(err, "Local file error")
+		}
+		uploadReq.filemeta.Dedup = recipe.meta
+	}
 	uploadReq.completedCallback = func(filepath string) {
 		a.mutex.Lock()
 		defer a.mutex.Unlock()
@@ -379,7 +460,7 @@ func (a *Allocation) uploadOrUpdateFile(localpath string, remotepath string,
 	}
 
 	if uploadReq.isRepair {
-		found, repairRequired, fileRef, err := a.RepairRequired(remotepath)
+		found, repairRequired, fileRef, err := a.repairRequired(remotepath)
 		if err != nil {
 			return err
 		}
@@ -387,12 +468,39 @@ func (a *Allocation) uploadOrUpdateFile(localpath string, remotepath string,
 		if !repairRequired {
 			return errors.New("Repair not required")
 		}
-
-		file, _ := ioutil.ReadFile(localpath)
+		uploadReq.filemeta.CustomMeta = fileRef.CustomMeta
+
+		hashpath := localpath
+		if meta := compressionMetaOf(fileRef); meta != nil {
+			// compressed again the same way, the shards match the
+			// blobbers' ones
+			uploadReq.compression = meta.Algorithm
+			if err = uploadReq.compress(meta.FrameSize); err != nil {
+				return err
+			}
+			hashpath = uploadReq.contentPath
+		}
+		if meta := dedupMetaOf(fileRef); meta != nil {
+			// the recipe of the file again, the same as the blobbers' one
+			recipePath, err := writeRecipeOf(localpath)
+			if err != nil {
+				return err
+			}
+			if err = uploadReq.setContent(recipePath); err != nil {
+				os.Remove(recipePath)
+				return err
+			}
+			uploadReq.filemeta.Dedup = meta
+			hashpath = recipePath
+		}
+		file, _ := ioutil.ReadFile(hashpath)
 		hash := sha1.New()
 		hash.Write(file)
 		contentHash := hex.EncodeToString(hash.Sum(nil))
 		if contentHash != fileRef.ActualFileHash {
+			if uploadReq.contentPath != "" {
+				os.Remove(uploadReq.contentPath)
+			}
 			return errors.New("Content hash doesn't match")
 		}
 
@@ -402,6 +510,9 @@ func (a *Allocation) uploadOrUpdateFile(localpath string, remotepath string,
 	}
 
 	if !uploadReq.IsFullConsensusSupported() {
+		if uploadReq.contentPath != "" {
+			os.Remove(uploadReq.contentPath)
+		}
 		return errors.New(fmt.Sprintf("allocation requires [%v] blobbers, which is greater than the maximum permitted number of [%v]. reduce number of data or parity shards and try again", uploadReq.fullconsensus, uploadReq.GetMaxBlobbersSupported()))
 	}
 
@@ -414,7 +525,43 @@ func (a *Allocation) uploadOrUpdateFile(localpath string, remotepath string,
 	return nil
 }
 
+// newUploadRequest returns the request uploading the content of the size
+// to the remote path, once its content is set.
+func (a *Allocation) newUploadRequest(remotepath string, size, thumbnailSize int64,
+	isUpdate bool, attrs fileref.Attributes, status StatusCallback) *UploadRequest {
+	var fileName string
+	_, fileName = filepath.Split(remotepath)
+	uploadReq := &UploadRequest{}
+	uploadReq.remotefilepath = remotepath
+	uploadReq.filemeta = &UploadFileMeta{}
+	uploadReq.filemeta.Name = fileName
+	uploadReq.filemeta.Size = size
+	uploadReq.filemeta.Path = remotepath
+	uploadReq.filemeta.ThumbnailSize = thumbnailSize
+	uploadReq.filemeta.Attributes = attrs
+	uploadReq.remaining = uploadReq.filemeta.Size
+	uploadReq.thumbRemaining = uploadReq.filemeta.ThumbnailSize
+	uploadReq.isUpdate = isUpdate
+	uploadReq.connectionID = zboxutil.NewConnectionId()
+	uploadReq.statusCallback = status
+	uploadReq.datashards = a.DataShards
+	uploadReq.parityshards = a.ParityShards
+	uploadReq.setUploadMask(len(a.Blobbers))
+	uploadReq.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
+	uploadReq.fullconsensus = float32(a.DataShards + a.ParityShards)
+	uploadReq.names = a.nameCipher
+	return uploadReq
+}
+
 func (a *Allocation) RepairRequired(remotepath string) (zboxutil.Uint128, bool, *fileref.FileRef, error) {
+	remotepath, err := a.remotePath(remotepath)
+	if err != nil {
+		return zboxutil.NewUint128(0), false, nil, err
+	}
+	return a.repairRequired(remotepath)
+}
+
+func (a *Allocation) repairRequired(remotepath string) (zboxutil.Uint128, bool, *fileref.FileRef, error) {
 	if !a.isInitialized() {
 		return zboxutil.Uint128{}, false, nil, notInitialized
 	}
@@ -449,9 +596,27 @@ func (a *Allocation) DownloadThumbnail(localPath string, remotePath string, stat
 	return a.downloadFile(localPath, remotePath, DOWNLOAD_CONTENT_THUMB, 1, 0, numBlockDownloads, status)
 }
 
+// DownloadFileRange downloads the bytes [offset, offset+length) of the file,
+// only the blocks holding them are read. The bytes of compressed files are
+// decompressed from the frames holding them.
+func (a *Allocation) DownloadFileRange(localPath string, remotePath string, offset int64, length int64, status StatusCallback) error {
+	if offset < 0 || length <= 0 {
+		return errors.New("invalid_range", "Range should have a positive offset and length")
+	}
+	return a.downloadFileRange(localPath, remotePath, DOWNLOAD_CONTENT_FULL, 1, 0, numBlockDownloads,
+		offset, length, false, status)
+}
+
 func (a *Allocation) downloadFile(localPath string, remotePath string, contentMode string,
 	startBlock int64, endBlock int64, numBlocks int,
 	status StatusCallback) error {
+	return a.downloadFileRange(localPath, remotePath, contentMode, startBlock, endBlock, numBlocks,
+		0, 0, false, status)
+}
+
+func (a *Allocation) downloadFileRange(localPath string, remotePath string, contentMode string,
+	startBlock int64, endBlock int64, numBlocks int, rangeOffset int64, rangeLength int64,
+	raw bool, status StatusCallback) error {
 	if !a.isInitialized() {
 		return notInitialized
 	}
@@ -468,33 +633,30 @@ func (a *Allocation) downloadFile(localPath string, remotePath string, contentMo
 	}
 	lPath, _ := filepath.Split(localPath)
 	os.MkdirAll(lPath, os.ModePerm)
+	remotePath, err := a.remotePath(remotePath)
+	if err != nil {
+		return err
+	}
 
 	if len(a.Blobbers) <= 1 {
 		return noBLOBBERS
 	}
 
-	downloadReq := &DownloadRequest{}
-	downloadReq.allocationID = a.ID
-	downloadReq.allocationTx = a.Tx
-	downloadReq.ctx, _ = context.WithCancel(a.ctx)
+	downloadReq := a.newDownloadRequest(remotePath, contentMode, status)
 	downloadReq.localpath = localPath
-	downloadReq.remotefilepath = remotePath
-	downloadReq.statusCallback = status
-	downloadReq.downloadMask = zboxutil.NewUint128(1).Lsh(uint64(len(a.Blobbers))).Sub64(1)
-	downloadReq.blobbers = a.Blobbers
-	downloadReq.datashards = a.DataShards
-	downloadReq.parityshards = a.ParityShards
 	downloadReq.startBlock = startBlock - 1
 	downloadReq.endBlock = endBlock
 	downloadReq.numBlocks = int64(numBlocks)
-	downloadReq.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
-	downloadReq.fullconsensus = float32(a.DataShards + a.ParityShards)
+	downloadReq.rangeOffset = rangeOffset
+	downloadReq.rangeLength = rangeLength
+	if !raw {
+		downloadReq.reassemble = a.reassemble
+	}
 	downloadReq.completedCallback = func(remotepath string, remotepathhash string) {
 		a.mutex.Lock()
 		defer a.mutex.Unlock()
 		delete(a.downloadProgressMap, remotepath)
 	}
-	downloadReq.contentMode = contentMode
 	go func() {
 		a.downloadChan <- downloadReq
 		a.mutex.Lock()
@@ -504,6 +666,26 @@ func (a *Allocation) downloadFile(localPath string, remotePath string, contentMo
 	return nil
 }
 
+// newDownloadRequest returns the request downloading the content of the
+// remote path, once its destination is set.
+func (a *Allocation) newDownloadRequest(remotePath string, contentMode string,
+	status StatusCallback) *DownloadRequest {
+	downloadReq := &DownloadRequest{}
+	downloadReq.allocationID = a.ID
+	downloadReq.allocationTx = a.Tx
+	downloadReq.ctx, _ = context.WithCancel(a.ctx)
+	downloadReq.remotefilepath = remotePath
+	downloadReq.statusCallback = status
+	downloadReq.downloadMask = zboxutil.NewUint128(1).Lsh(uint64(len(a.Blobbers))).Sub64(1)
+	downloadReq.blobbers = a.Blobbers
+	downloadReq.datashards = a.DataShards
+	downloadReq.parityshards = a.ParityShards
+	downloadReq.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
+	downloadReq.fullconsensus = float32(a.DataShards + a.ParityShards)
+	downloadReq.contentMode = contentMode
+	return downloadReq
+}
+
 func (a *Allocation) ListDirFromAuthTicket(authTicket string, lookupHash string) (*ListResult, error) {
 	if !a.isInitialized() {
 		return nil, notInitialized
@@ -532,6 +714,9 @@ func (a *Allocation) ListDirFromAuthTicket(authTicket string, lookupHash string)
 	listReq.authToken = at
 	ref := listReq.GetListFromBlobbers()
 	if ref != nil {
+		if v := openNames(at); v != nil {
+			decryptListResult(v, ref)
+		}
 		return ref, nil
 	}
 	return nil, errors.New("list_request_failed", "Failed to get list response from the blobbers")
@@ -540,7 +725,18 @@ func (a *Allocation) ListDirFromAuthTicket(authTicket string, lookupHash string)
 func (a *Allocation) ListDir(path string) (*ListResult, error) {
 	consensusThresh := (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
 	fullconsensus := float32(a.DataShards + a.ParityShards)
-	return a.listDir(path, consensusThresh, fullconsensus)
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := a.listDir(remotePath, consensusThresh, fullconsensus)
+	if err != nil {
+		return nil, err
+	}
+	if a.nameCipher != nil {
+		decryptListResult(&nameView{names: a.nameCipher}, ref)
+	}
+	return ref, nil
 }
 
 func (a *Allocation) listDir(path string, consensusThresh, fullconsensus float32) (*ListResult, error) {
@@ -571,11 +767,51 @@ func (a *Allocation) listDir(path string, consensusThresh, fullconsensus float32
 }
 
 func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.getFileMeta(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if a.nameCipher != nil {
+		decryptFileMeta(&nameView{names: a.nameCipher}, result)
+	}
+	return result, nil
+}
+
+func (a *Allocation) getFileMeta(path string) (*ConsolidatedFileMeta, error) {
 	if !a.isInitialized() {
 		return nil, notInitialized
 	}
 
+	ref, err := a.getFileRef(path)
+	if err != nil {
+		return nil, err
+	}
 	result := &ConsolidatedFileMeta{}
+	result.Type = ref.Type
+	result.Name = ref.Name
+	result.Hash = ref.ActualFileHash
+	result.LookupHash = ref.LookupHash
+	result.MimeType = ref.MimeType
+	result.Path = ref.Path
+	result.Size = ref.ActualFileSize
+	result.EncryptedKey = ref.EncryptedKey
+	_, _, result.EncryptionTag = encryption.ParseEncryptedKey(ref.EncryptedKey)
+	result.CommitMetaTxns = ref.CommitMetaTxns
+	result.Collaborators = ref.Collaborators
+	result.Attributes = ref.Attributes
+	result.ActualFileSize = ref.Size
+	result.ActualNumBlocks = ref.NumBlocks
+	result.ThumbnailSize = ref.ActualThumbnailSize
+	result.setContent(ref)
+	return result, nil
+}
+
+// getFileRef returns the file ref of the path the blobbers agree on.
+func (a *Allocation) getFileRef(path string) (*fileref.FileRef, error) {
 	listReq := &ListRequest{}
 	listReq.allocationID = a.ID
 	listReq.allocationTx = a.Tx
@@ -586,24 +822,27 @@ func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
 	listReq.remotefilepath = path
 	_, ref, _ := listReq.getFileConsensusFromBlobbers()
 	if ref != nil {
-		result.Type = ref.Type
-		result.Name = ref.Name
-		result.Hash = ref.ActualFileHash
-		result.LookupHash = ref.LookupHash
-		result.MimeType = ref.MimeType
-		result.Path = ref.Path
-		result.Size = ref.ActualFileSize
-		result.EncryptedKey = ref.EncryptedKey
-		result.CommitMetaTxns = ref.CommitMetaTxns
-		result.Collaborators = ref.Collaborators
-		result.Attributes = ref.Attributes
-		result.ActualFileSize = ref.Size
-		result.ActualNumBlocks = ref.NumBlocks
-		return result, nil
+		return ref, nil
 	}
 	return nil, errors.New("file_meta_error", "Error getting the file meta data from blobbers")
 }
 
+// setContent reports the size and hash of the content of the compressed
+// or deduplicated file.
+func (meta *ConsolidatedFileMeta) setContent(ref *fileref.FileRef) {
+	if c := compressionMetaOf(ref); c != nil {
+		meta.Compression = c.Algorithm
+		meta.CompressedSize = ref.ActualFileSize
+		meta.Size = c.Size
+		meta.Hash = c.Hash
+	}
+	if d := dedupMetaOf(ref); d != nil {
+		meta.Dedup = true
+		meta.Size = d.Size
+		meta.Hash = d.Hash
+	}
+}
+
 func (a *Allocation) GetFileMetaFromAuthTicket(authTicket string, lookupHash string) (*ConsolidatedFileMeta, error) {
 	if !a.isInitialized() {
 		return nil, notInitialized
@@ -644,6 +883,10 @@ func (a *Allocation) GetFileMetaFromAuthTicket(authTicket string, lookupHash str
 		result.CommitMetaTxns = ref.CommitMetaTxns
 		result.ActualFileSize = ref.Size
 		result.ActualNumBlocks = ref.NumBlocks
+		result.setContent(ref)
+		if v := openNames(at); v != nil {
+			decryptFileMeta(v, result)
+		}
 		return result, nil
 	}
 	return nil, errors.New("file_meta_error", "Error getting the file meta data from blobbers")
@@ -668,7 +911,11 @@ func (a *Allocation) GetFileStats(path string) (map[string]*FileStats, error) {
 	listReq.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
 	listReq.fullconsensus = float32(a.DataShards + a.ParityShards)
 	listReq.ctx = a.ctx
-	listReq.remotefilepath = path
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	listReq.remotefilepath = remotePath
 	ref := listReq.getFileStatsFromBlobbers()
 	if ref != nil {
 		return ref, nil
@@ -677,9 +924,20 @@ func (a *Allocation) GetFileStats(path string) (map[string]*FileStats, error) {
 }
 
 func (a *Allocation) DeleteFile(path string) error {
+	if a.dedupMutex != nil && !isDedupPath(path) {
+		return a.deleteDeduplicated(path)
+	}
+	return a.deletePath(path)
+}
+
+func (a *Allocation) deletePath(path string) error {
 	consensusThresh := (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
 	fullconsensus := float32(a.DataShards + a.ParityShards)
-	return a.deleteFile(path, consensusThresh, fullconsensus)
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return err
+	}
+	return a.deleteFile(remotePath, consensusThresh, fullconsensus)
 }
 
 func (a *Allocation) deleteFile(path string, threshConsensus, fullConsensus float32) error {
@@ -712,6 +970,18 @@ func (a *Allocation) deleteFile(path string, threshConsensus, fullConsensus floa
 }
 
 func (a *Allocation) RenameObject(path string, destName string) error {
+	if a.nameCipher == nil || !zboxutil.IsRemoteAbs(path) {
+		return a.renameObject(path, destName)
+	}
+	path = zboxutil.RemoteClean(path)
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return err
+	}
+	return a.renameEncrypted(remotePath, path, pathpkg.Join(pathpkg.Dir(path), destName))
+}
+
+func (a *Allocation) renameObject(path string, destName string) error {
 	if !a.isInitialized() {
 		return notInitialized
 	}
@@ -773,7 +1043,9 @@ func (a *Allocation) UpdateObjectAttributes(path string,
 	ar.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
 	ar.fullconsensus = float32(a.DataShards + a.ParityShards)
 	ar.ctx = a.ctx
-	ar.remotefilepath = path
+	if ar.remotefilepath, err = a.remotePath(path); err != nil {
+		return err
+	}
 	ar.attributesMask = 0
 	ar.connectionID = zboxutil.NewConnectionId()
 
@@ -789,6 +1061,34 @@ func (a *Allocation) MoveObject(path string, destPath string) error {
 }
 
 func (a *Allocation) CopyObject(path string, destPath string) error {
+	if a.dedupMutex != nil && !isDedupPath(path) {
+		return a.copyDeduplicated(path, destPath)
+	}
+	return a.copyPath(path, destPath)
+}
+
+func (a *Allocation) copyPath(path string, destPath string) error {
+	if a.nameCipher == nil || !zboxutil.IsRemoteAbs(path) || !zboxutil.IsRemoteAbs(destPath) {
+		return a.copyObject(path, destPath)
+	}
+	path, destPath = zboxutil.RemoteClean(path), zboxutil.RemoteClean(destPath)
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return err
+	}
+	remoteDestPath, err := a.remotePath(destPath)
+	if err != nil {
+		return err
+	}
+	if err := a.copyObject(remotePath, remoteDestPath); err != nil {
+		return err
+	}
+	// the copy keeps the name encrypted with the key of the source directory
+	copied := pathpkg.Join(remoteDestPath, pathpkg.Base(remotePath))
+	return a.renameEncrypted(copied, path, pathpkg.Join(destPath, pathpkg.Base(path)))
+}
+
+func (a *Allocation) copyObject(path string, destPath string) error {
 	if !a.isInitialized() {
 		return notInitialized
 	}
@@ -841,6 +1141,23 @@ func (a *Allocation) GetAuthTicket(path string, filename string, referenceType s
 	shareReq.ctx = a.ctx
 	shareReq.remotefilepath = path
 	shareReq.remotefilename = filename
+	if a.nameCipher != nil {
+		var err error
+		if shareReq.remotefilepath, err = a.remotePath(path); err != nil {
+			return "", err
+		}
+		shareReq.remotefilename, err = a.nameCipher.Dir(pathpkg.Dir(path)).EncryptName(filename)
+		if err != nil {
+			return "", err
+		}
+		if len(refereeEncryptionPublicKey) > 0 {
+			nameKey, err := a.sealNames(path, referenceType == fileref.DIRECTORY, refereeEncryptionPublicKey)
+			if err != nil {
+				return "", err
+			}
+			shareReq.nameKey = nameKey
+		}
+	}
 	if referenceType == fileref.DIRECTORY {
 		shareReq.refType = fileref.DIRECTORY
 	} else {
@@ -870,7 +1187,11 @@ func (a *Allocation) CancelUpload(localpath string) error {
 }
 
 func (a *Allocation) CancelDownload(remotepath string) error {
-	if downloadReq, ok := a.downloadProgressMap[remotepath]; ok {
+	remotePath, err := a.remotePath(remotepath)
+	if err != nil {
+		return err
+	}
+	if downloadReq, ok := a.downloadProgressMap[remotePath]; ok {
 		downloadReq.isDownloadCanceled = true
 		return nil
 	}
@@ -977,7 +1298,12 @@ func (a *Allocation) CommitMetaTransaction(path, crudOperation, authTicket, look
 
 	if fileMeta == nil {
 		if len(path) > 0 {
-			fileMeta, err = a.GetFileMeta(path)
+			// the meta data committed on chain keeps the names encrypted
+			var remotePath string
+			if remotePath, err = a.remotePath(path); err != nil {
+				return err
+			}
+			fileMeta, err = a.getFileMeta(remotePath)
 			if err != nil {
 				return err
 			}
@@ -1009,7 +1335,11 @@ func (a *Allocation) StartRepair(localRootPath, pathToRepair string, statusCB St
 
 	fullconsensus := float32(a.DataShards + a.ParityShards)
 	consensusThresh := 100 / fullconsensus
-	listDir, err := a.listDir(pathToRepair, consensusThresh, fullconsensus)
+	remotePath, err := a.remotePath(pathToRepair)
+	if err != nil {
+		return err
+	}
+	listDir, err := a.listDir(remotePath, consensusThresh, fullconsensus)
 	if err != nil {
 		return err
 	}
@@ -1122,8 +1452,12 @@ func (a *Allocation) AddCollaborator(filePath, collaboratorID string) error {
 		return notInitialized
 	}
 
+	remotePath, err := a.remotePath(filePath)
+	if err != nil {
+		return err
+	}
 	req := &CollaboratorRequest{
-		path:           filePath,
+		path:           remotePath,
 		collaboratorID: collaboratorID,
 		a:              a,
 	}
@@ -1139,8 +1473,12 @@ func (a *Allocation) RemoveCollaborator(filePath, collaboratorID string) error {
 		return notInitialized
 	}
 
+	remotePath, err := a.remotePath(filePath)
+	if err != nil {
+		return err
+	}
 	req := &CollaboratorRequest{
-		path:           filePath,
+		path:           remotePath,
 		collaboratorID: collaboratorID,
 		a:              a,
 	}
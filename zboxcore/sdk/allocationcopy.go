@@ -0,0 +1,303 @@
+package sdk
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	. "github.com/0chain/gosdk/zboxcore/logger"
+	"go.uber.org/zap"
+)
+
+// emptyDirPlaceholder is the file uploaded to create an empty directory.
+const emptyDirPlaceholder = ".zbox-dir"
+
+// What to do with the source allocation once it is copied.
+const (
+	SourceKeep     = ""
+	SourceCancel   = "cancel"
+	SourceFinalize = "finalize"
+)
+
+// ReshardAllocation creates an allocation of the request, for example with
+// other shards or blobbers, then copies the source allocation to it. See
+// CopyAllocation.
+func ReshardAllocation(source *Allocation, req *CreateAllocationRequest, lock int64,
+	sourceAction string, status StatusCallback) (*Allocation, []string, error) {
+
+	if !source.isInitialized() {
+		return nil, nil, notInitialized
+	}
+	if !isSourceAction(sourceAction) {
+		return nil, nil, errors.New("invalid_source_action", "Unknown source action "+sourceAction)
+	}
+	id, err := CreateAllocationWithRequest(req, lock)
+	if err != nil {
+		return nil, nil, err
+	}
+	Logger.Info("Target allocation created", zap.String("source", source.ID), zap.String("target", id))
+	target, err := GetAllocation(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	reshare, err := CopyAllocation(source, target, sourceAction, status)
+	return target, reshare, err
+}
+
+// CopyAllocation copies the files and the empty directories of the source
+// allocation to the target one, with their thumbnails, attributes,
+// encryption and collaborators. The hash of each file is verified once
+// copied, the files the target already has with the same hash are skipped
+// so that a copy started again resumes. The source is then canceled or
+// finalized as sourceAction asks.
+//
+// Auth tickets name the allocation, the shares of the source don't open
+// the copies. The encrypted files keep their tag, the one of the target
+// directory for the files uploaded with a directory tag, so that their
+// shares issued again from the target unlock them as before. The paths of
+// the encrypted files copied are returned, the shares to issue again.
+func CopyAllocation(source, target *Allocation, sourceAction string, status StatusCallback) ([]string, error) {
+	if !source.isInitialized() || !target.isInitialized() {
+		return nil, notInitialized
+	}
+	if !isSourceAction(sourceAction) {
+		return nil, errors.New("invalid_source_action", "Unknown source action "+sourceAction)
+	}
+	if source.ID == target.ID {
+		return nil, errors.New("invalid_target", "Target is the source allocation")
+	}
+	files, emptyDirs, err := source.listTree("/")
+	if err != nil {
+		return nil, err
+	}
+	tmpDir, err := ioutil.TempDir("", "zbox-copy")
+	if err != nil {
+		return nil, errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fail := func(err error) ([]string, error) {
+		if status != nil {
+			status.Error(source.ID, target.ID, OpUpload, err)
+		}
+		return nil, err
+	}
+
+	if status != nil {
+		status.Started(source.ID, target.ID, OpUpload, len(files))
+	}
+	var (
+		copied  int
+		reshare []string
+	)
+	for i, remotePath := range files {
+		path, err := source.plainPath(remotePath)
+		if err != nil {
+			return nil, err
+		}
+		// the chunks are copied with the files holding them
+		if isDedupPath(path) {
+			continue
+		}
+		encrypted, err := copyAllocationFile(source, target, tmpDir, path)
+		if err != nil {
+			return fail(errors.Wrap(err, "copy of "+path+" failed"))
+		}
+		if encrypted {
+			reshare = append(reshare, path)
+		}
+		copied++
+		if status != nil {
+			status.InProgress(source.ID, target.ID, OpUpload, i+1, nil)
+		}
+	}
+	for _, remotePath := range emptyDirs {
+		path, err := source.plainPath(remotePath)
+		if err != nil {
+			return nil, err
+		}
+		if isDedupPath(path) {
+			continue
+		}
+		if err = copyEmptyDir(target, tmpDir, path); err != nil {
+			return fail(errors.Wrap(err, "copy of "+path+" failed"))
+		}
+	}
+
+	switch sourceAction {
+	case SourceCancel:
+		_, err = CancelAllocation(source.ID)
+	case SourceFinalize:
+		_, err = FinalizeAllocation(source.ID)
+	}
+	if err != nil {
+		return fail(errors.Wrap(err, sourceAction+" of the source failed"))
+	}
+	if status != nil {
+		status.Completed(source.ID, target.ID, "", "", copied, OpUpload)
+	}
+	return reshare, nil
+}
+
+func isSourceAction(action string) bool {
+	return action == SourceKeep || action == SourceCancel || action == SourceFinalize
+}
+
+// copyAllocationFile copies the file, it returns whether it is encrypted.
+func copyAllocationFile(source, target *Allocation, tmpDir, path string) (bool, error) {
+	meta, err := source.GetFileMeta(path)
+	if err != nil {
+		return false, err
+	}
+	encrypted := len(meta.EncryptedKey) > 0
+	if copied, err := target.GetFileMeta(path); err == nil {
+		if checkPlainHash("Target", meta, copied.Hash) != nil {
+			return false, errors.New("copy_conflict", "Target has another file "+path)
+		}
+		return encrypted, addCollaborators(target, path, meta, copied)
+	}
+
+	localPath := filepath.Join(tmpDir, "file")
+	defer os.Remove(localPath)
+	err = new(waitStatusCB).run(func(cb StatusCallback) error {
+		return source.DownloadFile(localPath, path, cb)
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "download failed")
+	}
+	if hash, err := fileHash(localPath); err != nil {
+		return false, err
+	} else if err = checkPlainHash("Downloaded", meta, hash); err != nil {
+		return false, err
+	}
+	var thumbnailPath string
+	if meta.ThumbnailSize > 0 {
+		thumbnailPath = filepath.Join(tmpDir, "thumbnail")
+		defer os.Remove(thumbnailPath)
+		err = new(waitStatusCB).run(func(cb StatusCallback) error {
+			return source.DownloadThumbnail(thumbnailPath, path, cb)
+		})
+		if err != nil {
+			return false, errors.Wrap(err, "thumbnail download failed")
+		}
+	}
+
+	var tag string
+	if encrypted {
+		if tag, err = copiedEncryptionTag(source, target, path, meta.EncryptionTag); err != nil {
+			return false, err
+		}
+	}
+	err = new(waitStatusCB).run(func(cb StatusCallback) error {
+		return target.uploadOrUpdateFileWithTag(localPath, path, cb, false, thumbnailPath,
+			encrypted, tag, meta.Compression, false, meta.Attributes, nil)
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "upload failed")
+	}
+
+	copied, err := target.GetFileMeta(path)
+	if err != nil {
+		return false, err
+	}
+	if err = checkPlainHash("Copied", meta, copied.Hash); err != nil {
+		return false, err
+	}
+	if err = addCollaborators(target, path, meta, copied); err != nil {
+		return false, err
+	}
+	Logger.Info("File copied", zap.String("path", path), zap.Int64("size", meta.Size))
+	return encrypted, nil
+}
+
+// copiedEncryptionTag returns the tag of the copy of the file. The tags of
+// the directories are derived from the allocation, a file uploaded with
+// the tag of a source directory gets the one of the target directory.
+func copiedEncryptionTag(source, target *Allocation, path, tag string) (string, error) {
+	for dir := pathpkg.Dir(path); ; dir = pathpkg.Dir(dir) {
+		dirTag, err := source.DirectoryEncryptionTag(dir)
+		if err != nil {
+			return "", err
+		}
+		if dirTag == tag {
+			return target.DirectoryEncryptionTag(dir)
+		}
+		if dir == "/" {
+			return tag, nil
+		}
+	}
+}
+
+// copyEmptyDir creates the directory in the target. Blobbers create the
+// directories of the files uploaded and keep them once the files are
+// deleted: a placeholder file is uploaded to the directory and deleted.
+func copyEmptyDir(target *Allocation, tmpDir, path string) error {
+	if _, err := target.GetFileMeta(path); err == nil {
+		return nil
+	}
+	localPath := filepath.Join(tmpDir, emptyDirPlaceholder)
+	if err := ioutil.WriteFile(localPath, []byte{0}, 0600); err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.Remove(localPath)
+	placeholder := pathpkg.Join(path, emptyDirPlaceholder)
+	err := new(waitStatusCB).run(func(cb StatusCallback) error {
+		return target.UploadFile(localPath, placeholder, fileref.Attributes{}, cb)
+	})
+	if err != nil {
+		return errors.Wrap(err, "placeholder upload failed")
+	}
+	if err = target.DeleteFile(placeholder); err != nil {
+		return errors.Wrap(err, "placeholder delete failed")
+	}
+	Logger.Info("Directory copied", zap.String("path", path))
+	return nil
+}
+
+// addCollaborators adds the collaborators of the source file missing from
+// the copy.
+func addCollaborators(target *Allocation, path string, meta, copied *ConsolidatedFileMeta) error {
+	added := make(map[string]bool)
+	for _, c := range copied.Collaborators {
+		added[c.ClientID] = true
+	}
+	for _, c := range meta.Collaborators {
+		if added[c.ClientID] {
+			continue
+		}
+		if err := target.AddCollaborator(path, c.ClientID); err != nil {
+			return errors.Wrap(err, "collaborator "+c.ClientID+" not added")
+		}
+	}
+	return nil
+}
+
+// checkPlainHash returns an error if the hash isn't the one of the source
+// file. The hashes compared are the ones of the plaintext: the hash of the
+// meta is the one of the file downloaded, not of the compressed file or of
+// the recipe uploaded.
+func checkPlainHash(what string, meta *ConsolidatedFileMeta, hash string) error {
+	if hash != meta.Hash {
+		return errors.New("hash_mismatch", what+" file hash is "+hash+", expected "+meta.Hash)
+	}
+	return nil
+}
+
+func fileHash(localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", errors.Wrap(err, "Local file error")
+	}
+	defer f.Close()
+	h := sha1.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "Local file error")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/compression"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyAllocation_invalid(t *testing.T) {
+	sdkInitialized = true
+	source := &Allocation{ID: "source", initialized: true}
+	target := &Allocation{ID: "target", initialized: true}
+
+	for _, tt := range []struct {
+		target *Allocation
+		action string
+	}{
+		{target, "delete"},
+		{source, SourceKeep},
+		{&Allocation{ID: "target"}, SourceKeep},
+	} {
+		_, err := CopyAllocation(source, tt.target, tt.action, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestCopiedEncryptionTag(t *testing.T) {
+	source := &Allocation{ID: "source"}
+	target := &Allocation{ID: "target"}
+	dirTag := func(a *Allocation, dir string) string {
+		tag, err := a.DirectoryEncryptionTag(dir)
+		require.NoError(t, err)
+		return tag
+	}
+
+	// shared with the directory, the tag of the target directory
+	tag, err := copiedEncryptionTag(source, target, "/a/b/f.txt", dirTag(source, "/a"))
+	require.NoError(t, err)
+	require.Equal(t, dirTag(target, "/a"), tag)
+	tag, err = copiedEncryptionTag(source, target, "/f.txt", dirTag(source, "/"))
+	require.NoError(t, err)
+	require.Equal(t, dirTag(target, "/"), tag)
+
+	// the tag of the file
+	fileTag, err := encryption.NewFileTag()
+	require.NoError(t, err)
+	tag, err = copiedEncryptionTag(source, target, "/a/b/f.txt", fileTag)
+	require.NoError(t, err)
+	require.Equal(t, fileTag, tag)
+}
+
+func TestFileHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestFileHash")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data := []byte("content of the file")
+	localPath := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(localPath, data, 0644))
+	h := sha1.Sum(data)
+	hash, err := fileHash(localPath)
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(h[:]), hash)
+
+	_, err = fileHash(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func TestCheckPlainHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestCheckPlainHash")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data := bytes.Repeat([]byte("content of the file "), 1000)
+	localPath := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(localPath, data, 0644))
+	downloaded, err := fileHash(localPath)
+	require.NoError(t, err)
+	contentHash := func(b []byte) string {
+		h := sha1.Sum(b)
+		return hex.EncodeToString(h[:])
+	}
+	// the meta of the file as the blobbers return it
+	fileMeta := func(ref *fileref.FileRef) *ConsolidatedFileMeta {
+		meta := &ConsolidatedFileMeta{Hash: ref.ActualFileHash, Size: ref.ActualFileSize}
+		meta.setContent(ref)
+		return meta
+	}
+
+	// the source file is compressed
+	var compressed bytes.Buffer
+	cmeta, err := compression.Compress(compression.Zstd, bytes.NewReader(data), &compressed, 4096)
+	require.NoError(t, err)
+	source := fileMeta(&fileref.FileRef{
+		ActualFileHash: contentHash(compressed.Bytes()),
+		ActualFileSize: int64(compressed.Len()),
+		CustomMeta:     customMetaOf(t, cmeta, nil),
+	})
+	require.NoError(t, checkPlainHash("Downloaded", source, downloaded))
+
+	// the copy is deduplicated by the target
+	recipePath, err := writeRecipeOf(localPath)
+	require.NoError(t, err)
+	defer os.Remove(recipePath)
+	recipe, err := ioutil.ReadFile(recipePath)
+	require.NoError(t, err)
+	_, dmeta, err := chunkFile(localPath, nil)
+	require.NoError(t, err)
+	copied := fileMeta(&fileref.FileRef{
+		ActualFileHash: contentHash(recipe),
+		ActualFileSize: int64(len(recipe)),
+		CustomMeta:     customMetaOf(t, nil, dmeta),
+	})
+	require.NoError(t, checkPlainHash("Copied", source, copied.Hash))
+	require.NoError(t, checkPlainHash("Copied", copied, downloaded))
+
+	// the hashes of the content uploaded differ
+	require.Error(t, checkPlainHash("Copied", source, contentHash(recipe)))
+	require.Error(t, checkPlainHash("Downloaded", source, contentHash(compressed.Bytes())))
+}
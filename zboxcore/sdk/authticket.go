@@ -42,6 +42,9 @@ func (at *AuthTicket) GetFileName() (string, error) {
 	if err != nil {
 		return "", errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
 	}
+	if v := openNames(authTicket); v != nil {
+		return v.shared.Name, nil
+	}
 	return authTicket.FileName, nil
 }
 
@@ -225,7 +225,11 @@ func (req *BlockDownloadRequest) downloadBlobberBlock() {
 				// 	req.result <- &rspData
 				// 	return nil
 				// }
-				if !rspData.Success && rspData.LatestRM != nil && rspData.LatestRM.ReadCounter >= getBlobberReadCtr(req.blobber) {
+				// the read counter is only taken from a read marker the client
+				// signed for this blobber and allocation
+				if !rspData.Success && rspData.LatestRM != nil && rspData.LatestRM.ReadCounter >= getBlobberReadCtr(req.blobber) &&
+					rspData.LatestRM.VerifyClient(client.GetClientID(), client.GetClientPublicKey(),
+						req.blobber.ID, req.allocationID) == nil {
 					Logger.Info("Will be retrying download")
 					setBlobberReadCtr(req.blobber, rspData.LatestRM.ReadCounter)
 					shouldRetry = true
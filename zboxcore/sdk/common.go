@@ -99,3 +99,33 @@ func getAllocationDataFromBlobber(blobber *blockchain.StorageNode, allocationTx
 	respCh <- &result
 	return
 }
+
+func getReferencePathFromBlobber(ctx context.Context, allocationTx string, paths []string, blobber *blockchain.StorageNode) (*ReferencePathResult, error) {
+	httpreq, err := zboxutil.NewReferencePathRequest(blobber.Baseurl, allocationTx, paths)
+	if err != nil {
+		Logger.Error(blobber.Baseurl, "Error creating reference path request", err)
+		return nil, err
+	}
+	var lR ReferencePathResult
+	ctx, cncl := context.WithTimeout(ctx, (time.Second * 30))
+	err = zboxutil.HttpDo(ctx, cncl, httpreq, func(resp *http.Response, err error) error {
+		if err != nil {
+			Logger.Error("Ref path error:", err)
+			return err
+		}
+		defer resp.Body.Close()
+		resp_body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			Logger.Error("Ref path: Resp", err)
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.New(strconv.Itoa(resp.StatusCode), fmt.Sprintf("Reference path error response: Body: %s ", string(resp_body)))
+		}
+		return json.Unmarshal(resp_body, &lR)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &lR, nil
+}
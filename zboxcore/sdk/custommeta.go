@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"encoding/json"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/compression"
+	"github.com/0chain/gosdk/zboxcore/dedup"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+)
+
+// The compression and the deduplication of a file are recorded in its
+// custom meta, blobbers store it as it is. It is a JSON object, the keys
+// below are the client's, the others are kept.
+const (
+	customMetaCompression = "compression"
+	customMetaDedup       = "dedup"
+)
+
+// mergeCustomMeta returns the custom meta with the compression and the
+// deduplication of the file set, and the ones it recorded removed. The
+// custom meta without them is returned as it is.
+func mergeCustomMeta(customMeta string, c *compression.Meta, d *dedup.Meta) (string, error) {
+	fields := make(map[string]json.RawMessage)
+	if customMeta != "" && json.Unmarshal([]byte(customMeta), &fields) != nil {
+		if c != nil || d != nil {
+			return "", errors.New("custom_meta_error", "Custom meta is not a JSON object, "+
+				"the compression or the deduplication can't be recorded in it")
+		}
+		return customMeta, nil
+	}
+	if customMeta == "" && c == nil && d == nil {
+		return "", nil
+	}
+	delete(fields, customMetaCompression)
+	delete(fields, customMetaDedup)
+	if c != nil {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return "", err
+		}
+		fields[customMetaCompression] = b
+	}
+	if d != nil {
+		b, err := json.Marshal(d)
+		if err != nil {
+			return "", err
+		}
+		fields[customMetaDedup] = b
+	}
+	// an emptied custom meta is "{}", it replaces the one the blobbers have
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// compressionMetaOf returns the compression recorded in the custom meta of
+// the file, nil for an uncompressed file.
+func compressionMetaOf(ref *fileref.FileRef) *compression.Meta {
+	var meta *compression.Meta
+	customMetaField(ref, customMetaCompression, &meta)
+	return meta
+}
+
+// dedupMetaOf returns the deduplication recorded in the custom meta of the
+// file, nil for a file which is not a recipe.
+func dedupMetaOf(ref *fileref.FileRef) *dedup.Meta {
+	var meta *dedup.Meta
+	customMetaField(ref, customMetaDedup, &meta)
+	return meta
+}
+
+func customMetaField(ref *fileref.FileRef, key string, v interface{}) {
+	fields := make(map[string]json.RawMessage)
+	if ref.CustomMeta == "" || json.Unmarshal([]byte(ref.CustomMeta), &fields) != nil {
+		// any other custom meta records neither
+		return
+	}
+	if field, ok := fields[key]; ok {
+		_ = json.Unmarshal(field, v)
+	}
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/compression"
+	"github.com/0chain/gosdk/zboxcore/dedup"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/stretchr/testify/require"
+)
+
+func customMetaOf(t *testing.T, c *compression.Meta, d *dedup.Meta) string {
+	customMeta, err := mergeCustomMeta("", c, d)
+	require.NoError(t, err)
+	return customMeta
+}
+
+func TestMergeCustomMeta(t *testing.T) {
+	meta := &compression.Meta{Algorithm: compression.Zstd, FrameSize: 4096, Size: 10, Hash: "hash"}
+	ref := &fileref.FileRef{CustomMeta: customMetaOf(t, meta, nil)}
+	require.Equal(t, meta, compressionMetaOf(ref))
+	require.Nil(t, dedupMetaOf(ref))
+
+	recipe := &dedup.Meta{Size: 10, Hash: "hash"}
+	ref.CustomMeta = customMetaOf(t, nil, recipe)
+	require.Nil(t, compressionMetaOf(ref))
+	require.Equal(t, recipe, dedupMetaOf(ref))
+
+	require.Empty(t, customMetaOf(t, nil, nil))
+	ref.CustomMeta = "custom"
+	require.Nil(t, compressionMetaOf(ref))
+	require.Nil(t, dedupMetaOf(ref))
+
+	// the custom meta is left as it is without compression nor dedup
+	customMeta, err := mergeCustomMeta("custom", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "custom", customMeta)
+	_, err = mergeCustomMeta("custom", meta, nil)
+	require.Error(t, err)
+
+	// the other fields are kept, the compression replaced
+	customMeta, err = mergeCustomMeta(`{"app":{"tag":1},"dedup":{"size":1}}`, meta, nil)
+	require.NoError(t, err)
+	ref.CustomMeta = customMeta
+	require.Equal(t, meta, compressionMetaOf(ref))
+	require.Nil(t, dedupMetaOf(ref))
+	require.Contains(t, customMeta, `"app":{"tag":1}`)
+
+	// the compression of the previous content is removed
+	customMeta, err = mergeCustomMeta(customMeta, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"app":{"tag":1}}`, customMeta)
+	customMeta, err = mergeCustomMeta(customMetaOf(t, meta, nil), nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "{}", customMeta)
+}
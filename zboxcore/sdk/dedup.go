@@ -0,0 +1,742 @@
+package sdk
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/dedup"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	. "github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"go.uber.org/zap"
+)
+
+// recipeUpload is the recipe uploaded as the content of a deduplicated file.
+type recipeUpload struct {
+	path string
+	meta *dedup.Meta
+}
+
+// manifestFile is the manifest of the allocation and where it is saved.
+// The hash is the one of the manifest loaded, the manifest is saved only if
+// it is still the one of the allocation. The check does not make the save
+// atomic, the manifest is changed only by the client holding its lock.
+type manifestFile struct {
+	*dedup.Manifest
+	exists bool
+	hash   string
+	dir    string
+}
+
+// maxManifestUpdates is the number of times a change to the manifest is
+// applied to a manifest changed meanwhile by another client.
+const maxManifestUpdates = 5
+
+var errManifestChanged = errors.New("dedup_error", "Manifest changed since loaded")
+
+// dedupLockTTL is how long the lock of the manifest is held at most, a
+// lock left by a client which stopped is taken over once expired.
+const dedupLockTTL = time.Hour
+
+var errDedupLocked = errors.New("dedup_locked", "Deduplicated files are being changed by another client")
+
+// dedupLock is the content of the lock of the manifest.
+type dedupLock struct {
+	ClientID string `json:"client_id"`
+	Token    string `json:"token"`
+	Expires  int64  `json:"expires"`
+}
+
+// EnableDeduplication splits the files uploaded afterwards into
+// content-defined chunks, each stored once in the allocation. The files
+// are recipes listing their chunks, downloads reassemble them. Encrypted
+// and compressed uploads are not deduplicated.
+//
+// The chunks are reference counted by a manifest, changed by one client at
+// a time: uploads, copies and deletes of deduplicated files take a lock
+// stored in the allocation and fail with a dedup_locked error while another
+// client holds it. A client stopped while changing the files can leave
+// chunks counted but unused or stored but not counted, RepairDeduplication
+// recounts them.
+func (a *Allocation) EnableDeduplication() {
+	a.dedupMutex = &sync.Mutex{}
+}
+
+// IsDeduplicated returns whether deduplication is enabled.
+func (a *Allocation) IsDeduplicated() bool {
+	return a.dedupMutex != nil
+}
+
+// isDedupPath returns whether the path is the one of the chunks or of the
+// manifest, handled as any other file.
+func isDedupPath(path string) bool {
+	return path == dedup.Dir || strings.HasPrefix(path, dedup.Dir+"/")
+}
+
+func (a *Allocation) dedupUpload(localpath, remotepath, thumbnailpath string, isUpdate bool,
+	attrs fileref.Attributes, status StatusCallback) {
+	err := a.uploadDeduplicated(localpath, remotepath, thumbnailpath, isUpdate, attrs, status)
+	if err != nil {
+		Logger.Error("Deduplicated upload failed", zap.String("path", remotepath), zap.Error(err))
+		if status != nil {
+			status.Error(a.ID, remotepath, OpUpload, err)
+		}
+	}
+}
+
+// uploadDeduplicated uploads the chunks of the file missing from the
+// allocation, then the recipe of the file. The chunks are referenced before
+// the recipe is uploaded and released if it fails, so that a recipe never
+// references chunks not counted: a stop in between leaves them counted,
+// released by RepairDeduplication. The chunks of the file updated no longer
+// referenced are deleted.
+func (a *Allocation) uploadDeduplicated(localpath, remotepath, thumbnailpath string, isUpdate bool,
+	attrs fileref.Attributes, status StatusCallback) error {
+	a.dedupMutex.Lock()
+	defer a.dedupMutex.Unlock()
+
+	tmpDir, err := ioutil.TempDir("", "zbox-dedup")
+	if err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	unlock, err := a.lockManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	manifest, err := a.loadManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	var oldRecipe *dedup.Recipe
+	if isUpdate {
+		if oldRecipe, err = a.loadRecipe(tmpDir, remotepath); err != nil {
+			return err
+		}
+	}
+	fileInfo, err := GetFileInfo(localpath)
+	if err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	if status != nil {
+		status.Started(a.ID, remotepath, OpUpload, int(fileInfo.Size()))
+	}
+
+	var (
+		uploaded = make(map[string]bool)
+		recipe   *dedup.Recipe
+		meta     *dedup.Meta
+	)
+	err = a.updateManifest(manifest, func(m *manifestFile) error {
+		// chunks released meanwhile by another client are uploaded again
+		var done int64
+		recipe, meta, err = chunkFile(localpath, func(hash string, chunk []byte) error {
+			done += int64(len(chunk))
+			if !m.Has(hash) && !uploaded[hash] {
+				if err := a.uploadChunk(tmpDir, hash, chunk); err != nil {
+					return err
+				}
+				uploaded[hash] = true
+			}
+			if status != nil {
+				status.InProgress(a.ID, remotepath, OpUpload, int(done), nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		m.AddRefs(recipe)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	recipePath, err := writeRecipe(tmpDir, recipe)
+	if err != nil {
+		return err
+	}
+	err = new(waitStatusCB).run(func(cb StatusCallback) error {
+		return a.uploadOrUpdateFileWithTag(localpath, remotepath, cb, isUpdate, thumbnailpath,
+			false, "", "", false, attrs, &recipeUpload{path: recipePath, meta: meta})
+	})
+	if err != nil {
+		if rerr := a.releaseChunks(manifest, recipe); rerr != nil {
+			Logger.Error("Releasing the chunks failed", zap.Error(rerr))
+		}
+		return errors.Wrap(err, "recipe upload failed")
+	}
+	if oldRecipe != nil {
+		if err = a.releaseChunks(manifest, oldRecipe); err != nil {
+			return err
+		}
+	}
+
+	if status != nil {
+		var mimetype string
+		if f, err := os.Open(localpath); err == nil {
+			mimetype, _ = zboxutil.GetFileContentType(f)
+			f.Close()
+		}
+		status.Completed(a.ID, remotepath, pathpkg.Base(remotepath), mimetype, int(meta.Size), OpUpload)
+	}
+	return nil
+}
+
+// uploadChunk uploads the chunk missing from the manifest.
+func (a *Allocation) uploadChunk(tmpDir, hash string, chunk []byte) error {
+	chunkPath := filepath.Join(tmpDir, hash)
+	if err := ioutil.WriteFile(chunkPath, chunk, 0600); err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.Remove(chunkPath)
+	err := new(waitStatusCB).run(func(cb StatusCallback) error {
+		return a.UploadFile(chunkPath, dedup.ChunkPath(hash), fileref.Attributes{}, cb)
+	})
+	if err != nil {
+		// stored by an upload whose manifest was not saved
+		if _, merr := a.GetFileMeta(dedup.ChunkPath(hash)); merr == nil {
+			return nil
+		}
+		return errors.Wrap(err, "chunk upload failed")
+	}
+	return nil
+}
+
+// chunkFile splits the file into chunks, passed to onChunk when not nil.
+func chunkFile(localpath string, onChunk func(hash string, chunk []byte) error) (*dedup.Recipe, *dedup.Meta, error) {
+	f, err := os.Open(localpath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Local file error")
+	}
+	defer f.Close()
+
+	var (
+		recipe = &dedup.Recipe{}
+		meta   = &dedup.Meta{}
+		h      = sha1.New()
+	)
+	c := dedup.NewChunker(f)
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Local file error")
+		}
+		hash := dedup.ChunkHash(chunk)
+		h.Write(chunk)
+		meta.Size += int64(len(chunk))
+		recipe.Chunks = append(recipe.Chunks, dedup.Chunk{Hash: hash, Size: int64(len(chunk))})
+		if onChunk != nil {
+			if err = onChunk(hash, chunk); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	meta.Hash = hex.EncodeToString(h.Sum(nil))
+	return recipe, meta, nil
+}
+
+// writeRecipe writes the recipe to a new file of the directory.
+func writeRecipe(dir string, recipe *dedup.Recipe) (string, error) {
+	data, err := json.Marshal(recipe)
+	if err != nil {
+		return "", errors.Wrap(err, "recipe encoding failed")
+	}
+	f, err := ioutil.TempFile(dir, "recipe")
+	if err != nil {
+		return "", errors.Wrap(err, "Local file error")
+	}
+	defer f.Close()
+	if _, err = f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrap(err, "Local file error")
+	}
+	return f.Name(), nil
+}
+
+// writeRecipeOf writes the recipe of the file to a temporary file, the
+// same as the one uploaded with it.
+func writeRecipeOf(localpath string) (string, error) {
+	recipe, _, err := chunkFile(localpath, nil)
+	if err != nil {
+		return "", err
+	}
+	return writeRecipe("", recipe)
+}
+
+// downloadRaw downloads the file as stored, recipes are not reassembled.
+func (a *Allocation) downloadRaw(tmpDir, remotePath string) ([]byte, error) {
+	dir, err := ioutil.TempDir(tmpDir, "download")
+	if err != nil {
+		return nil, errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(dir)
+	localPath := filepath.Join(dir, "content")
+	err = new(waitStatusCB).run(func(cb StatusCallback) error {
+		return a.downloadFileRange(localPath, remotePath, DOWNLOAD_CONTENT_FULL, 1, 0, numBlockDownloads,
+			0, 0, true, cb)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "download failed")
+	}
+	return ioutil.ReadFile(localPath)
+}
+
+// manifestHash returns the hash of the manifest of the allocation, empty if
+// the blobbers answer there is none.
+func (a *Allocation) manifestHash() (string, error) {
+	if !a.isInitialized() {
+		return "", notInitialized
+	}
+	listReq := &ListRequest{}
+	listReq.allocationID = a.ID
+	listReq.allocationTx = a.Tx
+	listReq.blobbers = a.Blobbers
+	listReq.consensusThresh = (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
+	listReq.fullconsensus = float32(a.DataShards + a.ParityShards)
+	listReq.ctx = a.ctx
+	remotePath, err := a.remotePath(dedup.ManifestPath)
+	if err != nil {
+		return "", err
+	}
+	listReq.remotefilepath = remotePath
+	lR := listReq.getFileMetaFromBlobbers()
+	if _, ref, _ := listReq.fileConsensus(lR); ref != nil {
+		return ref.ActualFileHash, nil
+	}
+	if listReq.isFileNotFound(lR) {
+		return "", nil
+	}
+	return "", errors.New("dedup_error", "Error getting the manifest meta data from blobbers")
+}
+
+// loadManifest downloads the manifest of the allocation, a new one if
+// there is none yet.
+func (a *Allocation) loadManifest(tmpDir string) (*manifestFile, error) {
+	m := &manifestFile{Manifest: dedup.NewManifest(), dir: tmpDir}
+	hash, err := a.manifestHash()
+	if err != nil || hash == "" {
+		return m, err
+	}
+	data, err := a.downloadRaw(tmpDir, dedup.ManifestPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "manifest download failed")
+	}
+	if err = json.Unmarshal(data, m.Manifest); err != nil {
+		return nil, errors.Wrap(err, "invalid manifest")
+	}
+	m.exists = true
+	m.hash = hash
+	return m, nil
+}
+
+// updateManifest applies the change to the manifest and saves it. If the
+// manifest was changed meanwhile, the change is applied again to the
+// manifest reloaded.
+func (a *Allocation) updateManifest(m *manifestFile, change func(m *manifestFile) error) error {
+	for i := 0; ; i++ {
+		if err := change(m); err != nil {
+			return err
+		}
+		err := a.saveManifest(m)
+		if err != errManifestChanged || i == maxManifestUpdates-1 {
+			return err
+		}
+		reloaded, err := a.loadManifest(m.dir)
+		if err != nil {
+			return err
+		}
+		*m = *reloaded
+	}
+}
+
+// saveManifest saves the manifest if it is still the one loaded, else
+// returns errManifestChanged.
+func (a *Allocation) saveManifest(m *manifestFile) error {
+	data, err := json.Marshal(m.Manifest)
+	if err != nil {
+		return errors.Wrap(err, "manifest encoding failed")
+	}
+	hash, err := a.manifestHash()
+	if err != nil {
+		return err
+	}
+	if hash != m.hash {
+		return errManifestChanged
+	}
+	localPath := filepath.Join(m.dir, "manifest")
+	if err = ioutil.WriteFile(localPath, data, 0600); err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.Remove(localPath)
+	err = new(waitStatusCB).run(func(cb StatusCallback) error {
+		if m.exists {
+			return a.UpdateFile(localPath, dedup.ManifestPath, fileref.Attributes{}, cb)
+		}
+		return a.UploadFile(localPath, dedup.ManifestPath, fileref.Attributes{}, cb)
+	})
+	if err != nil {
+		return errors.Wrap(err, "manifest upload failed")
+	}
+	h := sha1.Sum(data)
+	m.exists = true
+	m.hash = hex.EncodeToString(h[:])
+	return nil
+}
+
+// lockManifest takes the lock of the manifest and returns its release. The
+// lock is created by an upload, rejected by the blobbers while the lock
+// exists, and read back to check that it is the one of this client.
+func (a *Allocation) lockManifest(tmpDir string) (func(), error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.Wrap(err, "lock token generation failed")
+	}
+	lock := &dedupLock{
+		ClientID: client.GetClientID(),
+		Token:    hex.EncodeToString(token),
+		Expires:  time.Now().Add(dedupLockTTL).Unix(),
+	}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, errors.Wrap(err, "lock encoding failed")
+	}
+	localPath := filepath.Join(tmpDir, "lock")
+	if err = ioutil.WriteFile(localPath, data, 0600); err != nil {
+		return nil, errors.Wrap(err, "Local file error")
+	}
+	defer os.Remove(localPath)
+	upload := func() error {
+		return new(waitStatusCB).run(func(cb StatusCallback) error {
+			return a.UploadFile(localPath, dedup.LockPath, fileref.Attributes{}, cb)
+		})
+	}
+
+	if err = upload(); err != nil {
+		held, rerr := a.loadLock(tmpDir)
+		if rerr != nil || held == nil {
+			return nil, errors.Wrap(err, "lock upload failed")
+		}
+		if time.Now().Unix() < held.Expires {
+			return nil, errDedupLocked
+		}
+		Logger.Info("Taking over an expired dedup lock", zap.String("client_id", held.ClientID))
+		if err = a.deletePath(dedup.LockPath); err != nil {
+			return nil, errors.Wrap(err, "expired lock delete failed")
+		}
+		if err = upload(); err != nil {
+			return nil, errDedupLocked
+		}
+	}
+	if held, err := a.loadLock(tmpDir); err != nil || held == nil || held.Token != lock.Token {
+		return nil, errDedupLocked
+	}
+	return func() {
+		if err := a.deletePath(dedup.LockPath); err != nil {
+			Logger.Error("Releasing the dedup lock failed", zap.Error(err))
+		}
+	}, nil
+}
+
+// loadLock downloads the lock of the manifest, nil if there is none.
+func (a *Allocation) loadLock(tmpDir string) (*dedupLock, error) {
+	if _, err := a.GetFileMeta(dedup.LockPath); err != nil {
+		return nil, nil
+	}
+	data, err := a.downloadRaw(tmpDir, dedup.LockPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "lock download failed")
+	}
+	lock := &dedupLock{}
+	if err = json.Unmarshal(data, lock); err != nil {
+		return nil, errors.Wrap(err, "invalid lock")
+	}
+	return lock, nil
+}
+
+// RepairDeduplication recounts the references of the chunks from the
+// recipes of the deduplicated files and deletes the chunks no file uses.
+func (a *Allocation) RepairDeduplication() error {
+	if !a.IsDeduplicated() {
+		return errors.New("dedup_error", "Deduplication is not enabled")
+	}
+	a.dedupMutex.Lock()
+	defer a.dedupMutex.Unlock()
+
+	tmpDir, err := ioutil.TempDir("", "zbox-dedup")
+	if err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	unlock, err := a.lockManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	manifest, err := a.loadManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	recipes, err := a.collectRecipes(tmpDir, "/")
+	if err != nil {
+		return err
+	}
+	list, err := a.ListDir(dedup.ChunksDir)
+	if err != nil {
+		return err
+	}
+	stored := make([]string, 0, len(list.Children))
+	for _, child := range list.Children {
+		stored = append(stored, child.Name)
+	}
+	var unreferenced []string
+	err = a.updateManifest(manifest, func(m *manifestFile) error {
+		m.Manifest = dedup.NewManifest()
+		for _, recipe := range recipes {
+			m.AddRefs(recipe)
+		}
+		unreferenced = m.Unreferenced(stored)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, hash := range unreferenced {
+		if err := a.deletePath(dedup.ChunkPath(hash)); err != nil {
+			Logger.Error("Deleting the chunk failed", zap.String("chunk", hash), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// loadRecipe downloads the recipe of the file, nil if the file is not
+// deduplicated.
+func (a *Allocation) loadRecipe(tmpDir, path string) (*dedup.Recipe, error) {
+	meta, err := a.GetFileMeta(path)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.Dedup {
+		return nil, nil
+	}
+	data, err := a.downloadRaw(tmpDir, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "recipe download failed")
+	}
+	recipe := &dedup.Recipe{}
+	if err = json.Unmarshal(data, recipe); err != nil {
+		return nil, errors.Wrap(err, "invalid recipe")
+	}
+	return recipe, nil
+}
+
+// collectRecipes returns the recipes of the deduplicated files of the path.
+func (a *Allocation) collectRecipes(tmpDir, path string) ([]*dedup.Recipe, error) {
+	meta, err := a.GetFileMeta(path)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Type == fileref.FILE {
+		recipe, err := a.loadRecipe(tmpDir, path)
+		if err != nil || recipe == nil {
+			return nil, err
+		}
+		return []*dedup.Recipe{recipe}, nil
+	}
+	list, err := a.ListDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var recipes []*dedup.Recipe
+	for _, child := range list.Children {
+		if child.Type != fileref.DIRECTORY && !child.Dedup || isDedupPath(child.Path) {
+			continue
+		}
+		childRecipes, err := a.collectRecipes(tmpDir, child.Path)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, childRecipes...)
+	}
+	return recipes, nil
+}
+
+// releaseChunks dereferences the chunks of the recipes and deletes the
+// ones no longer referenced.
+func (a *Allocation) releaseChunks(m *manifestFile, recipes ...*dedup.Recipe) error {
+	var unreferenced []string
+	err := a.updateManifest(m, func(m *manifestFile) error {
+		unreferenced = nil
+		for _, recipe := range recipes {
+			unreferenced = append(unreferenced, m.RemoveRefs(recipe)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, hash := range unreferenced {
+		// out of the manifest, a chunk left is only wasted space
+		if err := a.deletePath(dedup.ChunkPath(hash)); err != nil {
+			Logger.Error("Deleting the chunk failed", zap.String("chunk", hash), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (a *Allocation) deleteDeduplicated(path string) error {
+	a.dedupMutex.Lock()
+	defer a.dedupMutex.Unlock()
+
+	tmpDir, err := ioutil.TempDir("", "zbox-dedup")
+	if err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	unlock, err := a.lockManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	manifest, err := a.loadManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	if !manifest.exists {
+		return a.deletePath(path)
+	}
+	recipes, err := a.collectRecipes(tmpDir, path)
+	if err != nil {
+		return err
+	}
+	if err = a.deletePath(path); err != nil {
+		return err
+	}
+	if len(recipes) == 0 {
+		return nil
+	}
+	return a.releaseChunks(manifest, recipes...)
+}
+
+func (a *Allocation) copyDeduplicated(path, destPath string) error {
+	a.dedupMutex.Lock()
+	defer a.dedupMutex.Unlock()
+
+	tmpDir, err := ioutil.TempDir("", "zbox-dedup")
+	if err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	unlock, err := a.lockManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	manifest, err := a.loadManifest(tmpDir)
+	if err != nil {
+		return err
+	}
+	if err = a.copyPath(path, destPath); err != nil || !manifest.exists {
+		return err
+	}
+	recipes, err := a.collectRecipes(tmpDir, pathpkg.Join(destPath, pathpkg.Base(path)))
+	if err != nil {
+		return err
+	}
+	if len(recipes) == 0 {
+		return nil
+	}
+	return a.updateManifest(manifest, func(m *manifestFile) error {
+		for _, recipe := range recipes {
+			m.AddRefs(recipe)
+		}
+		return nil
+	})
+}
+
+// reassemble writes the bytes [offset, offset+length) of the file of the
+// recipe, the whole file if length is 0, from its chunks.
+func (a *Allocation) reassemble(recipePath, localPath string, offset, length int64) (int64, error) {
+	data, err := ioutil.ReadFile(recipePath)
+	if err != nil {
+		return 0, errors.Wrap(err, "Local file error")
+	}
+	recipe := &dedup.Recipe{}
+	if err = json.Unmarshal(data, recipe); err != nil {
+		return 0, errors.Wrap(err, "invalid recipe")
+	}
+	if length == 0 {
+		offset = 0
+		for _, c := range recipe.Chunks {
+			length += c.Size
+		}
+	}
+
+	tmpDir, err := ioutil.TempDir("", "zbox-dedup")
+	if err != nil {
+		return 0, errors.Wrap(err, "Local file error")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	out, err := os.Create(localPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "Local file error")
+	}
+	defer out.Close()
+
+	chunks, pos := recipe.Range(offset, length)
+	var written int64
+	for i, c := range chunks {
+		chunk, err := a.downloadRaw(tmpDir, dedup.ChunkPath(c.Hash))
+		if err != nil {
+			return 0, errors.Wrap(err, "chunk "+strconv.Itoa(i)+" download failed")
+		}
+		if dedup.ChunkHash(chunk) != c.Hash {
+			return 0, errors.New("dedup_error", "Chunk hash mismatch "+c.Hash)
+		}
+		n, err := out.Write(chunkSlice(chunk, pos, offset, length))
+		if err != nil {
+			return 0, errors.Wrap(err, "Local file error")
+		}
+		written += int64(n)
+		pos += c.Size
+	}
+	return written, nil
+}
+
+// chunkSlice returns the bytes of the chunk at pos in [offset, offset+length).
+func chunkSlice(chunk []byte, pos, offset, length int64) []byte {
+	start, end := offset-pos, offset+length-pos
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(chunk)) {
+		end = int64(len(chunk))
+	}
+	return chunk[start:end]
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/dedup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestChunkFile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data := make([]byte, 3*dedup.MaxChunkSize)
+	rand.New(rand.NewSource(1)).Read(data)
+	localPath := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(localPath, data, 0644))
+
+	var joined []byte
+	recipe, meta, err := chunkFile(localPath, func(hash string, chunk []byte) error {
+		require.Equal(t, dedup.ChunkHash(chunk), hash)
+		joined = append(joined, chunk...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, data, joined)
+	require.True(t, len(recipe.Chunks) > 1)
+	h := sha1.Sum(data)
+	require.Equal(t, &dedup.Meta{Size: int64(len(data)), Hash: hex.EncodeToString(h[:])}, meta)
+
+	// the recipe uploaded again on repair is the same
+	recipePath, err := writeRecipeOf(localPath)
+	require.NoError(t, err)
+	defer os.Remove(recipePath)
+	written, err := ioutil.ReadFile(recipePath)
+	require.NoError(t, err)
+	expected, err := json.Marshal(recipe)
+	require.NoError(t, err)
+	require.Equal(t, expected, written)
+}
+
+func TestChunkSlice(t *testing.T) {
+	chunk := []byte("0123456789")
+	require.Equal(t, []byte("0123456789"), chunkSlice(chunk, 10, 0, 100))
+	require.Equal(t, []byte("56789"), chunkSlice(chunk, 10, 15, 100))
+	require.Equal(t, []byte("012"), chunkSlice(chunk, 10, 5, 8))
+	require.Equal(t, []byte("34"), chunkSlice(chunk, 10, 13, 2))
+}
@@ -1,7 +1,6 @@
 package sdk
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
@@ -9,12 +8,11 @@ import (
 	"io"
 	"math"
 	"os"
-	"strings"
 	"sync"
 
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
-	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/compression"
 	"github.com/0chain/gosdk/zboxcore/encoder"
 	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/fileref"
@@ -47,13 +45,44 @@ type DownloadRequest struct {
 	wg                 *sync.WaitGroup
 	downloadMask       zboxutil.Uint128
 	encryptedKey       string
+	encryptionVersion  int
+	chunksPerShard     int64
+	rangeOffset        int64
+	rangeLength        int64
+	reassemble         func(recipePath, localPath string, offset, length int64) (int64, error)
 	isDownloadCanceled bool
 	completedCallback  func(remotepath string, remotepathhash string)
 	contentMode        string
+	// writer receives the stored content instead of the local file, it
+	// is neither extracted nor reassembled.
+	writer io.Writer
 	Consensus
 }
 
 func (req *DownloadRequest) downloadBlock(blockNum int64, blockChunksMax int) ([]byte, error) {
+	firstChunk := blockNum - 1
+	var encscheme encryption.EncryptionScheme
+	var reEncryptionKey string
+	if len(req.encryptedKey) > 0 {
+		var err error
+		if req.authTicket != nil {
+			reEncryptionKey = req.authTicket.ReEncryptionKey
+			keyID, _, _ := encryption.ParseEncryptedKey(req.encryptedKey)
+			if reKey, ok := req.authTicket.ReEncryptionKeys[keyID]; ok {
+				reEncryptionKey = reKey
+			}
+			// re-encrypted for the key of the client's encrypted public key
+			encscheme, err = recipientScheme(req.authTicket.EncryptionKeyID)
+		} else {
+			encscheme, err = decryptionScheme(req.encryptedKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = encscheme.InitForDecryption(encryption.FileTag(req.encryptedKey), req.encryptedKey); err != nil {
+			return nil, err
+		}
+	}
 	req.consensus = 0
 	numDownloads := req.downloadMask.CountOnes()
 	req.wg = &sync.WaitGroup{}
@@ -90,13 +119,6 @@ func (req *DownloadRequest) downloadBlock(blockNum int64, blockChunksMax int) ([
 	//shards := make([][]byte, len(req.blobbers))
 	decodeLen := make([]int, req.numBlocks)
 	var decodeNumBlocks int
-	var encscheme encryption.EncryptionScheme
-	if len(req.encryptedKey) > 0 {
-		encscheme = encryption.NewEncryptionScheme()
-		// TODO: Remove after testing
-		encscheme.Initialize(client.GetClient().Mnemonic)
-		encscheme.InitForDecryption("filetype:audio", req.encryptedKey)
-	}
 
 	retData := make([]byte, 0)
 	success := 0
@@ -116,22 +138,14 @@ func (req *DownloadRequest) downloadBlock(blockNum int64, blockChunksMax int) ([
 			//for blockNum := 0; blockNum < len(result.BlockChunks); blockNum++ {
 			for blockNum := 0; blockNum < downloadChunks; blockNum++ {
 				if len(req.encryptedKey) > 0 {
-					headerBytes := result.BlockChunks[blockNum][:(2 * 1024)]
-					headerBytes = bytes.Trim(headerBytes, "\x00")
-					headerString := string(headerBytes)
-					encMsg := &encryption.EncryptedMessage{}
-					encMsg.EncryptedData = result.BlockChunks[blockNum][(2 * 1024):]
-					headerChecksums := strings.Split(headerString, ",")
-					if len(headerChecksums) != 2 {
-						Logger.Error("Block has invalid header", req.blobbers[result.idx].Baseurl)
-						break
-					}
-					encMsg.MessageChecksum, encMsg.OverallChecksum = headerChecksums[0], headerChecksums[1]
-					encMsg.EncryptedKey = encscheme.GetEncryptedKey()
-					if req.authTicket != nil {
-						encMsg.ReEncryptionKey = req.authTicket.ReEncryptionKey
-					}
-					decryptedBytes, err := encscheme.Decrypt(encMsg)
+					chunk := firstChunk + int64(blockNum)
+					decryptedBytes, err := encryption.DecryptChunk(encscheme, req.encryptionVersion,
+						result.BlockChunks[blockNum], encryption.ChunkPosition{
+							Shard:     result.idx,
+							Index:     chunk,
+							Final:     chunk == req.chunksPerShard-1,
+							Thumbnail: req.contentMode == DOWNLOAD_CONTENT_THUMB,
+						}, reEncryptionKey)
 					if err != nil {
 						Logger.Error("Block decryption failed", req.blobbers[result.idx].Baseurl, err)
 						break
@@ -204,28 +218,74 @@ func (req *DownloadRequest) processDownload(ctx context.Context) {
 		size = fileRef.ActualThumbnailSize
 	}
 	req.encryptedKey = fileRef.EncryptedKey
+	req.encryptionVersion = encryption.FileChunkVersion(fileRef.EncryptedKey)
 	Logger.Info("Encrypted key from fileref", req.encryptedKey)
 	// Calculate number of bytes per shard.
 	perShard := (size + int64(req.datashards) - 1) / int64(req.datashards)
-	chunkSizeWithHeader := int64(fileref.CHUNK_SIZE)
+	var chunkOverhead int64
 	if len(fileRef.EncryptedKey) > 0 {
-		chunkSizeWithHeader -= 16
-		chunkSizeWithHeader -= 2 * 1024
+		chunkOverhead = encryption.ChunkOverhead(req.encryptionVersion)
 	}
+	chunkSizeWithHeader := int64(fileref.CHUNK_SIZE) - chunkOverhead
 	chunksPerShard := (perShard + chunkSizeWithHeader - 1) / chunkSizeWithHeader
-	if len(fileRef.EncryptedKey) > 0 {
-		perShard += chunksPerShard * (16 + (2 * 1024))
-	}
+	perShard += chunksPerShard * chunkOverhead
+	req.chunksPerShard = chunksPerShard
 
-	wrFile, err := os.OpenFile(req.localpath, os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
+	// compressed files and ranges are downloaded to a part file, then
+	// extracted to the local path
+	storedPath := req.localpath
+	var storedOffset int64
+	completedSize := fileRef.ActualFileSize
+	dedupMeta := dedupMetaOf(fileRef)
+	if dedupMeta != nil && req.reassemble == nil && req.authTicket != nil {
 		if req.statusCallback != nil {
-			Logger.Error(err.Error())
-			req.statusCallback.Error(req.allocationID, remotePathCallback, OpDownload, errors.Wrap(err, "Can't create local file"))
+			req.statusCallback.Error(req.allocationID, remotePathCallback, OpDownload,
+				errors.New("dedup_error", "Deduplicated files can't be downloaded with an auth ticket"))
 		}
 		return
 	}
-	defer wrFile.Close()
+	reassemble := req.writer == nil && req.contentMode != DOWNLOAD_CONTENT_THUMB && dedupMeta != nil && req.reassemble != nil
+	extract := req.writer == nil && req.contentMode != DOWNLOAD_CONTENT_THUMB && (compressionMetaOf(fileRef) != nil || req.rangeLength > 0) || reassemble
+	if extract {
+		start, end, err := req.storedRange(fileRef)
+		if err != nil {
+			if req.statusCallback != nil {
+				req.statusCallback.Error(req.allocationID, remotePathCallback, OpDownload, err)
+			}
+			return
+		}
+		blockSize := chunkSizeWithHeader * int64(req.datashards)
+		req.startBlock = start / blockSize
+		req.endBlock = (end + blockSize - 1) / blockSize
+		storedOffset = req.startBlock * blockSize
+		storedPath = req.localpath + ".part"
+		defer os.Remove(storedPath)
+		if meta := compressionMetaOf(fileRef); meta != nil {
+			completedSize = meta.Size
+		}
+		if dedupMeta != nil {
+			completedSize = dedupMeta.Size
+		}
+		if req.rangeLength > 0 {
+			completedSize = req.rangeLength
+		}
+	}
+
+	var wrFile *os.File
+	var err error
+	out := req.writer
+	if out == nil {
+		wrFile, err = os.OpenFile(storedPath, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			if req.statusCallback != nil {
+				Logger.Error(err.Error())
+				req.statusCallback.Error(req.allocationID, remotePathCallback, OpDownload, errors.Wrap(err, "Can't create local file"))
+			}
+			return
+		}
+		defer wrFile.Close()
+		out = wrFile
+	}
 	req.isDownloadCanceled = false
 	if req.statusCallback != nil {
 		req.statusCallback.Started(req.allocationID, remotePathCallback, OpDownload, int(size))
@@ -240,7 +300,7 @@ func (req *DownloadRequest) processDownload(ctx context.Context) {
 
 	downloaded := int(0)
 	fH := sha1.New()
-	mW := io.MultiWriter(fH, wrFile)
+	mW := io.MultiWriter(fH, out)
 
 	startBlock := req.startBlock
 	endBlock := req.endBlock
@@ -312,14 +372,86 @@ func (req *DownloadRequest) processDownload(ctx context.Context) {
 		}
 	}
 
-	wrFile.Sync()
-	wrFile.Close()
-	wrFile, _ = os.Open(req.localpath)
-	defer wrFile.Close()
-	wrFile.Seek(0, 0)
-	mimetype, _ := zboxutil.GetFileContentType(wrFile)
+	mimetype := fileRef.MimeType
+	if wrFile != nil {
+		wrFile.Sync()
+		wrFile.Close()
+		if extract {
+			if reassemble {
+				// the recipe was downloaded, the file is its chunks
+				completedSize, err = req.reassemble(storedPath, req.localpath, req.rangeOffset, req.rangeLength)
+			} else {
+				err = req.extract(storedPath, storedOffset, fileRef)
+			}
+			if err != nil {
+				os.Remove(req.localpath)
+				if req.statusCallback != nil {
+					req.statusCallback.Error(req.allocationID, remotePathCallback, OpDownload, errors.Wrap(err, "Extracting the file failed"))
+				}
+				return
+			}
+		}
+		wrFile, _ = os.Open(req.localpath)
+		defer wrFile.Close()
+		wrFile.Seek(0, 0)
+		mimetype, _ = zboxutil.GetFileContentType(wrFile)
+	}
 	if req.statusCallback != nil {
-		req.statusCallback.Completed(req.allocationID, remotePathCallback, fileRef.Name, mimetype, int(fileRef.ActualFileSize), OpDownload)
+		req.statusCallback.Completed(req.allocationID, remotePathCallback, fileRef.Name, mimetype, int(completedSize), OpDownload)
 	}
 	return
 }
+
+// storedRange returns the range of the uploaded bytes holding the bytes
+// requested.
+func (req *DownloadRequest) storedRange(fileRef *fileref.FileRef) (start, end int64, err error) {
+	meta := compressionMetaOf(fileRef)
+	switch {
+	case req.rangeLength == 0 || dedupMetaOf(fileRef) != nil:
+		return 0, fileRef.ActualFileSize, nil
+	case meta != nil:
+		return meta.Range(req.rangeOffset, req.rangeLength)
+	case req.rangeOffset+req.rangeLength > fileRef.ActualFileSize:
+		return 0, 0, errors.New("invalid_range", "range out of the file")
+	}
+	return req.rangeOffset, req.rangeOffset + req.rangeLength, nil
+}
+
+// extract writes the bytes requested to the local path from the part file,
+// downloaded from the stored offset, decompressing them.
+func (req *DownloadRequest) extract(storedPath string, storedOffset int64, fileRef *fileref.FileRef) error {
+	inFile, err := os.Open(storedPath)
+	if err != nil {
+		return err
+	}
+	defer inFile.Close()
+	outFile, err := os.OpenFile(req.localpath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	offset, length := req.rangeOffset, req.rangeLength
+	meta := compressionMetaOf(fileRef)
+	if meta == nil {
+		if _, err = inFile.Seek(offset-storedOffset, io.SeekStart); err != nil {
+			return err
+		}
+		_, err = io.CopyN(outFile, inFile, length)
+		return err
+	}
+	if length == 0 {
+		if meta.Size == 0 {
+			return nil
+		}
+		offset, length = 0, meta.Size
+	}
+	start, _, err := meta.Range(offset, length)
+	if err != nil {
+		return err
+	}
+	if _, err = inFile.Seek(start-storedOffset, io.SeekStart); err != nil {
+		return err
+	}
+	return compression.Decompress(meta, inFile, outFile, offset, length)
+}
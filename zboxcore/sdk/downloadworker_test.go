@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/compression"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadRequest_extract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDownloadRequest_extract")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	var compressed bytes.Buffer
+	meta, err := compression.Compress(compression.Zstd, bytes.NewReader(data), &compressed, 4096)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		ref    *fileref.FileRef
+		stored []byte
+		offset int64
+		length int64
+	}{
+		{
+			name:   "Test_Compressed_Full",
+			ref:    &fileref.FileRef{ActualFileSize: int64(compressed.Len()), CustomMeta: customMetaOf(t, meta, nil)},
+			stored: compressed.Bytes(),
+			length: 0,
+		},
+		{
+			name:   "Test_Compressed_Range",
+			ref:    &fileref.FileRef{ActualFileSize: int64(compressed.Len()), CustomMeta: customMetaOf(t, meta, nil)},
+			stored: compressed.Bytes(),
+			offset: 5000,
+			length: 4000,
+		},
+		{
+			name:   "Test_Range",
+			ref:    &fileref.FileRef{ActualFileSize: int64(len(data))},
+			stored: data,
+			offset: 100,
+			length: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &DownloadRequest{
+				localpath:   filepath.Join(dir, tt.name),
+				rangeOffset: tt.offset,
+				rangeLength: tt.length,
+			}
+			start, end, err := req.storedRange(tt.ref)
+			require.NoError(t, err)
+			// the part file starts at a block boundary before the range
+			storedOffset := start / 1024 * 1024
+			storedPath := req.localpath + ".part"
+			require.NoError(t, ioutil.WriteFile(storedPath, tt.stored[storedOffset:end], 0644))
+
+			require.NoError(t, req.extract(storedPath, storedOffset, tt.ref))
+			got, err := ioutil.ReadFile(req.localpath)
+			require.NoError(t, err)
+			if tt.length == 0 {
+				require.Equal(t, data, got)
+			} else {
+				require.Equal(t, data[tt.offset:tt.offset+tt.length], got)
+			}
+		})
+	}
+
+	req := &DownloadRequest{rangeOffset: int64(len(data)) - 1, rangeLength: 2}
+	_, _, err = req.storedRange(&fileref.FileRef{ActualFileSize: int64(len(data))})
+	require.Error(t, err)
+}
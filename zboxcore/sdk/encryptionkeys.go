@@ -0,0 +1,156 @@
+package sdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+)
+
+var encryptionKeyring *encryption.Keyring
+
+// SetEncryptionKeyring sets the keyring files are encrypted and decrypted
+// with, instead of the key derived from the wallet mnemonic. Import the
+// mnemonic key in it to decrypt the files uploaded before. A nil keyring
+// goes back to the mnemonic key.
+func SetEncryptionKeyring(kr *encryption.Keyring) {
+	encryptionKeyring = kr
+}
+
+// GetEncryptionKeyring returns the keyring set with SetEncryptionKeyring.
+func GetEncryptionKeyring() *encryption.Keyring {
+	return encryptionKeyring
+}
+
+// encryptionScheme returns the scheme new files are encrypted with and
+// the ID of its key, empty for the mnemonic key.
+func encryptionScheme() (encryption.EncryptionScheme, string, error) {
+	if encryptionKeyring != nil {
+		if key := encryptionKeyring.Current(); key != nil {
+			encscheme, err := key.Scheme()
+			if err != nil {
+				return nil, "", err
+			}
+			if key.Mnemonic {
+				return encscheme, "", nil
+			}
+			return encscheme, key.ID, nil
+		}
+	}
+	encscheme, err := mnemonicEncryptionScheme()
+	return encscheme, "", err
+}
+
+// decryptionSchemes returns the schemes of the keyring keys and of the
+// wallet mnemonic key by the key ID files record in their encrypted keys.
+func decryptionSchemes() (map[string]encryption.EncryptionScheme, error) {
+	schemes := make(map[string]encryption.EncryptionScheme)
+	if encryptionKeyring != nil {
+		for _, key := range encryptionKeyring.Keys {
+			encscheme, err := key.Scheme()
+			if err != nil {
+				return nil, err
+			}
+			if key.Mnemonic {
+				schemes[""] = encscheme
+			} else {
+				schemes[key.ID] = encscheme
+			}
+		}
+	}
+	if _, ok := schemes[""]; !ok {
+		if encscheme, err := mnemonicEncryptionScheme(); err == nil {
+			schemes[""] = encscheme
+		}
+	}
+	if len(schemes) == 0 {
+		return nil, errors.New("encryption_key_not_found", "no encryption keyring and no wallet mnemonic")
+	}
+	return schemes, nil
+}
+
+// recipientScheme returns the scheme of the key an auth ticket was
+// generated for, its encryption public key. Tickets without a key ID were
+// generated for the current key.
+func recipientScheme(keyID string) (encryption.EncryptionScheme, error) {
+	if keyID == "" {
+		encscheme, _, err := encryptionScheme()
+		return encscheme, err
+	}
+	if encryptionKeyring != nil {
+		if key, err := encryptionKeyring.Key(keyID); err == nil {
+			return key.Scheme()
+		}
+	}
+	encscheme, err := mnemonicEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := encscheme.GetPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if id, _ := encryption.KeyIDOf(publicKey); id != keyID {
+		return nil, errors.New("encryption_key_not_found", "no encryption key "+keyID)
+	}
+	return encscheme, nil
+}
+
+// decryptionScheme returns the scheme of the key the encrypted key of a
+// file was encrypted with.
+func decryptionScheme(encryptedKey string) (encryption.EncryptionScheme, error) {
+	keyID, _, _ := encryption.ParseEncryptedKey(encryptedKey)
+	if encryptionKeyring != nil {
+		key, err := encryptionKeyring.Key(keyID)
+		if err == nil {
+			return key.Scheme()
+		}
+		if keyID != "" {
+			return nil, err
+		}
+	}
+	if keyID != "" {
+		return nil, errors.New("encryption_key_not_found", "no keyring with the encryption key "+keyID)
+	}
+	return mnemonicEncryptionScheme()
+}
+
+func mnemonicEncryptionScheme() (encryption.EncryptionScheme, error) {
+	mnemonic := client.GetClient().Mnemonic
+	if mnemonic == "" {
+		return nil, errors.New("encryption_key_not_found", "no encryption keyring and no wallet mnemonic")
+	}
+	encscheme := encryption.NewEncryptionScheme()
+	if err := encscheme.Initialize(mnemonic); err != nil {
+		return nil, err
+	}
+	return encscheme, nil
+}
+
+// nameEncryptionKey returns the key the names of the allocation are
+// encrypted with, derived from the name key of the keyring, or from the
+// wallet mnemonic.
+func nameEncryptionKey(allocationID string) ([]byte, error) {
+	var master []byte
+	if encryptionKeyring != nil && encryptionKeyring.NameKey != "" {
+		key, err := hex.DecodeString(encryptionKeyring.NameKey)
+		if err != nil {
+			return nil, errors.New("invalid_keyring", "invalid name key")
+		}
+		master = key
+	} else {
+		mnemonic := client.GetClient().Mnemonic
+		if mnemonic == "" {
+			return nil, errors.New("encryption_key_not_found", "no encryption keyring and no wallet mnemonic")
+		}
+		mac := hmac.New(sha256.New, []byte("zbox name encryption"))
+		mac.Write([]byte(mnemonic))
+		master = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(allocationID))
+	return mac.Sum(nil), nil
+}
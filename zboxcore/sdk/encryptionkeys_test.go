@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEncryptionTestClient(t *testing.T) *zcncrypto.Wallet {
+	w, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	wStr, err := w.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, client.PopulateClient(wStr, "bls0chain"))
+	return w
+}
+
+func TestRecipientScheme(t *testing.T) {
+	defer SetEncryptionKeyring(GetEncryptionKeyring())
+	w := setupEncryptionTestClient(t)
+
+	publicKeyOf := func(encscheme encryption.EncryptionScheme) string {
+		publicKey, err := encscheme.GetPublicKey()
+		require.NoError(t, err)
+		return publicKey
+	}
+
+	kr, err := encryption.NewKeyring()
+	require.NoError(t, err)
+	require.NoError(t, kr.ImportMnemonic(w.Mnemonic))
+	SetEncryptionKeyring(kr)
+	shared, _, err := encryptionScheme()
+	require.NoError(t, err)
+	sharedKeyID, err := encryption.KeyIDOf(publicKeyOf(shared))
+	require.NoError(t, err)
+
+	// the ticket keeps decrypting with its key after the keyring rotates
+	_, err = kr.Rotate()
+	require.NoError(t, err)
+	encscheme, err := recipientScheme(sharedKeyID)
+	require.NoError(t, err)
+	require.Equal(t, publicKeyOf(shared), publicKeyOf(encscheme))
+	current, _, err := encryptionScheme()
+	require.NoError(t, err)
+	encscheme, err = recipientScheme("")
+	require.NoError(t, err)
+	require.Equal(t, publicKeyOf(current), publicKeyOf(encscheme))
+
+	// tickets for the mnemonic key, with or without a keyring
+	legacy, err := mnemonicEncryptionScheme()
+	require.NoError(t, err)
+	legacyKeyID, err := encryption.KeyIDOf(publicKeyOf(legacy))
+	require.NoError(t, err)
+	for _, k := range []*encryption.Keyring{kr, nil} {
+		SetEncryptionKeyring(k)
+		encscheme, err = recipientScheme(legacyKeyID)
+		require.NoError(t, err)
+		require.Equal(t, publicKeyOf(legacy), publicKeyOf(encscheme))
+	}
+
+	_, err = recipientScheme(sharedKeyID)
+	require.Error(t, err)
+}
+
+func TestDirectoryReKeys(t *testing.T) {
+	defer SetEncryptionKeyring(GetEncryptionKeyring())
+	w := setupEncryptionTestClient(t)
+	kr, err := encryption.NewKeyring()
+	require.NoError(t, err)
+	require.NoError(t, kr.ImportMnemonic(w.Mnemonic))
+	SetEncryptionKeyring(kr)
+
+	data := []byte("directory share")
+	tag := encryption.DirectoryTag("dir lookup hash")
+	var files []*encryption.EncryptedMessage
+	var encryptedKeys []string
+	for i := 0; i < 2; i++ {
+		encscheme, keyID, err := encryptionScheme()
+		require.NoError(t, err)
+		encscheme.InitForEncryption(tag)
+		msg, err := encscheme.Encrypt(data)
+		require.NoError(t, err)
+		files = append(files, msg)
+		encryptedKeys = append(encryptedKeys, encryption.FormatEncryptedKey(keyID, encscheme.GetEncryptedKey(), tag, encryption.ChunkVersion))
+		_, err = kr.Rotate()
+		require.NoError(t, err)
+	}
+
+	reader := encryption.NewEncryptionScheme()
+	require.NoError(t, reader.Initialize("travel twenty hen negative fresh sentence hen flat swift embody increase juice eternal satisfy want vivid power transfer"))
+	readerKey, err := reader.GetPublicKey()
+	require.NoError(t, err)
+	reKeys, err := directoryReKeys(readerKey, tag)
+	require.NoError(t, err)
+	require.Len(t, reKeys, 4)
+
+	// the files encrypted before the keyring rotated are unlocked by the
+	// re-encryption key of their key
+	for i, msg := range files {
+		require.Equal(t, tag, encryption.FileTag(encryptedKeys[i]))
+		keyID, _, _ := encryption.ParseEncryptedKey(encryptedKeys[i])
+		require.NoError(t, reader.InitForDecryption(tag, encryptedKeys[i]))
+		msg.ReEncryptionKey = reKeys[kr.Current().ID]
+		_, err = reader.Decrypt(msg)
+		require.Error(t, err)
+		msg.ReEncryptionKey = reKeys[keyID]
+		got, err := reader.Decrypt(msg)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	}
+}
@@ -22,6 +22,7 @@ type fileMetaResponse struct {
 	fileref     *fileref.FileRef
 	responseStr string
 	blobberIdx  int
+	statusCode  int
 	err         error
 }
 
@@ -32,9 +33,11 @@ func (req *ListRequest) getFileMetaInfoFromBlobber(blobber *blockchain.StorageNo
 
 	var fileRef *fileref.FileRef
 	var s strings.Builder
+	var statusCode int
 	var err error
 	fileMetaRetFn := func() {
-		rspCh <- &fileMetaResponse{fileref: fileRef, responseStr: s.String(), blobberIdx: blobberIdx, err: err}
+		rspCh <- &fileMetaResponse{fileref: fileRef, responseStr: s.String(), blobberIdx: blobberIdx,
+			statusCode: statusCode, err: err}
 	}
 	defer fileMetaRetFn()
 	if len(req.remotefilepath) > 0 {
@@ -72,6 +75,7 @@ func (req *ListRequest) getFileMetaInfoFromBlobber(blobber *blockchain.StorageNo
 		}
 		Logger.Info("File Meta result:", string(resp_body))
 		s.WriteString(string(resp_body))
+		statusCode = resp.StatusCode
 		if resp.StatusCode == http.StatusOK {
 			err = json.Unmarshal(resp_body, &fileRef)
 			if err != nil {
@@ -101,7 +105,11 @@ func (req *ListRequest) getFileMetaFromBlobbers() []*fileMetaResponse {
 }
 
 func (req *ListRequest) getFileConsensusFromBlobbers() (zboxutil.Uint128, *fileref.FileRef, []*fileMetaResponse) {
-	lR := req.getFileMetaFromBlobbers()
+	return req.fileConsensus(req.getFileMetaFromBlobbers())
+}
+
+// fileConsensus returns the file ref of the responses in consensus.
+func (req *ListRequest) fileConsensus(lR []*fileMetaResponse) (zboxutil.Uint128, *fileref.FileRef, []*fileMetaResponse) {
 	var selected *fileMetaResponse
 	foundMask := zboxutil.NewUint128(0)
 	req.consensus = 0
@@ -137,3 +145,15 @@ func (req *ListRequest) getFileConsensusFromBlobbers() (zboxutil.Uint128, *filer
 	}
 	return foundMask, selected.fileref, lR
 }
+
+// isFileNotFound returns whether the blobbers answered in consensus that the
+// file does not exist. Blobbers failing to answer do not count.
+func (req *ListRequest) isFileNotFound(lR []*fileMetaResponse) bool {
+	req.consensus = 0
+	for _, r := range lR {
+		if r.err == nil && (r.statusCode == http.StatusBadRequest || r.statusCode == http.StatusNotFound) {
+			req.consensus++
+		}
+	}
+	return req.isConsensusOk()
+}
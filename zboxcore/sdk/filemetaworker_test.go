@@ -297,3 +297,15 @@ func TestListRequest_getFileConsensusFromBlobbers(t *testing.T) {
 		})
 	}
 }
+
+func TestListRequest_isFileNotFound(t *testing.T) {
+	req := &ListRequest{Consensus: Consensus{consensusThresh: 50, fullconsensus: 4}}
+	notFound := &fileMetaResponse{statusCode: http.StatusBadRequest}
+	found := &fileMetaResponse{statusCode: http.StatusOK, fileref: &fileref.FileRef{}}
+	failed := &fileMetaResponse{err: errors.New("", "connection refused")}
+
+	require.True(t, req.isFileNotFound([]*fileMetaResponse{notFound, notFound, notFound, found}))
+	// blobbers failing to answer are not a missing file
+	require.False(t, req.isFileNotFound([]*fileMetaResponse{notFound, failed, failed, failed}))
+	require.False(t, req.isFileNotFound([]*fileMetaResponse{failed, failed, failed, failed}))
+}
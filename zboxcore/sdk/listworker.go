@@ -53,6 +53,9 @@ type ListResult struct {
 	Attributes      fileref.Attributes `json:"attributes"`
 	ActualSize      int64              `json:"actual_size"`
 	ActualNumBlocks int64              `json:"actual_num_blocks"`
+	Compression     string             `json:"compression,omitempty"`
+	CompressedSize  int64              `json:"compressed_size,omitempty"`
+	Dedup           bool               `json:"dedup,omitempty"`
 	CreatedAt       string             `json:"created_at"`
 	UpdatedAt       string             `json:"updated_at"`
 	Children        []*ListResult      `json:"list"`
@@ -202,6 +205,19 @@ func (req *ListRequest) GetListFromBlobbers() *ListResult {
 				if childResult.ActualSize > 0 {
 					childResult.ActualNumBlocks = childResult.ActualSize / CHUNK_SIZE
 				}
+				if meta := compressionMetaOf(child.(*fileref.FileRef)); meta != nil {
+					// the uncompressed file, the blocks are the compressed ones
+					childResult.Compression = meta.Algorithm
+					childResult.CompressedSize = childResult.ActualSize
+					childResult.ActualSize = meta.Size
+					childResult.Hash = meta.Hash
+				}
+				if meta := dedupMetaOf(child.(*fileref.FileRef)); meta != nil {
+					// the file, not its recipe
+					childResult.Dedup = true
+					childResult.ActualSize = meta.Size
+					childResult.Hash = meta.Hash
+				}
 			}
 			childResult.Size += child.GetSize()
 			childResult.NumBlocks += child.GetNumBlocks()
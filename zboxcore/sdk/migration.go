@@ -0,0 +1,388 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	. "github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"go.uber.org/zap"
+)
+
+// BlobberMigration is the progress of the migration of a blobber, saved
+// after each file so that a migration started again resumes from it.
+type BlobberMigration struct {
+	AllocationID string `json:"allocation_id"`
+	BlobberID    string `json:"blobber_id"`
+	// Done are the paths whose shards the blobber has.
+	Done         map[string]bool `json:"done"`
+	FilesRebuilt int             `json:"files_rebuilt"`
+}
+
+func loadBlobberMigration(statePath, allocationID, blobberID string) (*BlobberMigration, error) {
+	m := &BlobberMigration{AllocationID: allocationID, BlobberID: blobberID, Done: make(map[string]bool)}
+	data, err := ioutil.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Local file error")
+	}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrap(err, "invalid migration state")
+	}
+	if m.AllocationID != allocationID || m.BlobberID != blobberID {
+		return nil, errors.New("invalid_migration_state", "Migration state is of blobber "+m.BlobberID+
+			" of allocation "+m.AllocationID)
+	}
+	if m.Done == nil {
+		m.Done = make(map[string]bool)
+	}
+	return m, nil
+}
+
+func (m *BlobberMigration) save(statePath string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "migration state encoding failed")
+	}
+	// the state is replaced at once, an interruption keeps the previous one
+	tmpPath := statePath + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	return os.Rename(tmpPath, statePath)
+}
+
+// ReplaceBlobber swaps the blobber out of the allocation for the new one
+// through the storage SC, then reloads the blobbers of the allocation. The
+// new blobber takes the place, and the shards, of the old one, rebuilt by
+// MigrateBlobber.
+func (a *Allocation) ReplaceBlobber(removeBlobberID, addBlobberID string, lock int64) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	idx := a.blobberIndex(removeBlobberID)
+	if idx < 0 {
+		return errors.New("invalid_blobber", "Blobber "+removeBlobberID+" is not in the allocation")
+	}
+	if a.blobberIndex(addBlobberID) >= 0 {
+		return errors.New("invalid_blobber", "Blobber "+addBlobberID+" is already in the allocation")
+	}
+	hash, err := ReplaceAllocationBlobber(a.ID, removeBlobberID, addBlobberID, lock)
+	if err != nil {
+		return err
+	}
+	Logger.Info("Blobber replaced", zap.String("allocation", a.ID), zap.String("removed", removeBlobberID),
+		zap.String("added", addBlobberID), zap.String("hash", hash))
+
+	updated, err := GetAllocation(a.ID)
+	if err != nil {
+		return err
+	}
+	// the shards are in the order of the blobbers
+	if len(updated.Blobbers) != len(a.Blobbers) {
+		return errors.New("blobber_replace_failed", "Allocation has "+strconv.Itoa(len(updated.Blobbers))+" blobbers")
+	}
+	for i, b := range updated.Blobbers {
+		expected := a.Blobbers[i].ID
+		if i == idx {
+			expected = addBlobberID
+		}
+		if b.ID != expected {
+			return errors.New("blobber_replace_failed", "Blobber "+strconv.Itoa(i)+" of the allocation is "+b.ID+
+				", expected "+expected)
+		}
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.Blobbers = updated.Blobbers
+	a.BlobberDetails = updated.BlobberDetails
+	return nil
+}
+
+func (a *Allocation) blobberIndex(blobberID string) int {
+	for i, b := range a.Blobbers {
+		if b.ID == blobberID {
+			return i
+		}
+	}
+	return -1
+}
+
+// MigrateBlobber rebuilds the shards of the blobber, new in the allocation,
+// from the other blobbers. Each file is streamed from them and encoded
+// again block by block, only the missing shard is uploaded. The shards of
+// encrypted files are encrypted with the key of the file, so the owner
+// migrates them. The progress is saved to statePath and reported to status
+// per file. Once all the files are rebuilt, the allocation root of the
+// blobber is verified.
+func (a *Allocation) MigrateBlobber(blobberID, statePath string, status StatusCallback) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	idx := a.blobberIndex(blobberID)
+	if idx < 0 {
+		return errors.New("invalid_blobber", "Blobber "+blobberID+" is not in the allocation")
+	}
+	state, err := loadBlobberMigration(statePath, a.ID, blobberID)
+	if err != nil {
+		return err
+	}
+	files, err := a.listFiles("/")
+	if err != nil {
+		return err
+	}
+
+	if status != nil {
+		status.Started(a.ID, blobberID, OpRepair, len(files))
+	}
+	var failed int
+	for _, path := range files {
+		if state.Done[path] {
+			continue
+		}
+		rebuilt, err := a.rebuildShard(path, idx)
+		if err != nil {
+			Logger.Error("Shard rebuild failed", zap.String("path", path), zap.Error(err))
+			failed++
+			continue
+		}
+		state.Done[path] = true
+		if rebuilt {
+			state.FilesRebuilt++
+		}
+		if err = state.save(statePath); err != nil {
+			return err
+		}
+		if status != nil {
+			status.InProgress(a.ID, blobberID, OpRepair, len(state.Done), nil)
+		}
+	}
+	if failed > 0 {
+		err = errors.New("migration_incomplete", strconv.Itoa(failed)+" files not rebuilt, migrate again to retry them")
+	} else {
+		err = a.VerifyBlobberRoot(blobberID)
+	}
+	if err != nil {
+		if status != nil {
+			status.Error(a.ID, blobberID, OpRepair, err)
+		}
+		return err
+	}
+	if status != nil {
+		status.RepairCompleted(state.FilesRebuilt)
+		status.Completed(a.ID, blobberID, "", "", state.FilesRebuilt, OpRepair)
+	}
+	os.Remove(statePath)
+	return nil
+}
+
+// listFiles returns the paths of the files of the directory, as blobbers
+// store them.
+func (a *Allocation) listFiles(path string) ([]string, error) {
+	files, _, err := a.listTree(path)
+	return files, err
+}
+
+// listTree returns the paths of the files and of the empty directories of
+// the directory, as blobbers store them.
+func (a *Allocation) listTree(path string) (files, emptyDirs []string, err error) {
+	fullconsensus := float32(a.DataShards + a.ParityShards)
+	consensusThresh := 100 / fullconsensus
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir, err := a.listDir(remotePath, consensusThresh, fullconsensus)
+	if err != nil {
+		return nil, nil, err
+	}
+	var walk func(dir *ListResult) error
+	walk = func(dir *ListResult) error {
+		for _, child := range dir.Children {
+			switch child.Type {
+			case fileref.FILE:
+				files = append(files, child.Path)
+			case fileref.DIRECTORY:
+				childDir, err := a.listDir(child.Path, consensusThresh, fullconsensus)
+				if err != nil {
+					return err
+				}
+				if len(childDir.Children) == 0 {
+					emptyDirs = append(emptyDirs, child.Path)
+				}
+				if err = walk(childDir); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err = walk(dir); err != nil {
+		return nil, nil, err
+	}
+	return files, emptyDirs, nil
+}
+
+// rebuildShard uploads the shard of the file to the blobber at idx if it
+// doesn't have it. It returns whether it was uploaded. The content is
+// streamed from the other blobbers to the upload, nothing is written to
+// disk.
+func (a *Allocation) rebuildShard(remotePath string, idx int) (bool, error) {
+	found, _, fileRef, err := a.repairRequired(remotePath)
+	if err != nil {
+		return false, err
+	}
+	if found.And(blobberMask(idx)).CountOnes() > 0 {
+		return false, nil
+	}
+	if found.CountOnes() < a.DataShards {
+		return false, errors.New("migration_failed", "Not enough shards to rebuild the file")
+	}
+	path, err := a.plainPath(remotePath)
+	if err != nil {
+		return false, err
+	}
+	meta, err := a.GetFileMeta(path)
+	if err != nil {
+		return false, err
+	}
+
+	var thumbnail []byte
+	if fileRef.ActualThumbnailSize > 0 {
+		var buf bytes.Buffer
+		err = new(waitStatusCB).run(func(cb StatusCallback) error {
+			return a.streamDownload(remotePath, DOWNLOAD_CONTENT_THUMB, &buf, cb)
+		})
+		if err != nil {
+			return false, errors.Wrap(err, "thumbnail download failed")
+		}
+		thumbnail = buf.Bytes()
+	}
+
+	pr, pw := io.Pipe()
+	downloaded := make(chan error, 1)
+	go func() {
+		err := new(waitStatusCB).run(func(cb StatusCallback) error {
+			return a.streamDownload(remotePath, DOWNLOAD_CONTENT_FULL, pw, cb)
+		})
+		pw.CloseWithError(err)
+		downloaded <- err
+	}()
+	content := &verifiedReader{r: pr, remaining: fileRef.ActualFileSize, verified: downloaded}
+	err = new(waitStatusCB).run(func(cb StatusCallback) error {
+		uploadReq := a.newUploadRequest(remotePath, fileRef.ActualFileSize, int64(len(thumbnail)),
+			false, fileRef.Attributes, cb)
+		uploadReq.isRepair = true
+		uploadReq.reader = content
+		if len(thumbnail) > 0 {
+			uploadReq.thumbnailReader = bytes.NewReader(thumbnail)
+		}
+		uploadReq.filemeta.Hash = fileRef.ActualFileHash
+		uploadReq.filemeta.ThumbnailHash = fileRef.ActualThumbnailHash
+		uploadReq.filemeta.MimeType = meta.MimeType
+		uploadReq.filemeta.CustomMeta = fileRef.CustomMeta
+		uploadReq.filemeta.Compression = compressionMetaOf(fileRef)
+		uploadReq.filemeta.Dedup = dedupMetaOf(fileRef)
+		uploadReq.isEncrypted = fileRef.EncryptedKey != ""
+		uploadReq.encryptedKey = fileRef.EncryptedKey
+		uploadReq.uploadMask = blobberMask(idx)
+		uploadReq.fullconsensus = 1
+		go func() { a.uploadChan <- uploadReq }()
+		return nil
+	})
+	// stops the download if the upload failed
+	pr.CloseWithError(err)
+	if err != nil {
+		return false, errors.Wrap(err, "shard upload failed")
+	}
+
+	// the blobber has the shard now
+	if found, _, _, err = a.repairRequired(remotePath); err != nil {
+		return false, err
+	}
+	if found.And(blobberMask(idx)).CountOnes() == 0 {
+		return false, errors.New("migration_failed", "Blobber is missing the rebuilt shard")
+	}
+	return true, nil
+}
+
+// VerifyBlobberRoot verifies the latest write marker of the blobber is
+// signed by the client who wrote it, the owner or the current client, and
+// that its allocation root is the one of its files.
+func (a *Allocation) VerifyBlobberRoot(blobberID string) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	idx := a.blobberIndex(blobberID)
+	if idx < 0 {
+		return errors.New("invalid_blobber", "Blobber "+blobberID+" is not in the allocation")
+	}
+	lR, err := getReferencePathFromBlobber(a.ctx, a.Tx, []string{"/"}, a.Blobbers[idx])
+	if err != nil {
+		return err
+	}
+	if lR.ReferencePath == nil {
+		return errors.New("invalid_reference_path", "Blobber returned no reference path")
+	}
+	rootRef, err := lR.GetDirTree(a.ID)
+	if err != nil {
+		return err
+	}
+	return verifyAllocationRoot(a.ID, blobberID, a.clientPublicKey, rootRef, lR.LatestWM)
+}
+
+// clientPublicKey returns the public key of the client writing to the
+// allocation, the owner or the current client, empty if unknown.
+func (a *Allocation) clientPublicKey(clientID string) string {
+	switch clientID {
+	case a.Owner:
+		return a.OwnerPublicKey
+	case client.GetClientID():
+		return client.GetClientPublicKey()
+	}
+	return ""
+}
+
+func verifyAllocationRoot(allocationID, blobberID string, publicKey func(clientID string) string,
+	rootRef *fileref.Ref, wm *marker.WriteMarker) error {
+	rootRef.CalculateHash()
+	if wm == nil {
+		if len(rootRef.Children) > 0 {
+			return errors.New("allocation_root_mismatch", "Blobber has files but no write marker")
+		}
+		return nil
+	}
+	if wm.AllocationID != allocationID || wm.BlobberID != blobberID {
+		return errors.New("allocation_root_mismatch", "Write marker is of blobber "+wm.BlobberID+
+			" of allocation "+wm.AllocationID)
+	}
+	key := publicKey(wm.ClientID)
+	if key == "" {
+		return errors.New("write_marker_validation_failed", "Public key of the client "+wm.ClientID+
+			" signing the write marker is not known")
+	}
+	if err := wm.VerifySignature(key); err != nil {
+		return err
+	}
+	expected := encryption.Hash(rootRef.Hash + ":" + strconv.FormatInt(wm.Timestamp, 10))
+	if expected != wm.AllocationRoot {
+		return errors.New("allocation_root_mismatch", "Allocation root of the write marker is "+
+			wm.AllocationRoot+", expected "+expected)
+	}
+	return nil
+}
+
+func blobberMask(idx int) zboxutil.Uint128 {
+	return zboxutil.NewUint128(1).Lsh(uint64(idx))
+}
@@ -0,0 +1,130 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	zclient "github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobberMigrationState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestBlobberMigrationState")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	statePath := filepath.Join(dir, "state")
+
+	m, err := loadBlobberMigration(statePath, "alloc", "blobber")
+	require.NoError(t, err)
+	require.Empty(t, m.Done)
+	m.Done["/a"] = true
+	m.FilesRebuilt++
+	require.NoError(t, m.save(statePath))
+
+	resumed, err := loadBlobberMigration(statePath, "alloc", "blobber")
+	require.NoError(t, err)
+	require.Equal(t, m, resumed)
+
+	_, err = loadBlobberMigration(statePath, "alloc", "other")
+	require.Error(t, err)
+}
+
+func TestVerifyAllocationRoot(t *testing.T) {
+	w, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	wStr, err := w.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, zclient.PopulateClient(wStr, "bls0chain"))
+	publicKey := func(clientID string) string {
+		if clientID == w.ClientID {
+			return w.ClientKey
+		}
+		return ""
+	}
+
+	newRoot := func() *fileref.Ref {
+		root := &fileref.Ref{Type: fileref.DIRECTORY, Path: "/", AllocationID: "alloc"}
+		file := &fileref.FileRef{ContentHash: "content", ActualFileHash: "actual"}
+		file.Type = fileref.FILE
+		file.Path = "/a"
+		file.Name = "a"
+		file.AllocationID = "alloc"
+		root.AddChild(file)
+		return root
+	}
+	wm := &marker.WriteMarker{AllocationID: "alloc", BlobberID: "blobber", ClientID: w.ClientID, Timestamp: 1600000000}
+	wm.AllocationRoot = encryption.Hash(newRoot().CalculateHash() + ":" + strconv.FormatInt(wm.Timestamp, 10))
+	require.NoError(t, wm.Sign())
+	require.NoError(t, verifyAllocationRoot("alloc", "blobber", publicKey, newRoot(), wm))
+
+	require.Error(t, verifyAllocationRoot("alloc", "other", publicKey, newRoot(), wm))
+	require.Error(t, verifyAllocationRoot("alloc", "blobber", publicKey, newRoot(), nil))
+	require.NoError(t, verifyAllocationRoot("alloc", "blobber", publicKey, &fileref.Ref{Type: fileref.DIRECTORY}, nil))
+
+	tampered := newRoot()
+	tampered.Children[0].(*fileref.FileRef).ContentHash = "other"
+	require.Error(t, verifyAllocationRoot("alloc", "blobber", publicKey, tampered, wm))
+
+	// a root matching the files, not signed by the client
+	forged := *wm
+	forged.Signature = ""
+	require.Error(t, verifyAllocationRoot("alloc", "blobber", publicKey, newRoot(), &forged))
+	forged = *wm
+	forged.ClientID = "other"
+	require.Error(t, verifyAllocationRoot("alloc", "blobber", publicKey, newRoot(), &forged))
+}
+
+func TestRebuildEncryptedShard(t *testing.T) {
+	setupEncryptionTestClient(t)
+	data := bytes.Repeat([]byte("rebuilt shard "), 20000)
+	shards, encryptedKey := pushEncrypted(t, bytes.NewReader(data), int64(len(data)))
+	var content bytes.Buffer
+	require.NoError(t, decryptShards(shards, encryptedKey, int64(len(data)), &content))
+
+	// the lost shard encoded and encrypted again from the content is the
+	// one of the blobber
+	lost := 3
+	req := &UploadRequest{datashards: 2, parityshards: 2, isEncrypted: true, isRepair: true}
+	req.encryptedKey = encryptedKey
+	req.uploadMask = blobberMask(lost)
+	req.remaining = int64(len(data))
+	require.NoError(t, req.setupEncryption())
+	ch := make(chan []byte)
+	req.uploadDataCh = []chan []byte{ch}
+	var rebuilt [][]byte
+	done := make(chan struct{})
+	go func() {
+		for chunk := range ch {
+			rebuilt = append(rebuilt, chunk)
+		}
+		close(done)
+	}()
+	size := int64(len(data))
+	perShard := (size + 1) / 2
+	chunkSize := int64(fileref.CHUNK_SIZE) - req.chunkOverhead()
+	chunksPerShard := int64(len(shards[0]))
+	r := io.MultiReader(&content, bytes.NewReader(make([]byte, 2*perShard-size)))
+	for ctr := int64(0); ctr < chunksPerShard; ctr++ {
+		remaining := perShard - ctr*chunkSize
+		if remaining > chunkSize {
+			remaining = chunkSize
+		}
+		block := make([]byte, 2*remaining)
+		_, err := io.ReadFull(r, block)
+		require.NoError(t, err)
+		require.NoError(t, req.pushData(block, ctr, ctr == chunksPerShard-1))
+	}
+	close(ch)
+	<-done
+	require.Equal(t, shards[lost], rebuilt)
+	require.Contains(t, encryptedKey, req.encscheme.GetEncryptedKey())
+}
@@ -0,0 +1,352 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// sharedNames is the name key an auth ticket carries, sealed for the
+// encryption public key of the referee. It reveals the name of the shared
+// file or directory, and the names under a shared directory, not the names
+// of its siblings.
+type sharedNames struct {
+	// Name is the plain name of the shared path.
+	Name string `json:"name"`
+	// Path is the encrypted shared path.
+	Path string `json:"path"`
+	// Key decrypts the names under a shared directory and the metadata,
+	// only the metadata of a shared file.
+	Key string `json:"key"`
+}
+
+// nameView decrypts the names the blobbers return, of the allocation for
+// the owner, or under the shared path for the referee of an auth ticket.
+type nameView struct {
+	names *encryption.NameCipher
+	// shared is the shared path of the auth ticket, nil for the owner.
+	shared *sharedNames
+}
+
+// decryptPath decrypts the encrypted path. The paths of the referee are
+// relative to the parent directory of the shared path.
+func (v *nameView) decryptPath(encPath string) (string, error) {
+	if v.shared == nil {
+		return v.names.DecryptPath(encPath)
+	}
+	prefix := strings.TrimRight(v.shared.Path, "/")
+	if !strings.HasPrefix(encPath, prefix) || (len(encPath) > len(prefix) && encPath[len(prefix)] != '/') {
+		return "", errors.New("invalid_path", "path is not under the shared path")
+	}
+	path, err := v.names.DecryptPath(encPath[len(prefix):])
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimRight("/"+v.shared.Name, "/")
+	if path == "/" && name != "" {
+		return name, nil
+	}
+	return name + path, nil
+}
+
+// EnableNameEncryption encrypts the names and paths of the files and
+// directories and the mime types of the files, blobbers only see them
+// encrypted. The allocation methods keep taking and returning plain paths.
+// Names are encrypted with the name key of the encryption keyring, or
+// with a key derived from the wallet mnemonic. Enable it before uploading,
+// the files uploaded without it are not found by their plain path.
+func (a *Allocation) EnableNameEncryption() error {
+	key, err := nameEncryptionKey(a.ID)
+	if err != nil {
+		return err
+	}
+	a.nameCipher = encryption.NewNameCipher(key)
+	return nil
+}
+
+// IsNameEncrypted returns whether EnableNameEncryption was called.
+func (a *Allocation) IsNameEncrypted() bool {
+	return a.nameCipher != nil
+}
+
+// remotePath returns the path the blobbers know the plain path as.
+func (a *Allocation) remotePath(path string) (string, error) {
+	if a.nameCipher == nil || !zboxutil.IsRemoteAbs(path) {
+		return path, nil
+	}
+	return a.nameCipher.EncryptPath(zboxutil.RemoteClean(path))
+}
+
+// plainPath decrypts the path the blobbers returned.
+func (a *Allocation) plainPath(remotePath string) (string, error) {
+	if a.nameCipher == nil {
+		return remotePath, nil
+	}
+	return a.nameCipher.DecryptPath(remotePath)
+}
+
+// decryptListResult decrypts the names of the listing and of its children.
+// The names uploaded unencrypted are left as they are.
+func decryptListResult(v *nameView, res *ListResult) {
+	if path, err := v.decryptPath(res.Path); err == nil {
+		res.Path = path
+		if path != "/" {
+			res.Name = pathpkg.Base(path)
+		}
+	}
+	if mimeType, err := v.names.DecryptMeta(res.MimeType); err == nil {
+		res.MimeType = mimeType
+	}
+	for _, child := range res.Children {
+		decryptListResult(v, child)
+	}
+}
+
+// decryptFileMeta decrypts the names of the file meta, see
+// decryptListResult.
+func decryptFileMeta(v *nameView, meta *ConsolidatedFileMeta) {
+	if path, err := v.decryptPath(meta.Path); err == nil {
+		meta.Path = path
+		if path != "/" {
+			meta.Name = pathpkg.Base(path)
+		}
+	}
+	if mimeType, err := v.names.DecryptMeta(meta.MimeType); err == nil {
+		meta.MimeType = mimeType
+	}
+}
+
+// sealNames returns the name key of the auth ticket of the path, sealed
+// for the encryption public key, see sharedNames.
+func (a *Allocation) sealNames(path string, isDir bool, encPublicKey string) (string, error) {
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return "", err
+	}
+	shared := &sharedNames{
+		Name: pathpkg.Base(path),
+		Path: remotePath,
+		Key:  a.nameCipher.ExportMeta(),
+	}
+	if isDir {
+		shared.Key = a.nameCipher.Dir(path).Export()
+	}
+	names, err := json.Marshal(shared)
+	if err != nil {
+		return "", err
+	}
+	return encryption.SealForPublicKey(encPublicKey, names)
+}
+
+// openNames returns the names view of the auth ticket, nil if its names
+// are not encrypted for the client.
+func openNames(at *marker.AuthTicket) *nameView {
+	if at.NameKey == "" {
+		return nil
+	}
+	encscheme, err := recipientScheme(at.EncryptionKeyID)
+	if err != nil {
+		return nil
+	}
+	b, err := encryption.OpenSealed(encscheme, at.NameKey)
+	if err != nil {
+		return nil
+	}
+	var shared sharedNames
+	if err = json.Unmarshal(b, &shared); err != nil {
+		return nil
+	}
+	names, err := encryption.ImportNameCipher(shared.Key)
+	if err != nil {
+		return nil
+	}
+	return &nameView{names: names, shared: &shared}
+}
+
+// PendingRename is the rename of a directory with encrypted names, saved
+// before the directory is renamed and cleared once the names under it are
+// re-encrypted with the key of its new path. ResumeRename completes it.
+type PendingRename struct {
+	AllocationID string `json:"allocation_id"`
+	// RemotePath is the encrypted path of the directory before the rename.
+	RemotePath string `json:"remote_path"`
+	OldPath    string `json:"old_path"`
+	NewPath    string `json:"new_path"`
+}
+
+// SetRenameStatePath sets the local file the renames of directories with
+// encrypted names are saved in until they complete. A rename interrupted,
+// even by the exit of the process, is then completed by ResumeRename.
+// Without it the pending rename is only kept in memory.
+func (a *Allocation) SetRenameStatePath(statePath string) {
+	a.renameStatePath = statePath
+}
+
+// GetPendingRename returns the rename of a directory that didn't complete,
+// nil if none.
+func (a *Allocation) GetPendingRename() (*PendingRename, error) {
+	if a.pendingRename != nil || a.renameStatePath == "" {
+		return a.pendingRename, nil
+	}
+	data, err := ioutil.ReadFile(a.renameStatePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Local file error")
+	}
+	pending := &PendingRename{}
+	if err = json.Unmarshal(data, pending); err != nil {
+		return nil, errors.Wrap(err, "invalid rename state")
+	}
+	if pending.AllocationID != a.ID {
+		return nil, errors.New("invalid_rename_state", "Rename state is of allocation "+pending.AllocationID)
+	}
+	return pending, nil
+}
+
+// ResumeRename completes the rename of a directory that didn't complete:
+// the directory is renamed if it wasn't, then the names under it not
+// re-encrypted yet are.
+func (a *Allocation) ResumeRename() error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	if a.nameCipher == nil {
+		return errors.New("invalid_operation", "Name encryption is not enabled")
+	}
+	pending, err := a.GetPendingRename()
+	if err != nil || pending == nil {
+		return err
+	}
+	if _, err = a.getFileMeta(pending.RemotePath); err == nil {
+		// interrupted before the directory was renamed
+		return a.renameEncrypted(pending.RemotePath, pending.OldPath, pending.NewPath)
+	}
+	if err = a.reencryptNames(pending.OldPath, pending.NewPath); err != nil {
+		return err
+	}
+	return a.setPendingRename(nil)
+}
+
+// setPendingRename saves the pending rename, nil clears it.
+func (a *Allocation) setPendingRename(pending *PendingRename) error {
+	a.pendingRename = pending
+	if a.renameStatePath == "" {
+		return nil
+	}
+	if pending == nil {
+		if err := os.Remove(a.renameStatePath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "Local file error")
+		}
+		return nil
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return errors.Wrap(err, "rename state encoding failed")
+	}
+	// the state is replaced at once, an interruption keeps the previous one
+	tmpPath := a.renameStatePath + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return errors.Wrap(err, "Local file error")
+	}
+	return os.Rename(tmpPath, a.renameStatePath)
+}
+
+// renameEncrypted renames the object to the name encrypted with the key of
+// its new parent directory. The names in a directory are re-encrypted with
+// its new key, the rename is pending until they all are.
+func (a *Allocation) renameEncrypted(remotePath, oldPath, newPath string) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	meta, err := a.getFileMeta(remotePath)
+	if err != nil {
+		return err
+	}
+	newName, err := a.nameCipher.Dir(pathpkg.Dir(newPath)).EncryptName(pathpkg.Base(newPath))
+	if err != nil {
+		return err
+	}
+	if meta.Type == fileref.DIRECTORY {
+		if pending, err := a.GetPendingRename(); err != nil {
+			return err
+		} else if pending != nil && *pending != (PendingRename{a.ID, remotePath, oldPath, newPath}) {
+			return errors.New("rename_pending", "Rename of "+pending.OldPath+" to "+pending.NewPath+
+				" didn't complete, resume it first")
+		}
+		err = a.setPendingRename(&PendingRename{
+			AllocationID: a.ID,
+			RemotePath:   remotePath,
+			OldPath:      oldPath,
+			NewPath:      newPath,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if newName != pathpkg.Base(remotePath) {
+		if err := a.renameObject(remotePath, newName); err != nil {
+			return err
+		}
+	}
+	if meta.Type != fileref.DIRECTORY {
+		return nil
+	}
+	if err = a.reencryptNames(oldPath, newPath); err != nil {
+		return err
+	}
+	return a.setPendingRename(nil)
+}
+
+// reencryptNames renames the children of the directory moved from oldDir
+// to newDir to their names encrypted with the key of newDir. The children
+// already renamed are skipped, it resumes an interrupted rename.
+func (a *Allocation) reencryptNames(oldDir, newDir string) error {
+	if oldDir == newDir {
+		return nil
+	}
+	oldNames, newNames := a.nameCipher.Dir(oldDir), a.nameCipher.Dir(newDir)
+	consensusThresh := (float32(a.DataShards) * 100) / float32(a.DataShards+a.ParityShards)
+	fullconsensus := float32(a.DataShards + a.ParityShards)
+	remoteDir, err := a.remotePath(newDir)
+	if err != nil {
+		return err
+	}
+	list, err := a.listDir(remoteDir, consensusThresh, fullconsensus)
+	if err != nil {
+		return err
+	}
+	for _, child := range list.Children {
+		name, err := oldNames.DecryptName(child.Name)
+		if err == nil {
+			newName, err := newNames.EncryptName(name)
+			if err != nil {
+				return err
+			}
+			if err = a.renameObject(child.Path, newName); err != nil {
+				return err
+			}
+		} else if name, err = newNames.DecryptName(child.Name); err != nil {
+			// not encrypted
+			continue
+		}
+		// a directory re-encrypted by an interrupted rename may have
+		// children that are not
+		if child.Type == fileref.DIRECTORY {
+			err = a.reencryptNames(pathpkg.Join(oldDir, name), pathpkg.Join(newDir, name))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecryptListResult(t *testing.T) {
+	a := &Allocation{nameCipher: encryption.NewNameCipher([]byte("allocation name key"))}
+	enc := func(s string, err error) string {
+		require.NoError(t, err)
+		return s
+	}
+	dir := enc(a.remotePath("/docs/reports"))
+	file := enc(a.remotePath("/docs/reports/2021.pdf"))
+	newList := func() *ListResult {
+		return &ListResult{
+			Name: dir[len(enc(a.remotePath("/docs")))+1:],
+			Path: dir,
+			Type: fileref.DIRECTORY,
+			Children: []*ListResult{{
+				Name:     file[len(dir)+1:],
+				Path:     file,
+				Type:     fileref.FILE,
+				MimeType: enc(a.nameCipher.EncryptMeta("application/pdf")),
+			}, {
+				Name: "plain.txt",
+				Path: dir + "/plain.txt",
+				Type: fileref.FILE,
+			}},
+		}
+	}
+
+	list := newList()
+	decryptListResult(&nameView{names: a.nameCipher}, list)
+	require.Equal(t, "reports", list.Name)
+	require.Equal(t, "/docs/reports", list.Path)
+	require.Equal(t, "2021.pdf", list.Children[0].Name)
+	require.Equal(t, "/docs/reports/2021.pdf", list.Children[0].Path)
+	require.Equal(t, "application/pdf", list.Children[0].MimeType)
+	require.Equal(t, "plain.txt", list.Children[1].Name)
+
+	// the referee of a share sees the paths relative to the shared parent
+	for sharedPath, want := range map[string]string{"/docs/reports": "/reports", "/": "/docs/reports"} {
+		v := &nameView{names: a.nameCipher.Dir(sharedPath), shared: &sharedNames{
+			Name: path.Base(sharedPath),
+			Path: enc(a.remotePath(sharedPath)),
+		}}
+		list = newList()
+		decryptListResult(v, list)
+		require.Equal(t, want, list.Path, sharedPath)
+		require.Equal(t, want+"/2021.pdf", list.Children[0].Path, sharedPath)
+		require.Equal(t, "application/pdf", list.Children[0].MimeType)
+	}
+}
+
+func TestSealNames(t *testing.T) {
+	setupEncryptionTestClient(t)
+	a := &Allocation{nameCipher: encryption.NewNameCipher([]byte("allocation name key"))}
+	enc := func(s string, err error) string {
+		require.NoError(t, err)
+		return s
+	}
+	reader, _, err := encryptionScheme()
+	require.NoError(t, err)
+	readerKey, err := reader.GetPublicKey()
+	require.NoError(t, err)
+
+	open := func(path string, isDir bool) *nameView {
+		nameKey, err := a.sealNames(path, isDir, readerKey)
+		require.NoError(t, err)
+		v := openNames(&marker.AuthTicket{NameKey: nameKey})
+		require.NotNil(t, v)
+		return v
+	}
+
+	// a file share reveals the file name only, not its siblings
+	v := open("/docs/a.txt", false)
+	require.Equal(t, "a.txt", v.shared.Name)
+	p, err := v.decryptPath(enc(a.remotePath("/docs/a.txt")))
+	require.NoError(t, err)
+	require.Equal(t, "/a.txt", p)
+	_, err = v.decryptPath(enc(a.remotePath("/docs/b.txt")))
+	require.Error(t, err)
+	_, err = v.names.DecryptName(enc(a.nameCipher.Dir("/docs").EncryptName("b.txt")))
+	require.Error(t, err)
+	mime, err := v.names.DecryptMeta(enc(a.nameCipher.EncryptMeta("text/plain")))
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", mime)
+
+	// a directory share reveals the names under it
+	v = open("/docs/reports", true)
+	p, err = v.decryptPath(enc(a.remotePath("/docs/reports/2021/q1.pdf")))
+	require.NoError(t, err)
+	require.Equal(t, "/reports/2021/q1.pdf", p)
+	_, err = v.decryptPath(enc(a.remotePath("/docs/other/q1.pdf")))
+	require.Error(t, err)
+	_, err = v.names.DecryptName(enc(a.nameCipher.Dir("/docs").EncryptName("other")))
+	require.Error(t, err)
+}
+
+func TestPendingRename(t *testing.T) {
+	a := &Allocation{ID: "allocation"}
+	pending, err := a.GetPendingRename()
+	require.NoError(t, err)
+	require.Nil(t, pending)
+
+	// saved until the names are re-encrypted, read back by another process
+	dir, err := ioutil.TempDir("", "TestPendingRename")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	a.SetRenameStatePath(filepath.Join(dir, "rename.json"))
+	rename := &PendingRename{AllocationID: a.ID, RemotePath: "/enc", OldPath: "/docs", NewPath: "/papers"}
+	require.NoError(t, a.setPendingRename(rename))
+	b := &Allocation{ID: a.ID}
+	b.SetRenameStatePath(a.renameStatePath)
+	pending, err = b.GetPendingRename()
+	require.NoError(t, err)
+	require.Equal(t, rename, pending)
+
+	other := &Allocation{ID: "other"}
+	other.SetRenameStatePath(a.renameStatePath)
+	_, err = other.GetPendingRename()
+	require.Error(t, err)
+
+	require.NoError(t, a.setPendingRename(nil))
+	b.pendingRename = nil
+	pending, err = b.GetPendingRename()
+	require.NoError(t, err)
+	require.Nil(t, pending)
+}
@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/node"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
 )
@@ -108,3 +109,9 @@ func GetNetworkDetails() (*Network, error) {
 	})
 	return &networkResponse, err
 }
+
+// GetNodeHealth returns the latency, error rate, round lag and quarantine
+// stats of the miners and sharders used by the SDK.
+func GetNodeHealth() *node.PoolStats {
+	return node.GetPoolStats()
+}
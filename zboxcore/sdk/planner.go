@@ -0,0 +1,411 @@
+package sdk
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/client"
+	. "github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"go.uber.org/zap"
+)
+
+// Blobber ordering policies of the allocation planner.
+const (
+	// PolicyCheapest prefers the lowest write, then read, prices.
+	PolicyCheapest = "cheapest"
+	// PolicyLatency prefers the lowest latency measured from the client.
+	PolicyLatency = "latency"
+)
+
+// plannerProbes is the number of blobbers probed at once by the planner.
+const plannerProbes = 10
+
+// AllocationPlanOptions are the allocation and the blobber selection
+// policy of the planner.
+type AllocationPlanOptions struct {
+	DataShards   int
+	ParityShards int
+	Size         int64
+	// Expiry is the expiration date of the allocation, a unix timestamp.
+	Expiry int64
+	// ReadPrice and WritePrice are any price when empty, the request gets
+	// the range of the prices of the blobbers selected.
+	ReadPrice  PriceRange
+	WritePrice PriceRange
+	// MaxChallengeCompletionTime is the one of the storage SC
+	// configuration when 0.
+	MaxChallengeCompletionTime time.Duration
+
+	// Policy orders the eligible blobbers, PolicyCheapest by default.
+	Policy string
+	// Diversify selects at most one blobber per operator and per region.
+	Diversify bool
+	// Region returns the region of the blobber, empty if unknown. Without
+	// it Diversify only spreads the blobbers over operators, as Explain
+	// notes.
+	Region func(b *Blobber) string
+	// MaxLatency rejects the blobbers slower to respond, 0 for no limit.
+	MaxLatency time.Duration
+	// MinFreeStake is the free staked capacity a blobber keeps once the
+	// allocation is on it.
+	MinFreeStake common.Size
+	// MinChallengeSuccessRatio rejects the blobbers passing less
+	// challenges, ChallengeStats returns their passed and total ones.
+	// The chain doesn't report them with the blobbers.
+	MinChallengeSuccessRatio float64
+	ChallengeStats           func(blobberID string) (passed, total int64, err error)
+}
+
+// PlannedBlobber is a blobber considered by the planner.
+type PlannedBlobber struct {
+	Blobber   *Blobber
+	StakePool *StakePoolInfo
+	Operator  string
+	Region    string
+	// Latency is 0 when it isn't measured.
+	Latency time.Duration
+	// ChallengeSuccessRatio is -1 when it isn't known.
+	ChallengeSuccessRatio float64
+	// Reason the blobber is selected or rejected.
+	Reason string
+}
+
+// AllocationPlan is the request of the new allocation and the reasons of
+// its blobbers.
+type AllocationPlan struct {
+	Request  *CreateAllocationRequest
+	Selected []*PlannedBlobber
+	Rejected []*PlannedBlobber
+	// MinLock is the lock demanded by the chain for the request, and
+	// EstimatedMinLock the sum of the min lock demands of the blobbers.
+	MinLock          int64
+	EstimatedMinLock int64
+	// Notes are the parts of the policy which could not be applied.
+	Notes []string
+}
+
+// Explain describes the selected and the rejected blobbers.
+func (p *AllocationPlan) Explain() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d blobbers selected, min lock %d (estimated %d)\n",
+		len(p.Selected), p.MinLock, p.EstimatedMinLock)
+	for _, note := range p.Notes {
+		fmt.Fprintf(&sb, "note: %s\n", note)
+	}
+	for _, b := range p.Selected {
+		fmt.Fprintf(&sb, "selected %s (%s): %s\n", b.Blobber.ID, b.Blobber.BaseURL, b.Reason)
+	}
+	for _, b := range p.Rejected {
+		fmt.Fprintf(&sb, "rejected %s (%s): %s\n", b.Blobber.ID, b.Blobber.BaseURL, b.Reason)
+	}
+	return sb.String()
+}
+
+// PlanAllocation selects the blobbers of a new allocation by the policy,
+// from the blobbers, their stake pools and the storage SC configuration.
+// CreateAllocationWithRequest creates the planned allocation.
+func PlanAllocation(opts *AllocationPlanOptions) (*AllocationPlan, error) {
+	if !sdkInitialized {
+		return nil, sdkNotInitialized
+	}
+	conf, err := GetStorageSCConfig()
+	if err != nil {
+		return nil, err
+	}
+	blobbers, err := GetBlobbers()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*PlannedBlobber, len(blobbers))
+	probes := make(chan *PlannedBlobber)
+	var wg sync.WaitGroup
+	for i := 0; i < plannerProbes && i < len(blobbers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range probes {
+				probeBlobber(opts, c)
+			}
+		}()
+	}
+	for i, b := range blobbers {
+		candidates[i] = &PlannedBlobber{Blobber: b, ChallengeSuccessRatio: -1}
+		probes <- candidates[i]
+	}
+	close(probes)
+	wg.Wait()
+
+	plan, err := planAllocation(opts, conf, candidates, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	plan.Request.OwnerID = client.GetClientID()
+	plan.Request.OwnerPublicKey = client.GetClientPublicKey()
+	if plan.MinLock, err = GetAllocationRequestMinLock(plan.Request); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// probeBlobber gets the stake pool of the blobber and measures what the
+// policy needs.
+func probeBlobber(opts *AllocationPlanOptions, c *PlannedBlobber) {
+	if sp, err := GetStakePoolInfo(string(c.Blobber.ID)); err == nil {
+		c.StakePool = sp
+	} else {
+		Logger.Error("Stake pool request failed", zap.String("blobber", string(c.Blobber.ID)), zap.Error(err))
+	}
+	if opts.Policy == PolicyLatency || opts.MaxLatency > 0 {
+		c.Latency = measureLatency(c.Blobber.BaseURL)
+	}
+	if opts.ChallengeStats != nil {
+		if passed, total, err := opts.ChallengeStats(string(c.Blobber.ID)); err == nil && total > 0 {
+			c.ChallengeSuccessRatio = float64(passed) / float64(total)
+		}
+	}
+}
+
+// measureLatency returns the time the blobber takes to respond, -1 if it
+// doesn't.
+func measureLatency(baseURL string) time.Duration {
+	req, ctx, cncl, err := zboxutil.NewHTTPRequest(http.MethodGet, baseURL+"/_stats", nil)
+	if err != nil {
+		return -1
+	}
+	defer cncl()
+	start := time.Now()
+	err = zboxutil.HttpDo(ctx, cncl, req, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	})
+	if err != nil {
+		return -1
+	}
+	return time.Since(start)
+}
+
+func planAllocation(opts *AllocationPlanOptions, conf *StorageSCConfig,
+	candidates []*PlannedBlobber, now time.Time) (*AllocationPlan, error) {
+
+	need := opts.DataShards + opts.ParityShards
+	if opts.DataShards <= 0 || opts.ParityShards < 0 {
+		return nil, errors.New("invalid_allocation", "Allocation should have data shards")
+	}
+	duration := time.Duration(opts.Expiry-now.Unix()) * time.Second
+	mcct := opts.MaxChallengeCompletionTime
+	if mcct == 0 {
+		mcct = conf.MaxChallengeCompletionTime
+	}
+	switch {
+	case opts.Policy != "" && opts.Policy != PolicyCheapest && opts.Policy != PolicyLatency:
+		return nil, errors.New("invalid_policy", "Unknown blobber selection policy "+opts.Policy)
+	case opts.Size < int64(conf.MinAllocSize):
+		return nil, errors.New("invalid_allocation", fmt.Sprintf("Allocation size is less than %d", conf.MinAllocSize))
+	case duration < conf.MinAllocDuration:
+		return nil, errors.New("invalid_allocation", fmt.Sprintf("Allocation duration is less than %v", conf.MinAllocDuration))
+	case mcct > conf.MaxChallengeCompletionTime:
+		return nil, errors.New("invalid_allocation",
+			fmt.Sprintf("Challenge completion time is more than %v", conf.MaxChallengeCompletionTime))
+	}
+	shardSize := (opts.Size + int64(opts.DataShards) - 1) / int64(opts.DataShards)
+
+	plan := &AllocationPlan{}
+	var eligible []*PlannedBlobber
+	for _, c := range candidates {
+		if c.Operator == "" {
+			c.Operator = c.Blobber.StakePoolSettings.DelegateWallet
+		}
+		if c.Region == "" && opts.Region != nil {
+			c.Region = opts.Region(c.Blobber)
+		}
+		if c.Reason = rejectReason(opts, c, shardSize, duration, mcct); c.Reason != "" {
+			plan.Rejected = append(plan.Rejected, c)
+			continue
+		}
+		eligible = append(eligible, c)
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		a, b := eligible[i], eligible[j]
+		if opts.Policy == PolicyLatency && a.Latency != b.Latency {
+			return a.Latency < b.Latency
+		}
+		if a.Blobber.Terms.WritePrice != b.Blobber.Terms.WritePrice {
+			return a.Blobber.Terms.WritePrice < b.Blobber.Terms.WritePrice
+		}
+		if a.Blobber.Terms.ReadPrice != b.Blobber.Terms.ReadPrice {
+			return a.Blobber.Terms.ReadPrice < b.Blobber.Terms.ReadPrice
+		}
+		return a.Blobber.ID < b.Blobber.ID
+	})
+
+	var (
+		operators = make(map[string]string)
+		regions   = make(map[string]string)
+	)
+	for _, c := range eligible {
+		switch {
+		case len(plan.Selected) == need:
+			c.Reason = "not needed, " + describeBlobber(c)
+		case opts.Diversify && c.Operator != "" && operators[c.Operator] != "":
+			c.Reason = "same operator as " + operators[c.Operator]
+		case opts.Diversify && c.Region != "" && regions[c.Region] != "":
+			c.Reason = "same region as " + regions[c.Region]
+		default:
+			c.Reason = describeBlobber(c)
+			plan.Selected = append(plan.Selected, c)
+			operators[c.Operator] = string(c.Blobber.ID)
+			regions[c.Region] = string(c.Blobber.ID)
+			continue
+		}
+		plan.Rejected = append(plan.Rejected, c)
+	}
+	if len(plan.Selected) < need {
+		return nil, errors.New("not_enough_blobbers",
+			fmt.Sprintf("%d blobbers needed, %d selected of %d", need, len(plan.Selected), len(candidates)))
+	}
+
+	plan.Notes = diversityNotes(opts, plan.Selected)
+
+	ids := make([]string, len(plan.Selected))
+	readPrice, writePrice := opts.ReadPrice, opts.WritePrice
+	for i, c := range plan.Selected {
+		ids[i] = string(c.Blobber.ID)
+		plan.EstimatedMinLock += minLockDemand(conf, c.Blobber, shardSize, duration)
+		if opts.ReadPrice == (PriceRange{}) {
+			readPrice = widenPriceRange(readPrice, c.Blobber.Terms.ReadPrice, i == 0)
+		}
+		if opts.WritePrice == (PriceRange{}) {
+			writePrice = widenPriceRange(writePrice, c.Blobber.Terms.WritePrice, i == 0)
+		}
+	}
+	plan.Request = &CreateAllocationRequest{
+		DataShards:                 opts.DataShards,
+		ParityShards:               opts.ParityShards,
+		Size:                       opts.Size,
+		Expiration:                 opts.Expiry,
+		PreferredBlobbers:          ids,
+		ReadPriceRange:             readPrice,
+		WritePriceRange:            writePrice,
+		MaxChallengeCompletionTime: mcct,
+	}
+	return plan, nil
+}
+
+// diversityNotes returns the notes of the selected blobbers whose region
+// is unknown, not spread over regions.
+func diversityNotes(opts *AllocationPlanOptions, selected []*PlannedBlobber) []string {
+	if !opts.Diversify {
+		return nil
+	}
+	if opts.Region == nil {
+		return []string{"region diversity not applied, no region given for the blobbers"}
+	}
+	var unknown []string
+	for _, c := range selected {
+		if c.Region == "" {
+			unknown = append(unknown, string(c.Blobber.ID))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return []string{"region diversity not applied to " + strings.Join(unknown, ", ") + ", region unknown"}
+}
+
+// rejectReason returns why the blobber can't hold the allocation, empty if
+// it can.
+func rejectReason(opts *AllocationPlanOptions, c *PlannedBlobber, shardSize int64,
+	duration, mcct time.Duration) string {
+	terms := c.Blobber.Terms
+	switch {
+	case !inPriceRange(opts.WritePrice, terms.WritePrice):
+		return fmt.Sprintf("write price %d out of range", terms.WritePrice)
+	case !inPriceRange(opts.ReadPrice, terms.ReadPrice):
+		return fmt.Sprintf("read price %d out of range", terms.ReadPrice)
+	case terms.ChallengeCompletionTime > mcct:
+		return fmt.Sprintf("challenge completion time %v too long", terms.ChallengeCompletionTime)
+	case terms.MaxOfferDuration < duration:
+		return fmt.Sprintf("max offer duration %v too short", terms.MaxOfferDuration)
+	case int64(c.Blobber.Capacity-c.Blobber.Used) < shardSize:
+		return fmt.Sprintf("%d free space, %d needed", c.Blobber.Capacity-c.Blobber.Used, shardSize)
+	case c.StakePool == nil:
+		return "stake pool unavailable"
+	case int64(c.StakePool.Free) < shardSize+int64(opts.MinFreeStake):
+		return fmt.Sprintf("%d free stake capacity, %d needed", c.StakePool.Free, shardSize+int64(opts.MinFreeStake))
+	case c.Latency < 0:
+		return "unreachable"
+	case opts.MaxLatency > 0 && c.Latency > opts.MaxLatency:
+		return fmt.Sprintf("latency %v too high", c.Latency)
+	case opts.MinChallengeSuccessRatio > 0 && c.ChallengeSuccessRatio < 0:
+		return "challenge success ratio unknown"
+	case opts.MinChallengeSuccessRatio > 0 && c.ChallengeSuccessRatio < opts.MinChallengeSuccessRatio:
+		return fmt.Sprintf("challenge success ratio %.2f too low", c.ChallengeSuccessRatio)
+	}
+	return ""
+}
+
+// inPriceRange returns whether the price is in the range, any price is if
+// the range is empty.
+func inPriceRange(pr PriceRange, price common.Balance) bool {
+	if pr.Max == 0 {
+		return true
+	}
+	return pr.Min <= int64(price) && int64(price) <= pr.Max
+}
+
+// widenPriceRange returns the range including the price, the price only if
+// first.
+func widenPriceRange(pr PriceRange, price common.Balance, first bool) PriceRange {
+	if first || int64(price) < pr.Min {
+		pr.Min = int64(price)
+	}
+	if first || int64(price) > pr.Max {
+		pr.Max = int64(price)
+	}
+	return pr
+}
+
+func describeBlobber(c *PlannedBlobber) string {
+	desc := fmt.Sprintf("write price %d, read price %d", c.Blobber.Terms.WritePrice, c.Blobber.Terms.ReadPrice)
+	if c.StakePool != nil {
+		desc += fmt.Sprintf(", free stake capacity %d", c.StakePool.Free)
+	}
+	if c.Latency > 0 {
+		desc += fmt.Sprintf(", latency %v", c.Latency.Round(time.Millisecond))
+	}
+	if c.ChallengeSuccessRatio >= 0 {
+		desc += fmt.Sprintf(", challenge success ratio %.2f", c.ChallengeSuccessRatio)
+	}
+	if c.Operator != "" {
+		desc += ", operator " + c.Operator
+	}
+	if c.Region != "" {
+		desc += ", region " + c.Region
+	}
+	return desc
+}
+
+// minLockDemand returns the min lock demand of the blobber for the shard,
+// the part of its write price it is paid whatever the allocation uses.
+func minLockDemand(conf *StorageSCConfig, b *Blobber, shardSize int64, duration time.Duration) int64 {
+	timeUnit := conf.TimeUnit
+	if timeUnit <= 0 {
+		timeUnit = duration
+	}
+	gb := float64(shardSize) / GB
+	units := float64(duration) / float64(timeUnit)
+	return int64(math.Ceil(float64(b.Terms.WritePrice) * gb * b.Terms.MinLockDemand * units))
+}
@@ -0,0 +1,109 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAllocation(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	conf := &StorageSCConfig{
+		MinAllocSize:               1 * MB,
+		MinAllocDuration:           time.Hour,
+		MaxChallengeCompletionTime: time.Hour,
+		TimeUnit:                   720 * time.Hour,
+	}
+	candidate := func(id, url, operator string, writePrice common.Balance, latency time.Duration) *PlannedBlobber {
+		return &PlannedBlobber{
+			Blobber: &Blobber{
+				ID:       common.Key(id),
+				BaseURL:  url,
+				Capacity: 10 * GB,
+				Terms: Terms{
+					WritePrice:              writePrice,
+					ReadPrice:               1,
+					MinLockDemand:           0.1,
+					MaxOfferDuration:        1000 * time.Hour,
+					ChallengeCompletionTime: time.Minute,
+				},
+				StakePoolSettings: StakePoolSettings{DelegateWallet: operator},
+			},
+			StakePool:             &StakePoolInfo{Free: 5 * GB},
+			Latency:               latency,
+			ChallengeSuccessRatio: -1,
+		}
+	}
+	candidates := func() []*PlannedBlobber {
+		return []*PlannedBlobber{
+			candidate("a", "http://one.example:5051", "op1", 40, 30*time.Millisecond),
+			candidate("b", "http://one.example:5052", "op1", 10, 20*time.Millisecond),
+			candidate("c", "http://two.example:5051", "op2", 20, 10*time.Millisecond),
+			candidate("d", "http://three.example:5051", "op3", 30, 40*time.Millisecond),
+			candidate("e", "http://four.example:5051", "op4", 1000, time.Millisecond),
+		}
+	}
+	opts := &AllocationPlanOptions{
+		DataShards:                 2,
+		ParityShards:               1,
+		Size:                       2 * GB,
+		Expiry:                     now.Add(720 * time.Hour).Unix(),
+		WritePrice:                 PriceRange{Min: 0, Max: 100},
+		MaxChallengeCompletionTime: time.Hour,
+	}
+
+	plan, err := planAllocation(opts, conf, candidates(), now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "d"}, plan.Request.PreferredBlobbers)
+	require.False(t, plan.Request.DiversifyBlobbers)
+	// 0.1 of the write price of 1 GB shards for a time unit
+	require.Equal(t, int64(1+2+3), plan.EstimatedMinLock)
+	require.Len(t, plan.Rejected, 2)
+	require.Contains(t, plan.Explain(), "rejected e (http://four.example:5051): write price 1000 out of range")
+	require.Equal(t, PriceRange{Min: 0, Max: 100}, plan.Request.WritePriceRange)
+	// the empty range is the one of the blobbers selected
+	require.Equal(t, PriceRange{Min: 1, Max: 1}, plan.Request.ReadPriceRange)
+
+	// the fastest blobbers whatever their price, in any price range
+	opts.Policy = PolicyLatency
+	opts.WritePrice = PriceRange{}
+	opts.MaxChallengeCompletionTime = 0
+	plan, err = planAllocation(opts, conf, candidates(), now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e", "c", "b"}, plan.Request.PreferredBlobbers)
+	require.Equal(t, PriceRange{Min: 10, Max: 1000}, plan.Request.WritePriceRange)
+	require.Equal(t, conf.MaxChallengeCompletionTime, plan.Request.MaxChallengeCompletionTime)
+	for _, b := range plan.Rejected {
+		require.Contains(t, b.Reason, "not needed")
+	}
+
+	opts.Diversify = true
+	opts.MaxLatency = 15 * time.Millisecond
+	_, err = planAllocation(opts, conf, candidates(), now)
+	require.Error(t, err)
+	opts.MaxLatency = 0
+	opts.ParityShards = 2
+	plan, err = planAllocation(opts, conf, candidates(), now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e", "c", "b", "d"}, plan.Request.PreferredBlobbers)
+	require.Contains(t, plan.Explain(), "rejected a (http://one.example:5051): same operator as b")
+	require.Contains(t, plan.Explain(), "note: region diversity not applied, no region given")
+
+	// b and c share a region, e is in none known
+	regions := map[string]string{"a": "eu", "b": "asia", "c": "asia", "d": "us"}
+	opts.Region = func(b *Blobber) string { return regions[string(b.ID)] }
+	opts.ParityShards = 1
+	plan, err = planAllocation(opts, conf, candidates(), now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e", "c", "a"}, plan.Request.PreferredBlobbers)
+	require.Contains(t, plan.Explain(), "rejected b (http://one.example:5052): same region as c")
+	require.Equal(t, []string{"region diversity not applied to e, region unknown"}, plan.Notes)
+	opts.Region = nil
+	opts.ParityShards = 2
+
+	opts.MinFreeStake = 5 * GB
+	_, err = planAllocation(opts, conf, candidates(), now)
+	require.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	. "github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"go.uber.org/zap"
+)
+
+// waitStatusCB waits for a download or an upload to end. The first
+// Completed or Error call ends it, later calls are ignored.
+type waitStatusCB struct {
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+func (cb *waitStatusCB) Started(allocationId, filePath string, op int, totalBytes int) {}
+
+func (cb *waitStatusCB) InProgress(allocationId, filePath string, op int, completedBytes int, data []byte) {
+}
+
+func (cb *waitStatusCB) CommitMetaCompleted(request, response string, err error) {}
+
+func (cb *waitStatusCB) RepairCompleted(filesRepaired int) {}
+
+func (cb *waitStatusCB) Completed(allocationId, filePath string, filename string, mimetype string, size int, op int) {
+	cb.done(nil)
+}
+
+func (cb *waitStatusCB) Error(allocationID string, filePath string, op int, err error) {
+	cb.done(err)
+}
+
+func (cb *waitStatusCB) done(err error) {
+	cb.once.Do(func() {
+		cb.err = err
+		cb.wg.Done()
+	})
+}
+
+// run starts the operation and waits for it to end.
+func (cb *waitStatusCB) run(op func(status StatusCallback) error) error {
+	cb.wg.Add(1)
+	if err := op(cb); err != nil {
+		cb.done(err)
+		return err
+	}
+	cb.wg.Wait()
+	return cb.err
+}
+
+// EncryptExisting encrypts the file uploaded unencrypted. Its content is
+// streamed from the blobbers back to them encrypted with the current
+// encryption key and a new tag.
+func (a *Allocation) EncryptExisting(path string) error {
+	return a.reencryptFile(path, true, false)
+}
+
+// DecryptExisting updates the encrypted file unencrypted. Its shares no
+// longer need a re-encryption key.
+func (a *Allocation) DecryptExisting(path string) error {
+	return a.reencryptFile(path, false, false)
+}
+
+// RotateEncryptionKey re-encrypts the encrypted file with the current
+// encryption key and a new tag, the re-encryption keys of its shares no
+// longer unlock it. Rotate the keyring first if the key was compromised.
+func (a *Allocation) RotateEncryptionKey(path string) error {
+	return a.reencryptFile(path, true, true)
+}
+
+// reencryptFile updates the file with its content, decrypted block by
+// block as it is downloaded and encrypted again as it is uploaded. The
+// content stays compressed and never touches the disk, the thumbnail is
+// held in memory.
+func (a *Allocation) reencryptFile(path string, encrypt, rotate bool) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	path = zboxutil.RemoteClean(path)
+	if !zboxutil.IsRemoteAbs(path) {
+		return errors.New("invalid_path", "Path should be valid and absolute")
+	}
+	meta, err := a.GetFileMeta(path)
+	if err != nil {
+		return err
+	}
+	if meta.Type != fileref.FILE {
+		return errors.New("invalid_path", "Path is not a file")
+	}
+	if meta.Dedup {
+		// its chunks are shared with other files
+		return errors.New("invalid_path", "File is deduplicated")
+	}
+	encrypted := len(meta.EncryptedKey) > 0
+	switch {
+	case encrypt && encrypted && !rotate:
+		return errors.New("already_encrypted", "File is already encrypted")
+	case (!encrypt || rotate) && !encrypted:
+		return errors.New("not_encrypted", "File is not encrypted")
+	}
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return err
+	}
+	ref, err := a.getFileRef(remotePath)
+	if err != nil {
+		return err
+	}
+
+	var thumbnail []byte
+	if ref.ActualThumbnailSize > 0 {
+		var buf bytes.Buffer
+		err = new(waitStatusCB).run(func(status StatusCallback) error {
+			return a.streamDownload(remotePath, DOWNLOAD_CONTENT_THUMB, &buf, status)
+		})
+		if err != nil {
+			return errors.Wrap(err, "thumbnail download failed")
+		}
+		thumbnail = buf.Bytes()
+	}
+
+	Logger.Info("Re-encrypting file", zap.String("path", path), zap.Bool("encrypt", encrypt))
+	pr, pw := io.Pipe()
+	downloaded := make(chan error, 1)
+	go func() {
+		err := new(waitStatusCB).run(func(status StatusCallback) error {
+			return a.streamDownload(remotePath, DOWNLOAD_CONTENT_FULL, pw, status)
+		})
+		pw.CloseWithError(err)
+		downloaded <- err
+	}()
+	content := &verifiedReader{r: pr, remaining: ref.ActualFileSize, verified: downloaded}
+	err = new(waitStatusCB).run(func(status StatusCallback) error {
+		uploadReq := a.newUploadRequest(remotePath, ref.ActualFileSize, int64(len(thumbnail)),
+			true, ref.Attributes, status)
+		uploadReq.reader = content
+		if len(thumbnail) > 0 {
+			uploadReq.thumbnailReader = bytes.NewReader(thumbnail)
+		}
+		uploadReq.filemeta.MimeType = meta.MimeType
+		uploadReq.filemeta.Compression = compressionMetaOf(ref)
+		uploadReq.isEncrypted = encrypt
+		go func() { a.uploadChan <- uploadReq }()
+		return nil
+	})
+	// stops the download if the upload failed
+	pr.CloseWithError(err)
+	if err != nil {
+		return errors.Wrap(err, "update failed")
+	}
+	return nil
+}
+
+// streamDownload downloads the stored content of the remote path to w.
+func (a *Allocation) streamDownload(remotePath, contentMode string, w io.Writer,
+	status StatusCallback) error {
+	if len(a.Blobbers) <= 1 {
+		return noBLOBBERS
+	}
+	downloadReq := a.newDownloadRequest(remotePath, contentMode, status)
+	downloadReq.writer = w
+	go func() { a.downloadChan <- downloadReq }()
+	return nil
+}
+
+// verifiedReader reads the downloaded content, its last bytes are only
+// returned once the download checked the content hash: the update isn't
+// committed with a content the blobbers didn't agree on.
+type verifiedReader struct {
+	r         io.Reader
+	remaining int64
+	verified  <-chan error
+}
+
+func (vr *verifiedReader) Read(p []byte) (int, error) {
+	if vr.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > vr.remaining {
+		p = p[:vr.remaining]
+	}
+	n, err := vr.r.Read(p)
+	vr.remaining -= int64(n)
+	if err == nil && vr.remaining == 0 {
+		err = <-vr.verified
+	}
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	return n, err
+}
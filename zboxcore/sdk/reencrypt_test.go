@@ -0,0 +1,260 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/blockchain"
+	zclient "github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/encoder"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/mocks"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocation_reencryptFile(t *testing.T) {
+	var mockClient = mocks.HttpClient{}
+	zboxutil.Client = &mockClient
+
+	client := zclient.GetClient()
+	client.Wallet = &zcncrypto.Wallet{
+		ClientID:  mockClientId,
+		ClientKey: mockClientKey,
+	}
+
+	tests := []struct {
+		name      string
+		ref       fileref.FileRef
+		reencrypt func(a *Allocation, path string) error
+		errMsg    string
+	}{
+		{
+			name:      "Test_Encrypt_Encrypted_Failed",
+			ref:       fileref.FileRef{Ref: fileref.Ref{Type: fileref.FILE}, EncryptedKey: "key"},
+			reencrypt: (*Allocation).EncryptExisting,
+			errMsg:    "already_encrypted: File is already encrypted",
+		},
+		{
+			name:      "Test_Decrypt_Unencrypted_Failed",
+			ref:       fileref.FileRef{Ref: fileref.Ref{Type: fileref.FILE}},
+			reencrypt: (*Allocation).DecryptExisting,
+			errMsg:    "not_encrypted: File is not encrypted",
+		},
+		{
+			name:      "Test_Rotate_Unencrypted_Failed",
+			ref:       fileref.FileRef{Ref: fileref.Ref{Type: fileref.FILE}},
+			reencrypt: (*Allocation).RotateEncryptionKey,
+			errMsg:    "not_encrypted: File is not encrypted",
+		},
+		{
+			name:      "Test_Directory_Failed",
+			ref:       fileref.FileRef{Ref: fileref.Ref{Type: fileref.DIRECTORY}},
+			reencrypt: (*Allocation).EncryptExisting,
+			errMsg:    "invalid_path: Path is not a file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Allocation{
+				DataShards:   2,
+				ParityShards: 2,
+			}
+			a.InitAllocation()
+			sdkInitialized = true
+			for i := 0; i < numBlobbers; i++ {
+				a.Blobbers = append(a.Blobbers, &blockchain.StorageNode{
+					ID:      tt.name + mockBlobberId + strconv.Itoa(i),
+					Baseurl: "TestAllocation_reencryptFile" + tt.name + mockBlobberUrl + strconv.Itoa(i),
+				})
+			}
+			body, err := json.Marshal(&tt.ref)
+			require.NoError(t, err)
+			setupMockHttpResponse(t, &mockClient, "TestAllocation_reencryptFile", tt.name, a, http.MethodPost, http.StatusOK, body)
+
+			err = tt.reencrypt(a, "/1.txt")
+			require.Error(t, err)
+			require.EqualValues(t, tt.errMsg, errors.Top(err))
+		})
+	}
+}
+
+func TestWaitStatusCB(t *testing.T) {
+	failed := errors.New("failed", "upload failed")
+	err := new(waitStatusCB).run(func(status StatusCallback) error {
+		go func() {
+			status.Error("", "", OpUpload, failed)
+			status.Error("", "", OpUpload, errors.New("failed", "again"))
+			status.Completed("", "", "", "", 0, OpUpload)
+		}()
+		return nil
+	})
+	require.Equal(t, failed, err)
+
+	err = new(waitStatusCB).run(func(status StatusCallback) error {
+		status.Error("", "", OpUpload, failed)
+		return failed
+	})
+	require.Equal(t, failed, err)
+
+	err = new(waitStatusCB).run(func(status StatusCallback) error {
+		status.Completed("", "", "", "", 0, OpUpload)
+		status.Error("", "", OpUpload, failed)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// pushEncrypted pushes the content as the upload does, encrypted with the
+// current key, and returns the chunks of each shard and the encrypted key.
+func pushEncrypted(t *testing.T, content io.Reader, size int64) ([][][]byte, string) {
+	req := &UploadRequest{datashards: 2, parityshards: 2, isEncrypted: true}
+	req.filemeta = &UploadFileMeta{Size: size}
+	req.remaining = size
+	req.reader = content
+	req.setUploadMask(4)
+	req.fileHash = sha1.New()
+	req.fileHashWr = req.fileHash
+	var err error
+	req.encscheme, req.encKeyID, err = encryptionScheme()
+	require.NoError(t, err)
+	req.encTag, err = encryption.NewFileTag()
+	require.NoError(t, err)
+	req.encscheme.InitForEncryption(req.encTag)
+	req.encVersion = encryption.SchemeChunkVersion(req.encscheme)
+
+	shards := make([][][]byte, 4)
+	var wg sync.WaitGroup
+	for i := range shards {
+		ch := make(chan []byte)
+		req.uploadDataCh = append(req.uploadDataCh, ch)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for chunk := range ch {
+				shards[i] = append(shards[i], chunk)
+			}
+		}(i)
+	}
+	perShard := (size + 1) / 2
+	chunkSize := int64(fileref.CHUNK_SIZE) - req.chunkOverhead()
+	chunksPerShard := (perShard + chunkSize - 1) / chunkSize
+	r := io.MultiReader(req.reader, bytes.NewReader(make([]byte, 2*perShard-size)))
+	for ctr := int64(0); ctr < chunksPerShard; ctr++ {
+		remaining := perShard - ctr*chunkSize
+		if remaining > chunkSize {
+			remaining = chunkSize
+		}
+		data := make([]byte, 2*remaining)
+		_, err = io.ReadFull(r, data)
+		require.NoError(t, err)
+		require.NoError(t, req.pushData(data, ctr, ctr == chunksPerShard-1))
+	}
+	for _, ch := range req.uploadDataCh {
+		close(ch)
+	}
+	wg.Wait()
+	return shards, encryption.FormatEncryptedKey(req.encKeyID, req.encscheme.GetEncryptedKey(),
+		req.encTag, req.encVersion)
+}
+
+// decryptShards writes the content of the shards decrypted block by block
+// as the download does.
+func decryptShards(shards [][][]byte, encryptedKey string, size int64, w io.Writer) error {
+	encscheme, err := decryptionScheme(encryptedKey)
+	if err != nil {
+		return err
+	}
+	if err = encscheme.InitForDecryption(encryption.FileTag(encryptedKey), encryptedKey); err != nil {
+		return err
+	}
+	erasureencoder, err := encoder.NewEncoder(2, 2)
+	if err != nil {
+		return err
+	}
+	chunks := int64(len(shards[0]))
+	for ctr := int64(0); ctr < chunks; ctr++ {
+		block := make([][]byte, len(shards))
+		for i := range shards {
+			block[i], err = encryption.DecryptChunk(encscheme, encryption.FileChunkVersion(encryptedKey),
+				shards[i][ctr], encryption.ChunkPosition{Shard: i, Index: ctr, Final: ctr == chunks-1}, "")
+			if err != nil {
+				return err
+			}
+		}
+		data, err := erasureencoder.Decode(block, len(block[0]))
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) > size {
+			data = data[:size]
+		}
+		size -= int64(len(data))
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestReencryptStream(t *testing.T) {
+	defer SetEncryptionKeyring(GetEncryptionKeyring())
+	w := setupEncryptionTestClient(t)
+	kr, err := encryption.NewKeyring()
+	require.NoError(t, err)
+	require.NoError(t, kr.ImportMnemonic(w.Mnemonic))
+	SetEncryptionKeyring(kr)
+
+	// a few chunks per shard, the last one short
+	content := bytes.Repeat([]byte("0chain re-encryption "), 20000)
+	size := int64(len(content))
+	oldShards, oldKey := pushEncrypted(t, bytes.NewReader(content), size)
+	_, err = kr.Rotate()
+	require.NoError(t, err)
+
+	// decrypted with the old key block by block into the upload
+	pr, pw := io.Pipe()
+	downloaded := make(chan error, 1)
+	go func() {
+		err := decryptShards(oldShards, oldKey, size, pw)
+		pw.CloseWithError(err)
+		downloaded <- err
+	}()
+	newShards, newKey := pushEncrypted(t, &verifiedReader{r: pr, remaining: size, verified: downloaded}, size)
+	oldKeyID, _, oldTag := encryption.ParseEncryptedKey(oldKey)
+	newKeyID, newEncKey, newTag := encryption.ParseEncryptedKey(newKey)
+	require.NotEqual(t, oldKeyID, newKeyID)
+	require.NotEqual(t, oldTag, newTag)
+
+	var out bytes.Buffer
+	require.NoError(t, decryptShards(newShards, newKey, size, &out))
+	require.Equal(t, content, out.Bytes())
+
+	// the old key doesn't decrypt the new chunks
+	withOldKey := encryption.FormatEncryptedKey(oldKeyID, newEncKey, newTag, encryption.FileChunkVersion(newKey))
+	require.Error(t, decryptShards(newShards, withOldKey, size, ioutil.Discard))
+}
+
+func TestVerifiedReader(t *testing.T) {
+	failed := errors.New("download_failed", "content hash mismatch")
+	verified := make(chan error, 1)
+	verified <- failed
+	vr := &verifiedReader{r: bytes.NewReader([]byte("content")), remaining: 7, verified: verified}
+	p := make([]byte, 4)
+	n, err := vr.Read(p)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	// the last bytes wait for the download to end
+	_, err = io.ReadFull(vr, p[:3])
+	require.Equal(t, failed, err)
+}
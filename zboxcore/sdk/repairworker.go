@@ -109,7 +109,13 @@ func (r *RepairRequest) repairFile(a *Allocation, file *ListResult) {
 		return
 	}
 	Logger.Info("Checking file for the path :", zap.Any("path", file.Path))
-	found, repairRequired, _, err := a.RepairRequired(file.Path)
+	found, repairRequired, _, err := a.repairRequired(file.Path)
+	if err != nil {
+		Logger.Error("repair_required_failed", zap.Error(err))
+		return
+	}
+	// the listing has the names as blobbers store them
+	path, err := a.plainPath(file.Path)
 	if err != nil {
 		Logger.Error("repair_required_failed", zap.Error(err))
 		return
@@ -125,7 +131,7 @@ func (r *RepairRequest) repairFile(a *Allocation, file *ListResult) {
 				statusCB: r.statusCB,
 			}
 
-			localPath := r.getLocalPath(file)
+			localPath := r.getLocalPath(path)
 
 			if !checkFileExists(localPath) {
 				if r.checkForCancel(a) {
@@ -133,7 +139,7 @@ func (r *RepairRequest) repairFile(a *Allocation, file *ListResult) {
 				}
 				Logger.Info("Downloading file for the path :", zap.Any("path", file.Path))
 				wg.Add(1)
-				err = a.DownloadFile(localPath, file.Path, statusCB)
+				err = a.DownloadFile(localPath, path, statusCB)
 				if err != nil {
 					Logger.Error("download_file_failed", zap.Error(err))
 					return
@@ -154,7 +160,7 @@ func (r *RepairRequest) repairFile(a *Allocation, file *ListResult) {
 
 			Logger.Info("Repairing file for the path :", zap.Any("path", file.Path))
 			wg.Add(1)
-			err = a.RepairFile(localPath, file.Path, statusCB)
+			err = a.RepairFile(localPath, path, statusCB)
 			if err != nil {
 				Logger.Error("repair_file_failed", zap.Error(err))
 				return
@@ -181,8 +187,8 @@ func (r *RepairRequest) repairFile(a *Allocation, file *ListResult) {
 	return
 }
 
-func (r *RepairRequest) getLocalPath(file *ListResult) string {
-	return r.localRootPath + file.Path
+func (r *RepairRequest) getLocalPath(path string) string {
+	return r.localRootPath + path
 }
 
 func checkFileExists(localPath string) bool {
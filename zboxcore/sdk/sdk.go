@@ -20,12 +20,11 @@ import (
 	"github.com/0chain/gosdk/core/version"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
 	"github.com/0chain/gosdk/zboxcore/client"
-	"github.com/0chain/gosdk/zboxcore/encryption"
 	. "github.com/0chain/gosdk/zboxcore/logger"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
 )
 
-const STORAGE_SCADDRESS = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d7"
+const STORAGE_SCADDRESS = transaction.StorageSmartContractAddress
 
 var sdkNotInitialized = errors.New("sdk_not_initialized", "SDK is not initialised")
 
@@ -81,10 +80,24 @@ func InitStorageSDK(clientJson string, blockWorker string, chainID string, signa
 	if err != nil {
 		return err
 	}
+	return initStorageSDK(blockWorker, chainID, preferredBlobbers)
+}
+
+// InitEncryptedStorageSDK initializes the SDK with a wallet json string,
+// plain or encrypted with the passphrase.
+func InitEncryptedStorageSDK(clientJson, passphrase string, blockWorker string, chainID string, signatureScheme string, preferredBlobbers []string) error {
+	err := client.PopulateEncryptedClient(clientJson, passphrase, signatureScheme)
+	if err != nil {
+		return err
+	}
+	return initStorageSDK(blockWorker, chainID, preferredBlobbers)
+}
+
+func initStorageSDK(blockWorker string, chainID string, preferredBlobbers []string) error {
 	blockchain.SetChainID(chainID)
 	blockchain.SetPreferredBlobbers(preferredBlobbers)
 	blockchain.SetBlockWorker(blockWorker)
-	err = UpdateNetworkDetails()
+	err := UpdateNetworkDetails()
 	if err != nil {
 		return err
 	}
@@ -185,20 +198,11 @@ func GetReadPoolInfo(clientID string) (info *AllocationPoolStats, err error) {
 		clientID = client.GetClientID()
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/getReadPoolStat",
-		map[string]string{"client_id": clientID}, nil)
+	info = new(AllocationPoolStats)
+	err = storageSC.Query("getReadPoolStat", map[string]string{"client_id": clientID}, info)
 	if err != nil {
 		return nil, errors.Wrap(err, "error requesting read pool info")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
-	info = new(AllocationPoolStats)
-	if err = json.Unmarshal(b, info); err != nil {
-		return nil, errors.Wrap(err, "error decoding response:")
-	}
 
 	return
 }
@@ -329,20 +333,11 @@ func GetStakePoolInfo(blobberID string) (info *StakePoolInfo, err error) {
 		blobberID = client.GetClientID()
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/getStakePoolStat",
-		map[string]string{"blobber_id": blobberID}, nil)
+	info = new(StakePoolInfo)
+	err = storageSC.Query("getStakePoolStat", map[string]string{"blobber_id": blobberID}, info)
 	if err != nil {
 		return nil, errors.Wrap(err, "error requesting stake pool info:")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
-	info = new(StakePoolInfo)
-	if err = json.Unmarshal(b, info); err != nil {
-		return nil, errors.Wrap(err, "error decoding response:")
-	}
 
 	return
 }
@@ -362,20 +357,11 @@ func GetStakePoolUserInfo(clientID string) (info *StakePoolUserInfo, err error)
 		clientID = client.GetClientID()
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS,
-		"/getUserStakePoolStat", map[string]string{"client_id": clientID}, nil)
+	info = new(StakePoolUserInfo)
+	err = storageSC.Query("getUserStakePoolStat", map[string]string{"client_id": clientID}, info)
 	if err != nil {
 		return nil, errors.Wrap(err, "error requesting stake pool user info:")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
-	info = new(StakePoolUserInfo)
-	if err = json.Unmarshal(b, info); err != nil {
-		return nil, errors.Wrap(err, "error decoding response:")
-	}
 
 	return
 }
@@ -484,20 +470,11 @@ func GetWritePoolInfo(clientID string) (info *AllocationPoolStats, err error) {
 		clientID = client.GetClientID()
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/getWritePoolStat",
-		map[string]string{"client_id": clientID}, nil)
+	info = new(AllocationPoolStats)
+	err = storageSC.Query("getWritePoolStat", map[string]string{"client_id": clientID}, info)
 	if err != nil {
 		return nil, errors.Wrap(err, "error requesting read pool info:")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
-	info = new(AllocationPoolStats)
-	if err = json.Unmarshal(b, info); err != nil {
-		return nil, errors.Wrap(err, "error decoding response:")
-	}
 
 	return
 }
@@ -568,21 +545,11 @@ func GetChallengePoolInfo(allocID string) (info *ChallengePoolInfo, err error) {
 		return nil, sdkNotInitialized
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS,
-		"/getChallengePoolStat", map[string]string{"allocation_id": allocID},
-		nil)
+	info = new(ChallengePoolInfo)
+	err = storageSC.Query("getChallengePoolStat", map[string]string{"allocation_id": allocID}, info)
 	if err != nil {
 		return nil, errors.Wrap(err, "error requesting challenge pool info:")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
-	info = new(ChallengePoolInfo)
-	if err = json.Unmarshal(b, info); err != nil {
-		return nil, errors.Wrap(err, "error decoding response:")
-	}
 
 	return
 }
@@ -641,20 +608,11 @@ func GetStorageSCConfig() (conf *StorageSCConfig, err error) {
 		return nil, sdkNotInitialized
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/getConfig", nil,
-		nil)
+	conf = new(StorageSCConfig)
+	err = storageSC.Query("getConfig", nil, conf)
 	if err != nil {
 		return nil, errors.Wrap(err, "error requesting storage SC configs:")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
-	conf = new(StorageSCConfig)
-	if err = json.Unmarshal(b, conf); err != nil {
-		return nil, errors.Wrap(err, "rror decoding response:")
-	}
 
 	if conf.ReadPool == nil || conf.WritePool == nil || conf.StakePool == nil {
 		return nil, errors.New("invalid confg: missing read/write/stake pool configs")
@@ -678,24 +636,14 @@ func GetBlobbers() (bs []*Blobber, err error) {
 		return nil, sdkNotInitialized
 	}
 
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/getblobbers", nil,
-		nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "error requesting blobbers:")
-	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response")
-	}
-
 	type nodes struct {
 		Nodes []*Blobber
 	}
 
 	var wrap nodes
 
-	if err = json.Unmarshal(b, &wrap); err != nil {
-		return nil, errors.Wrap(err, "error decoding response:")
+	if err = storageSC.Query("getblobbers", nil, &wrap); err != nil {
+		return nil, errors.Wrap(err, "error requesting blobbers:")
 	}
 
 	return wrap.Nodes, nil
@@ -706,22 +654,11 @@ func GetBlobber(blobberID string) (blob *Blobber, err error) {
 	if !sdkInitialized {
 		return nil, sdkNotInitialized
 	}
-	var b []byte
-	b, err = zboxutil.MakeSCRestAPICall(
-		STORAGE_SCADDRESS,
-		"/getBlobber",
-		map[string]string{"blobber_id": blobberID},
-		nil)
+	blob = new(Blobber)
+	err = storageSC.Query("getBlobber", map[string]string{"blobber_id": blobberID}, blob)
 	if err != nil {
 		return nil, errors.Wrap(err, "requesting blobber:")
 	}
-	if len(b) == 0 {
-		return nil, errors.New("empty response from sharders")
-	}
-	blob = new(Blobber)
-	if err = json.Unmarshal(b, blob); err != nil {
-		return nil, errors.Wrap(err, "decoding response:")
-	}
 	return
 }
 
@@ -733,8 +670,7 @@ func GetClientEncryptedPublicKey() (string, error) {
 	if !sdkInitialized {
 		return "", sdkNotInitialized
 	}
-	encScheme := encryption.NewEncryptionScheme()
-	err := encScheme.Initialize(client.GetClient().Mnemonic)
+	encScheme, _, err := encryptionScheme()
 	if err != nil {
 		return "", err
 	}
@@ -763,14 +699,10 @@ func GetAllocation(allocationID string) (*Allocation, error) {
 	}
 	params := make(map[string]string)
 	params["allocation"] = allocationID
-	allocationBytes, err := zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/allocation", params, nil)
-	if err != nil {
-		return nil, errors.New("allocation_fetch_error", "Error fetching the allocation."+err.Error())
-	}
 	allocationObj := &Allocation{}
-	err = json.Unmarshal(allocationBytes, allocationObj)
+	err := storageSC.Query("allocation", params, allocationObj)
 	if err != nil {
-		return nil, errors.New("allocation_decode_error", "Error decoding the allocation."+err.Error())
+		return nil, errors.New("allocation_fetch_error", "Error fetching the allocation."+err.Error())
 	}
 	allocationObj.numBlockDownloads = numBlockDownloads
 	allocationObj.InitAllocation()
@@ -794,14 +726,10 @@ func GetAllocationsForClient(clientID string) ([]*Allocation, error) {
 	}
 	params := make(map[string]string)
 	params["client"] = clientID
-	allocationsBytes, err := zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/allocations", params, nil)
-	if err != nil {
-		return nil, errors.New("allocations_fetch_error", "Error fetching the allocations."+err.Error())
-	}
 	allocations := make([]*Allocation, 0)
-	err = json.Unmarshal(allocationsBytes, &allocations)
+	err := storageSC.Query("allocations", params, &allocations)
 	if err != nil {
-		return nil, errors.New("allocations_decode_error", "Error decoding the allocations."+err.Error())
+		return nil, errors.New("allocations_fetch_error", "Error fetching the allocations."+err.Error())
 	}
 	return allocations, nil
 }
@@ -816,32 +744,51 @@ func CreateAllocation(datashards, parityshards int, size, expiry int64,
 		blockchain.GetPreferredBlobbers())
 }
 
+// CreateAllocationRequest is the request of a new allocation.
+type CreateAllocationRequest struct {
+	DataShards                 int           `json:"data_shards"`
+	ParityShards               int           `json:"parity_shards"`
+	Size                       int64         `json:"size"`
+	OwnerID                    string        `json:"owner_id"`
+	OwnerPublicKey             string        `json:"owner_public_key"`
+	Expiration                 int64         `json:"expiration_date"`
+	PreferredBlobbers          []string      `json:"preferred_blobbers"`
+	ReadPriceRange             PriceRange    `json:"read_price_range"`
+	WritePriceRange            PriceRange    `json:"write_price_range"`
+	MaxChallengeCompletionTime time.Duration `json:"max_challenge_completion_time"`
+	DiversifyBlobbers          bool          `json:"diversify_blobbers"`
+}
+
 func CreateAllocationForOwner(owner, ownerpublickey string,
 	datashards, parityshards int, size, expiry int64,
 	readPrice, writePrice PriceRange, mcct time.Duration,
 	lock int64, preferredBlobbers []string) (hash string, err error) {
 
+	return CreateAllocationWithRequest(&CreateAllocationRequest{
+		DataShards:                 datashards,
+		ParityShards:               parityshards,
+		Size:                       size,
+		OwnerID:                    owner,
+		OwnerPublicKey:             ownerpublickey,
+		Expiration:                 expiry,
+		PreferredBlobbers:          preferredBlobbers,
+		ReadPriceRange:             readPrice,
+		WritePriceRange:            writePrice,
+		MaxChallengeCompletionTime: mcct,
+		DiversifyBlobbers:          true,
+	}, lock)
+}
+
+// CreateAllocationWithRequest sends the new allocation request, for
+// example the one of an AllocationPlan.
+func CreateAllocationWithRequest(req *CreateAllocationRequest, lock int64) (hash string, err error) {
 	if !sdkInitialized {
 		return "", sdkNotInitialized
 	}
 
-	var allocationRequest = map[string]interface{}{
-		"data_shards":                   datashards,
-		"parity_shards":                 parityshards,
-		"size":                          size,
-		"owner_id":                      owner,
-		"owner_public_key":              ownerpublickey,
-		"expiration_date":               expiry,
-		"preferred_blobbers":            preferredBlobbers,
-		"read_price_range":              readPrice,
-		"write_price_range":             writePrice,
-		"max_challenge_completion_time": mcct,
-		"diversify_blobbers":            true,
-	}
-
 	var sn = transaction.SmartContractTxnData{
 		Name:      transaction.NEW_ALLOCATION_REQUEST,
-		InputArgs: allocationRequest,
+		InputArgs: req,
 	}
 	hash, _, err = smartContractTxnValue(sn, lock)
 	return
@@ -908,6 +855,29 @@ func UpdateAllocation(size int64, expiry int64, allocationID string,
 	return
 }
 
+// ReplaceAllocationBlobber swaps the blobber of the allocation for the new
+// one. The new blobber holds no data until the allocation is migrated.
+func ReplaceAllocationBlobber(allocationID, removeBlobberID, addBlobberID string,
+	lock int64) (hash string, err error) {
+
+	if !sdkInitialized {
+		return "", sdkNotInitialized
+	}
+
+	updateAllocationRequest := make(map[string]interface{})
+	updateAllocationRequest["owner_id"] = client.GetClientID()
+	updateAllocationRequest["id"] = allocationID
+	updateAllocationRequest["add_blobber_id"] = addBlobberID
+	updateAllocationRequest["remove_blobber_id"] = removeBlobberID
+
+	sn := transaction.SmartContractTxnData{
+		Name:      transaction.STORAGESC_UPDATE_ALLOCATION,
+		InputArgs: updateAllocationRequest,
+	}
+	hash, _, err = smartContractTxnValue(sn, lock)
+	return
+}
+
 func CreateFreeUpdateAllocation(marker, allocationId string, value int64) (string, error) {
 	if !sdkInitialized {
 		return "", sdkNotInitialized
@@ -1012,53 +982,8 @@ func smartContractTxnValue(sn transaction.SmartContractTxnData, value int64) (
 func smartContractTxnValueFee(sn transaction.SmartContractTxnData,
 	value, fee int64) (hash, out string, err error) {
 
-	var requestBytes []byte
-	if requestBytes, err = json.Marshal(sn); err != nil {
-		return
-	}
-
-	var txn = transaction.NewTransactionEntity(client.GetClientID(),
-		blockchain.GetChainID(), client.GetClientPublicKey())
-
-	txn.TransactionData = string(requestBytes)
-	txn.ToClientID = STORAGE_SCADDRESS
-	txn.Value = value
-	txn.TransactionFee = fee
-	txn.TransactionType = transaction.TxnTypeSmartContract
-
-	if err = txn.ComputeHashAndSign(client.Sign); err != nil {
-		return
-	}
-
-	transaction.SendTransactionSync(txn, blockchain.GetMiners())
-
-	var (
-		querySleepTime = time.Duration(blockchain.GetQuerySleepTime()) * time.Second
-		retries        = 0
-		t              *transaction.Transaction
-	)
-	time.Sleep(querySleepTime)
-
-	for retries < blockchain.GetMaxTxnQuery() {
-		t, err = transaction.VerifyTransaction(txn.Hash, blockchain.GetSharders())
-		if err == nil {
-			break
-		}
-		retries++
-		time.Sleep(querySleepTime)
-	}
-
-	if err != nil {
-		Logger.Error("Error verifying the transaction", err.Error(), txn.Hash)
-		return
-	}
-
-	if t == nil {
-		return "", "", errors.New("transaction_validation_failed",
-			"Failed to get the transaction confirmation")
-	}
-
-	return t.Hash, t.TransactionOutput, nil
+	hash, err = storageSC.Call(sn.Name, sn.InputArgs, value, fee, &out)
+	return
 }
 
 func CommitToFabric(metaTxnData, fabricConfigJSON string) (string, error) {
@@ -1126,36 +1051,36 @@ func CommitToFabric(metaTxnData, fabricConfigJSON string) (string, error) {
 
 func GetAllocationMinLock(datashards, parityshards int, size, expiry int64,
 	readPrice, writePrice PriceRange, mcct time.Duration) (int64, error) {
+	return GetAllocationRequestMinLock(&CreateAllocationRequest{
+		DataShards:                 datashards,
+		ParityShards:               parityshards,
+		Size:                       size,
+		OwnerID:                    client.GetClientID(),
+		OwnerPublicKey:             client.GetClientPublicKey(),
+		Expiration:                 expiry,
+		PreferredBlobbers:          blockchain.GetPreferredBlobbers(),
+		ReadPriceRange:             readPrice,
+		WritePriceRange:            writePrice,
+		MaxChallengeCompletionTime: mcct,
+		DiversifyBlobbers:          true,
+	})
+}
+
+// GetAllocationRequestMinLock returns the minimum lock of the new
+// allocation request.
+func GetAllocationRequestMinLock(req *CreateAllocationRequest) (int64, error) {
 	if !sdkInitialized {
 		return 0, sdkNotInitialized
 	}
 
-	var allocationRequestData = map[string]interface{}{
-		"data_shards":                   datashards,
-		"parity_shards":                 parityshards,
-		"size":                          size,
-		"owner_id":                      client.GetClientID(),
-		"owner_public_key":              client.GetClientPublicKey(),
-		"expiration_date":               expiry,
-		"preferred_blobbers":            blockchain.GetPreferredBlobbers(),
-		"read_price_range":              readPrice,
-		"write_price_range":             writePrice,
-		"max_challenge_completion_time": mcct,
-		"diversify_blobbers":            true,
-	}
-	allocationData, _ := json.Marshal(allocationRequestData)
+	allocationData, _ := json.Marshal(req)
 
 	params := make(map[string]string)
 	params["allocation_data"] = string(allocationData)
-	allocationsBytes, err := zboxutil.MakeSCRestAPICall(STORAGE_SCADDRESS, "/allocation_min_lock", params, nil)
-	if err != nil {
-		return 0, errors.New("allocation_min_lock_fetch_error", "Error fetching the allocation min lock."+err.Error())
-	}
-
 	var response = make(map[string]int64)
-	err = json.Unmarshal(allocationsBytes, &response)
+	err := storageSC.Query("allocation_min_lock", params, &response)
 	if err != nil {
-		return 0, errors.New("allocation_min_lock_decode_error", "Error decoding the response."+err.Error())
+		return 0, errors.New("allocation_min_lock_fetch_error", "Error fetching the allocation min lock."+err.Error())
 	}
 	return response["min_lock_demand"], nil
 }
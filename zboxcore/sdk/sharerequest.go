@@ -23,9 +23,16 @@ type ShareRequest struct {
 	authToken      *marker.AuthTicket
 	refType        string
 	ctx            context.Context
+	// nameKey is the sealed key of the encrypted names of the path.
+	nameKey string
+	// encKeyID is the ID of the recipient key, see AuthTicket.EncryptionKeyID.
+	encKeyID string
 }
 
 func (req *ShareRequest) GetAuthTicketForEncryptedFile(clientID string, encPublicKey string) (string, error) {
+	// an invalid public key fails re-keying below, files which are not
+	// encrypted are shared without it
+	req.encKeyID, _ = encryption.KeyIDOf(encPublicKey)
 	at := &marker.AuthTicket{}
 	at.AllocationID = req.allocationID
 	at.OwnerID = client.GetClientID()
@@ -33,6 +40,8 @@ func (req *ShareRequest) GetAuthTicketForEncryptedFile(clientID string, encPubli
 	at.FileName = req.remotefilename
 	at.FilePathHash = fileref.GetReferenceLookup(req.allocationID, req.remotefilepath)
 	at.RefType = req.refType
+	at.NameKey = req.nameKey
+	at.EncryptionKeyID = req.encKeyID
 	timestamp := int64(common.Now())
 	at.Expiration = timestamp + 7776000
 	at.Timestamp = timestamp
@@ -53,13 +62,29 @@ func (req *ShareRequest) GetAuthTicketForEncryptedFile(clientID string, encPubli
 	if fileRef == nil {
 		return "", errors.New("file_meta_error", "Error getting object meta data from blobbers")
 	}
-	if fileRef.Type == fileref.DIRECTORY || len(fileRef.EncryptedKey) == 0 {
+	var (
+		encscheme encryption.EncryptionScheme
+		tag       string
+	)
+	switch {
+	case fileRef.Type == fileref.DIRECTORY:
+		// unlocks the files uploaded with the directory tag, encrypted
+		// with any key of the keyring
+		tag = encryption.DirectoryTag(at.FilePathHash)
+		if at.ReEncryptionKeys, err = directoryReKeys(encPublicKey, tag); err != nil {
+			return "", err
+		}
+		encscheme, _, err = encryptionScheme()
+	case len(fileRef.EncryptedKey) > 0:
+		encscheme, err = decryptionScheme(fileRef.EncryptedKey)
+		tag = encryption.FileTag(fileRef.EncryptedKey)
+	default:
 		return req.GetAuthTicket(clientID)
 	}
-	var encscheme encryption.EncryptionScheme
-	encscheme = encryption.NewEncryptionScheme()
-	encscheme.Initialize(client.GetClient().Mnemonic)
-	reKey, err := encscheme.GetReGenKey(encPublicKey, "filetype:audio")
+	if err != nil {
+		return "", err
+	}
+	reKey, err := encscheme.GetReGenKey(encPublicKey, tag)
 	if err != nil {
 		return "", err
 	}
@@ -76,6 +101,22 @@ func (req *ShareRequest) GetAuthTicketForEncryptedFile(clientID string, encPubli
 	return sEnc, nil
 }
 
+// directoryReKeys returns the re-encryption keys of the tag for the files
+// encrypted with each key, see AuthTicket.ReEncryptionKeys.
+func directoryReKeys(encPublicKey, tag string) (map[string]string, error) {
+	schemes, err := decryptionSchemes()
+	if err != nil {
+		return nil, err
+	}
+	reKeys := make(map[string]string, len(schemes))
+	for keyID, encscheme := range schemes {
+		if reKeys[keyID], err = encscheme.GetReGenKey(encPublicKey, tag); err != nil {
+			return nil, err
+		}
+	}
+	return reKeys, nil
+}
+
 func (req *ShareRequest) GetAuthTicket(clientID string) (string, error) {
 
 	at := &marker.AuthTicket{}
@@ -85,6 +126,8 @@ func (req *ShareRequest) GetAuthTicket(clientID string) (string, error) {
 	at.FileName = req.remotefilename
 	at.FilePathHash = fileref.GetReferenceLookup(req.allocationID, req.remotefilepath)
 	at.RefType = req.refType
+	at.NameKey = req.nameKey
+	at.EncryptionKeyID = req.encKeyID
 	timestamp := int64(common.Now())
 	at.Expiration = timestamp + 7776000
 	at.Timestamp = timestamp
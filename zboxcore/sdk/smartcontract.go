@@ -0,0 +1,86 @@
+package sdk
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/zboxcore/blockchain"
+	"github.com/0chain/gosdk/zboxcore/client"
+	. "github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// scTransport executes smart contract transactions with the client wallet
+// and waits for their confirmation, and queries the smart contract REST
+// endpoints of the sharders. Transactions rejected by the smart contract
+// return the typed error of their output.
+type scTransport struct{}
+
+func (scTransport) ExecuteSmartContract(address string,
+	sn *transaction.SmartContractTxnData, value, fee int64) (
+	hash, out string, err error) {
+
+	var requestBytes []byte
+	if requestBytes, err = json.Marshal(sn); err != nil {
+		return
+	}
+
+	var txn = transaction.NewTransactionEntity(client.GetClientID(),
+		blockchain.GetChainID(), client.GetClientPublicKey())
+
+	txn.TransactionData = string(requestBytes)
+	txn.ToClientID = address
+	txn.Value = value
+	txn.TransactionFee = fee
+	txn.TransactionType = transaction.TxnTypeSmartContract
+
+	if err = txn.ComputeHashAndSign(client.Sign); err != nil {
+		return
+	}
+
+	transaction.SendTransactionSync(txn, blockchain.GetMiners())
+
+	var (
+		querySleepTime = time.Duration(blockchain.GetQuerySleepTime()) * time.Second
+		retries        = 0
+		t              *transaction.Transaction
+	)
+	time.Sleep(querySleepTime)
+
+	for retries < blockchain.GetMaxTxnQuery() {
+		t, err = transaction.VerifyTransaction(txn.Hash, blockchain.GetSharders())
+		if err == nil {
+			break
+		}
+		retries++
+		time.Sleep(querySleepTime)
+	}
+
+	if err != nil {
+		Logger.Error("Error verifying the transaction", err.Error(), txn.Hash)
+		return
+	}
+
+	if t == nil {
+		return "", "", errors.New("transaction_validation_failed",
+			"Failed to get the transaction confirmation")
+	}
+
+	return t.Hash, t.TransactionOutput, t.OutputError()
+}
+
+func (scTransport) QuerySmartContract(address, path string,
+	params map[string]string) ([]byte, error) {
+
+	return zboxutil.MakeSCRestAPICall(address, path, params, nil)
+}
+
+var storageSC = NewSmartContractClient(transaction.StorageSmartContract)
+
+// NewSmartContractClient returns a client of the smart contract executing
+// transactions with the SDK client wallet.
+func NewSmartContractClient(sc *transaction.SmartContract) *transaction.SmartContractClient {
+	return transaction.NewSmartContractClient(sc, scTransport{}, scTransport{})
+}
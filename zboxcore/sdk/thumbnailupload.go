@@ -10,11 +10,12 @@ import (
 	"sync"
 
 	"github.com/0chain/gosdk/zboxcore/encoder"
+	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/fileref"
 	. "github.com/0chain/gosdk/zboxcore/logger"
 )
 
-func (req *UploadRequest) pushThumbnailData(data []byte) error {
+func (req *UploadRequest) pushThumbnailData(data []byte, index int64, final bool) error {
 	//TODO: Check for optimization
 	n := int64(math.Min(float64(req.thumbRemaining), float64(len(data))))
 	if !req.isRepair {
@@ -35,14 +36,12 @@ func (req *UploadRequest) pushThumbnailData(data []byte) error {
 	if req.isEncrypted {
 		for i := req.uploadMask; !i.Equals64(0); i = i.And(zboxutil.NewUint128(1).Lsh(pos).Not()) {
 			pos = uint64(i.TrailingZeros())
-			encMsg, err := req.encscheme.Encrypt(shards[pos])
+			shards[pos], err = encryption.EncryptChunk(req.encscheme, req.encVersion, shards[pos],
+				encryption.ChunkPosition{Shard: int(pos), Index: index, Final: final, Thumbnail: true})
 			if err != nil {
 				Logger.Error("Encryption failed.", err.Error())
 				return err
 			}
-			header := make([]byte, 2*1024)
-			copy(header[:], encMsg.MessageChecksum+","+encMsg.OverallChecksum)
-			shards[pos] = append(header, encMsg.EncryptedData...)
 			c++
 		}
 
@@ -58,10 +57,14 @@ func (req *UploadRequest) pushThumbnailData(data []byte) error {
 
 func (req *UploadRequest) processThumbnail(a *Allocation, wg *sync.WaitGroup) {
 	defer wg.Done()
-	var inFile *os.File
-	inFile, err := os.Open(req.thumbnailpath)
-	if err != nil {
-		return
+	var inFile io.Reader = req.thumbnailReader
+	if inFile == nil {
+		f, err := os.Open(req.thumbnailpath)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		inFile = f
 	}
 	size := req.filemeta.ThumbnailSize
 	// Calculate number of bytes per shard.
@@ -69,11 +72,7 @@ func (req *UploadRequest) processThumbnail(a *Allocation, wg *sync.WaitGroup) {
 	// Pad data to Shards*perShard.
 	padding := make([]byte, (int64(a.DataShards)*perShard)-size)
 	dataReader := io.MultiReader(inFile, bytes.NewBuffer(padding))
-	chunkSizeWithHeader := int64(fileref.CHUNK_SIZE)
-	if req.isEncrypted {
-		chunkSizeWithHeader -= 16
-		chunkSizeWithHeader -= 2 * 1024
-	}
+	chunkSizeWithHeader := int64(fileref.CHUNK_SIZE) - req.chunkOverhead()
 	chunksPerShard := (perShard + chunkSizeWithHeader - 1) / chunkSizeWithHeader
 	Logger.Info("Thumbnail Size:", size, " perShard:", perShard, " chunks/shard:", chunksPerShard)
 
@@ -81,17 +80,17 @@ func (req *UploadRequest) processThumbnail(a *Allocation, wg *sync.WaitGroup) {
 	for ctr := int64(0); ctr < chunksPerShard; ctr++ {
 		remaining := int64(math.Min(float64(perShard-(ctr*chunkSizeWithHeader)), float64(chunkSizeWithHeader)))
 		b1 := make([]byte, remaining*int64(a.DataShards))
-		_, err = dataReader.Read(b1)
+		_, err := io.ReadFull(dataReader, b1)
 		if err != nil {
 			return
 		}
-		err = req.pushThumbnailData(b1)
+		err = req.pushThumbnailData(b1, ctr, ctr == chunksPerShard-1)
 		if err != nil {
 			return
 		}
 		//sent = sent + int(remaining*int64(a.DataShards+a.ParityShards))
 	}
-	err = req.completeThumbnailPush()
+	err := req.completeThumbnailPush()
 	if err != nil {
 		return
 	}
@@ -20,7 +20,8 @@ import (
 	"github.com/0chain/gosdk/core/util"
 	"github.com/0chain/gosdk/zboxcore/allocationchange"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
-	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/compression"
+	"github.com/0chain/gosdk/zboxcore/dedup"
 	"github.com/0chain/gosdk/zboxcore/encoder"
 	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/fileref"
@@ -46,6 +47,11 @@ type UploadFileMeta struct {
 	ThumbnailSize int64
 	ThumbnailHash string
 	Attributes    fileref.Attributes
+	Compression   *compression.Meta
+	Dedup         *dedup.Meta
+	// CustomMeta is the custom meta of the file, the compression and the
+	// deduplication are merged into it.
+	CustomMeta string
 }
 
 type uploadFormData struct {
@@ -96,9 +102,22 @@ type UploadRequest struct {
 	uploadMask        zboxutil.Uint128
 	isEncrypted       bool
 	encscheme         encryption.EncryptionScheme
+	encKeyID          string
+	encTag            string
+	encVersion        int
+	names             *encryption.NameCipher
+	compression       string
+	contentPath       string
 	isUploadCanceled  bool
 	completedCallback func(filepath string)
 	err               error
+	// reader and thumbnailReader are uploaded instead of the local files,
+	// as they are: the mime type and the compression are set by the caller.
+	reader          io.Reader
+	thumbnailReader io.Reader
+	// encryptedKey is the one of the file whose shards are rebuilt, they
+	// are encrypted with its key as the blobbers' ones.
+	encryptedKey string
 	Consensus
 }
 
@@ -116,15 +135,9 @@ func (req *UploadRequest) prepareUpload(a *Allocation, blobber *blockchain.Stora
 	httpreq.Header.Add("Content-Type", formWriter.FormDataContentType())
 	var formData uploadFormData
 	shardSize := (req.filemeta.Size + int64(a.DataShards) - 1) / int64(a.DataShards)
-	chunkSizeWithHeader := int64(fileref.CHUNK_SIZE)
-	if req.isEncrypted {
-		chunkSizeWithHeader -= 16
-		chunkSizeWithHeader -= 2 * 1024
-	}
+	chunkSizeWithHeader := int64(fileref.CHUNK_SIZE) - req.chunkOverhead()
 	chunksPerShard := (shardSize + chunkSizeWithHeader - 1) / chunkSizeWithHeader
-	if req.isEncrypted {
-		shardSize += chunksPerShard * (16 + (2 * 1024))
-	}
+	shardSize += chunksPerShard * req.chunkOverhead()
 	thumbnailSize := int64(0)
 	remaining := shardSize
 	sent := 0
@@ -194,17 +207,11 @@ func (req *UploadRequest) prepareUpload(a *Allocation, blobber *blockchain.Stora
 		fileContentHash = hex.EncodeToString(h.Sum(nil))
 		fileMerkleRoot = mt.GetRoot()
 
-		if len(req.thumbnailpath) > 0 {
+		if req.hasThumbnail() {
 			thumbnailSize = (req.filemeta.ThumbnailSize + int64(a.DataShards) - 1) / int64(a.DataShards)
-			chunkSizeWithHeader := int64(fileref.CHUNK_SIZE)
-			if req.isEncrypted {
-				chunkSizeWithHeader -= 16
-				chunkSizeWithHeader -= 2 * 1024
-			}
+			chunkSizeWithHeader := int64(fileref.CHUNK_SIZE) - req.chunkOverhead()
 			chunksPerShard := (thumbnailSize + chunkSizeWithHeader - 1) / chunkSizeWithHeader
-			if req.isEncrypted {
-				thumbnailSize += chunksPerShard * (16 + (2 * 1024))
-			}
+			thumbnailSize += chunksPerShard * req.chunkOverhead()
 			remaining := thumbnailSize
 
 			fileField, err := formWriter.CreateFormFile("uploadThumbnailFile", file.Name+".thumb")
@@ -249,7 +256,16 @@ func (req *UploadRequest) prepareUpload(a *Allocation, blobber *blockchain.Stora
 			MerkleRoot:          fileMerkleRoot,
 		}
 		if req.isEncrypted {
-			formData.EncryptedKey = req.encscheme.GetEncryptedKey()
+			formData.EncryptedKey = encryption.FormatEncryptedKey(req.encKeyID, req.encscheme.GetEncryptedKey(),
+				req.encTag, req.encVersion)
+		}
+		formData.CustomMeta = req.filemeta.CustomMeta
+		if req.names != nil {
+			if formData.MimeType, err = req.names.EncryptMeta(formData.MimeType); err != nil {
+				Logger.Error("Mime type encryption failed: ", err)
+				bodyWriter.CloseWithError(err)
+				return
+			}
 		}
 		_ = formWriter.WriteField("connection_id", req.connectionID)
 		var metaData []byte
@@ -333,19 +349,19 @@ func (req *UploadRequest) setupUpload(a *Allocation) error {
 		req.file[i].Attributes = req.filemeta.Attributes
 	}
 
+	customMeta, err := mergeCustomMeta(req.filemeta.CustomMeta, req.filemeta.Compression, req.filemeta.Dedup)
+	if err != nil {
+		return err
+	}
+	req.filemeta.CustomMeta = customMeta
 	if !req.isRepair {
 		req.fileHash = sha1.New()
 		req.fileHashWr = io.MultiWriter(req.fileHash)
 		req.thumbnailHash = sha1.New()
 		req.thumbnailHashWr = io.MultiWriter(req.thumbnailHash)
 	}
-	if req.isEncrypted {
-		req.encscheme = encryption.NewEncryptionScheme()
-		err := req.encscheme.Initialize(client.GetClient().Mnemonic)
-		if err != nil {
-			return err
-		}
-		req.encscheme.InitForEncryption("filetype:audio")
+	if err = req.setupEncryption(); err != nil {
+		return err
 	}
 
 	req.wg = &sync.WaitGroup{}
@@ -362,7 +378,125 @@ func (req *UploadRequest) setupUpload(a *Allocation) error {
 	return nil
 }
 
-func (req *UploadRequest) pushData(data []byte) error {
+// setupEncryption sets the encryption scheme of the upload, with the key of
+// the file if its shards are rebuilt, else a new key.
+func (req *UploadRequest) setupEncryption() error {
+	var err error
+	switch {
+	case req.isEncrypted && req.encryptedKey != "":
+		if req.encscheme, err = decryptionScheme(req.encryptedKey); err != nil {
+			return err
+		}
+		if err = encryption.InitForFileKey(req.encscheme, req.encryptedKey); err != nil {
+			return err
+		}
+		req.encKeyID, _, req.encTag = encryption.ParseEncryptedKey(req.encryptedKey)
+		req.encVersion = encryption.FileChunkVersion(req.encryptedKey)
+	case req.isEncrypted:
+		req.encscheme, req.encKeyID, err = encryptionScheme()
+		if err != nil {
+			return err
+		}
+		if req.encTag == "" {
+			// a new tag per file, shares of the file don't unlock others
+			if req.encTag, err = encryption.NewFileTag(); err != nil {
+				return err
+			}
+		} else if err = encryption.ValidateTag(req.encTag); err != nil {
+			return err
+		}
+		req.encscheme.InitForEncryption(req.encTag)
+		req.encVersion = encryption.SchemeChunkVersion(req.encscheme)
+	}
+	return nil
+}
+
+// compress compresses the file to a temporary file, uploaded instead of
+// the file.
+func (req *UploadRequest) compress(frameSize int64) error {
+	inFile, err := os.Open(req.filepath)
+	if err != nil {
+		return err
+	}
+	defer inFile.Close()
+	outFile, err := ioutil.TempFile("", "zbox-upload")
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+	meta, err := compression.Compress(req.compression, inFile, outFile, frameSize)
+	if err != nil {
+		os.Remove(outFile.Name())
+		return err
+	}
+	if err = req.setContent(outFile.Name()); err != nil {
+		os.Remove(outFile.Name())
+		return err
+	}
+	req.filemeta.Compression = meta
+	return nil
+}
+
+// setContent uploads the file at the path instead of the local file, as
+// the compressed file or the recipe of a deduplicated file.
+func (req *UploadRequest) setContent(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	req.contentPath = path
+	req.filemeta.Size = info.Size()
+	req.remaining = info.Size()
+	return nil
+}
+
+// chunkOverhead returns the bytes encryption adds to each chunk.
+func (req *UploadRequest) chunkOverhead() int64 {
+	if !req.isEncrypted {
+		return 0
+	}
+	return encryption.ChunkOverhead(req.encVersion)
+}
+
+// openContent opens the content uploaded: the reader, the compressed file
+// or the recipe if any, else the local file.
+func (req *UploadRequest) openContent() (io.Reader, error) {
+	if req.reader != nil {
+		return req.reader, nil
+	}
+	inFile, err := os.Open(req.filepath)
+	if err != nil {
+		return nil, errors.New("open_file_failed", err.Error())
+	}
+	mimetype, err := zboxutil.GetFileContentType(inFile)
+	if err != nil {
+		inFile.Close()
+		return nil, errors.New("mime_type_error", err.Error())
+	}
+	req.filemeta.MimeType = mimetype
+	if req.compression != "" && req.contentPath == "" {
+		if err = req.compress(compression.DefaultFrameSize); err != nil {
+			inFile.Close()
+			return nil, errors.New("compression_failed", err.Error())
+		}
+	}
+	if req.contentPath == "" {
+		return inFile, nil
+	}
+	// the compressed file or the recipe is uploaded instead
+	inFile.Close()
+	if inFile, err = os.Open(req.contentPath); err != nil {
+		return nil, errors.New("open_file_failed", err.Error())
+	}
+	return inFile, nil
+}
+
+// hasThumbnail reports whether a thumbnail is uploaded with the file.
+func (req *UploadRequest) hasThumbnail() bool {
+	return len(req.thumbnailpath) > 0 || req.thumbnailReader != nil
+}
+
+func (req *UploadRequest) pushData(data []byte, index int64, final bool) error {
 	//TODO: Check for optimization
 	n := int64(math.Min(float64(req.remaining), float64(len(data))))
 	if !req.isRepair {
@@ -382,14 +516,12 @@ func (req *UploadRequest) pushData(data []byte) error {
 	if req.isEncrypted {
 		for i := req.uploadMask; !i.Equals64(0); i = i.And(zboxutil.NewUint128(1).Lsh(pos).Not()) {
 			pos = uint64(i.TrailingZeros())
-			encMsg, err := req.encscheme.Encrypt(shards[pos])
+			shards[pos], err = encryption.EncryptChunk(req.encscheme, req.encVersion, shards[pos],
+				encryption.ChunkPosition{Shard: int(pos), Index: index, Final: final})
 			if err != nil {
 				Logger.Error("Encryption failed.", err.Error())
 				return err
 			}
-			header := make([]byte, 2*1024)
-			copy(header[:], encMsg.MessageChecksum+","+encMsg.OverallChecksum)
-			shards[pos] = append(header, encMsg.EncryptedData...)
 			c++
 		}
 
@@ -422,27 +554,40 @@ func (req *UploadRequest) completePush() error {
 	return nil
 }
 
+// uploadError reports the failure of the upload to the status callback, if
+// any.
+func (req *UploadRequest) uploadError(a *Allocation, path string, err error) {
+	if req.statusCallback != nil {
+		req.statusCallback.Error(a.ID, path, OpUpload, err)
+	}
+}
+
 func (req *UploadRequest) processUpload(ctx context.Context, a *Allocation) {
 	if req.completedCallback != nil {
 		defer req.completedCallback(req.filepath)
 	}
 
-	var inFile *os.File
-	inFile, err := os.Open(req.filepath)
-	if err != nil && req.statusCallback != nil {
-		req.statusCallback.Error(a.ID, req.filepath, OpUpload, errors.New("open_file_failed", err.Error()))
-		return
+	content, err := req.openContent()
+	if req.contentPath != "" {
+		defer os.Remove(req.contentPath)
 	}
-	defer inFile.Close()
-	mimetype, err := zboxutil.GetFileContentType(inFile)
-	if err != nil && req.statusCallback != nil {
-		req.statusCallback.Error(a.ID, req.filepath, OpUpload, errors.New("mime_type_error", err.Error()))
+	if err != nil {
+		req.uploadError(a, req.filepath, err)
 		return
 	}
-	req.filemeta.MimeType = mimetype
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+	if req.isUpdate && !req.isRepair {
+		// the custom meta of the file is kept, the compression and the
+		// deduplication of the new content replace the ones it records
+		if ref, err := a.getFileRef(req.remotefilepath); err == nil {
+			req.filemeta.CustomMeta = ref.CustomMeta
+		}
+	}
 	err = req.setupUpload(a)
-	if err != nil && req.statusCallback != nil {
-		req.statusCallback.Error(a.ID, req.filepath, OpUpload, errors.New("setup_upload_failed", err.Error()))
+	if err != nil {
+		req.uploadError(a, req.filepath, errors.New("setup_upload_failed", err.Error()))
 		return
 	}
 	size := req.filemeta.Size
@@ -450,20 +595,18 @@ func (req *UploadRequest) processUpload(ctx context.Context, a *Allocation) {
 	perShard := (size + int64(a.DataShards) - 1) / int64(a.DataShards)
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
-	if len(req.thumbnailpath) > 0 {
+	if req.hasThumbnail() {
 		wg.Add(1)
 		go req.processThumbnail(a, wg)
 	}
+	// the content isn't committed if it wasn't pushed whole
+	var pushFailed bool
 	go func() {
 		defer wg.Done()
 		// Pad data to Shards*perShard.
 		padding := make([]byte, (int64(a.DataShards)*perShard)-size)
-		dataReader := io.MultiReader(inFile, bytes.NewBuffer(padding))
-		chunkSizeWithHeader := int64(fileref.CHUNK_SIZE)
-		if req.isEncrypted {
-			chunkSizeWithHeader -= 16
-			chunkSizeWithHeader -= 2 * 1024
-		}
+		dataReader := io.MultiReader(content, bytes.NewBuffer(padding))
+		chunkSizeWithHeader := int64(fileref.CHUNK_SIZE) - req.chunkOverhead()
 		chunksPerShard := (perShard + chunkSizeWithHeader - 1) / chunkSizeWithHeader
 		Logger.Info("Size:", size, " perShard:", perShard, " chunks/shard:", chunksPerShard)
 		req.isUploadCanceled = false
@@ -474,31 +617,33 @@ func (req *UploadRequest) processUpload(ctx context.Context, a *Allocation) {
 		for ctr := int64(0); ctr < chunksPerShard; ctr++ {
 			remaining := int64(math.Min(float64(perShard-(ctr*chunkSizeWithHeader)), float64(chunkSizeWithHeader)))
 			b1 := make([]byte, remaining*int64(a.DataShards))
-			_, err = dataReader.Read(b1)
-			if err != nil && req.statusCallback != nil {
-				req.statusCallback.Error(a.ID, req.filepath, OpUpload, errors.New("read_failed", err.Error()))
+			_, err = io.ReadFull(dataReader, b1)
+			if err != nil {
+				pushFailed = true
+				req.uploadError(a, req.filepath, errors.New("read_failed", err.Error()))
 				return
 			}
 			if req.isUploadCanceled {
 				req.isUploadCanceled = false
 				if !req.isUpdate && !req.isRepair {
-					go a.DeleteFile(req.remotefilepath)
+					go a.deleteFile(req.remotefilepath, req.consensusThresh, req.fullconsensus)
 				}
 				if req.statusCallback != nil {
 					req.statusCallback.Error(a.ID, req.filepath, OpUpload, errors.New("user_aborted", "Upload aborted by user"))
 				}
 				return
 			}
-			err = req.pushData(b1)
+			err = req.pushData(b1, ctr, ctr == chunksPerShard-1)
 			if err != nil {
-				req.statusCallback.Error(a.ID, req.filepath, OpUpload, errors.New("push_error", err.Error()))
+				pushFailed = true
+				req.uploadError(a, req.filepath, errors.New("push_error", err.Error()))
 				return
 			}
 
 		}
 		err = req.completePush()
-		if err != nil && req.statusCallback != nil {
-			req.statusCallback.Error(a.ID, req.remotefilepath, OpUpload, err)
+		if err != nil {
+			req.uploadError(a, req.remotefilepath, err)
 			return
 		}
 	}()
@@ -513,6 +658,9 @@ func (req *UploadRequest) processUpload(ctx context.Context, a *Allocation) {
 		close(ch)
 	}
 	Logger.Info("Closed all the channels. Submitting for commit")
+	if pushFailed {
+		return
+	}
 	req.consensus = 0
 	wg = &sync.WaitGroup{}
 	ones := req.uploadMask.CountOnes()
@@ -1,7 +1,12 @@
 package sdk
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestMaxBlobbersRequiredGreaterThanImplicitLimit128(t *testing.T) {
@@ -63,3 +68,24 @@ func TestNumBlobbersRequiredZero(t *testing.T) {
 		t.Errorf("IsFullConsensusSupported() = %v, want %v", false, true)
 	}
 }
+
+func TestProcessUploadCompressError(t *testing.T) {
+	f, err := ioutil.TempFile("", "upload")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("content")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// without a status callback, the upload stops at the failure
+	var completed bool
+	req := &UploadRequest{
+		filepath:          f.Name(),
+		filemeta:          &UploadFileMeta{},
+		compression:       "unknown",
+		completedCallback: func(string) { completed = true },
+	}
+	req.processUpload(context.Background(), &Allocation{})
+	require.True(t, completed)
+	require.Empty(t, req.contentPath)
+}
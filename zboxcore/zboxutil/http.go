@@ -15,7 +15,7 @@ import (
 
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/encryption"
-	"github.com/0chain/gosdk/core/util"
+	"github.com/0chain/gosdk/core/node"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
 	"github.com/0chain/gosdk/zboxcore/client"
 )
@@ -449,7 +449,7 @@ func MakeSCRestAPICall(scAddress string, relativePath string, params map[string]
 	entityResult := make(map[string][]byte)
 	var retObj []byte
 	maxCount := float32(0)
-	for _, sharder := range util.Shuffle(sharders) {
+	for _, sharder := range node.Sharders.Available(sharders) {
 		urlString := fmt.Sprintf("%v/%v%v%v", sharder, SC_REST_API_URL, scAddress, relativePath)
 		urlObj, _ := url.Parse(urlString)
 		q := urlObj.Query()
@@ -459,10 +459,13 @@ func MakeSCRestAPICall(scAddress string, relativePath string, params map[string]
 		urlObj.RawQuery = q.Encode()
 		client := &http.Client{Transport: transport}
 
+		start := time.Now()
 		response, err := client.Get(urlObj.String())
 		if err != nil {
+			node.Sharders.Report(sharder, time.Since(start), err)
 			continue
 		} else {
+			node.Sharders.ReportResponse(sharder, start, response.StatusCode, nil)
 			if response.StatusCode != 200 {
 				continue
 			}
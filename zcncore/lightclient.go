@@ -0,0 +1,236 @@
+package zcncore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0chain/gosdk/core/block"
+	"github.com/0chain/gosdk/core/common/errors"
+)
+
+// defaultInclusionTimeout bounds VerifyTransactionInclusion when the
+// given context has no deadline.
+const defaultInclusionTimeout = defaultTxnExpirationSeconds * time.Second
+
+// LightClient keeps a header chain verified against a trusted magic
+// block. Every header is checked for its hash, the generator signature
+// and the verification tickets of the magic block miners. Magic block
+// rotations are followed from the trusted one, each next magic block is
+// accepted once its hash is recomputed and the block carrying it is
+// verified by the miners of the previous one.
+type LightClient struct {
+	mutex      sync.Mutex
+	magicBlock *block.MagicBlock
+	next       *block.MagicBlock
+	head       *block.Header
+	headers    map[int64]*block.Header
+
+	// getMagicBlockBlock returns the block carrying the magic block of
+	// the number.
+	getMagicBlockBlock func(ctx context.Context, number int64) (*block.Block, error)
+}
+
+// NewLightClient creates a light client trusting the given magic block.
+func NewLightClient(trusted *block.MagicBlock) (*LightClient, error) {
+	if trusted == nil || trusted.Miners == nil || len(trusted.Miners.Nodes) == 0 {
+		return nil, errors.New("light_client", "invalid trusted magic block")
+	}
+	return &LightClient{
+		magicBlock: trusted,
+		headers:    make(map[int64]*block.Header),
+		getMagicBlockBlock: func(ctx context.Context, number int64) (*block.Block, error) {
+			return GetMagicBlockBlockByNumber(ctx, getMinShardersVerify(), number)
+		},
+	}, nil
+}
+
+// NewLightClientFromNetwork creates a light client trusting the latest
+// finalized magic block reported by the sharders.
+func NewLightClientFromNetwork(ctx context.Context) (*LightClient, error) {
+	if err := checkSdkInit(); err != nil {
+		return nil, err
+	}
+	mb, err := GetLatestFinalizedMagicBlock(ctx, getMinShardersVerify())
+	if err != nil {
+		return nil, errors.Wrap(err, "getting trusted magic block")
+	}
+	return NewLightClient(mb)
+}
+
+// MagicBlock returns the current verified magic block.
+func (lc *LightClient) MagicBlock() *block.MagicBlock {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	return lc.magicBlock
+}
+
+// Head returns the highest verified header, or nil.
+func (lc *LightClient) Head() *block.Header {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	return lc.head
+}
+
+// Header returns the verified header of the round, if any.
+func (lc *LightClient) Header(round int64) (*block.Header, bool) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	h, ok := lc.headers[round]
+	return h, ok
+}
+
+// magicBlockFor returns the magic block governing the round, following
+// rotations from the current one. The next magic block, once known, is
+// cached so rounds before its start do not query the sharders.
+func (lc *LightClient) magicBlockFor(ctx context.Context, round int64) (*block.MagicBlock, error) {
+	lc.mutex.Lock()
+	mb, next := lc.magicBlock, lc.next
+	lc.mutex.Unlock()
+
+	if round < mb.StartingRound {
+		return nil, errors.New("light_client",
+			fmt.Sprintf("round %d is older than trusted magic block %d", round, mb.MagicBlockNumber))
+	}
+	for {
+		if next == nil {
+			b, err := lc.getMagicBlockBlock(ctx, mb.MagicBlockNumber+1)
+			if err != nil || b == nil {
+				// no newer magic block is known yet
+				return mb, nil
+			}
+			if next, err = mb.VerifyNextBlock(b, _config.chain.SignatureScheme); err != nil {
+				return nil, err
+			}
+		}
+		if next.StartingRound > round {
+			lc.mutex.Lock()
+			if lc.magicBlock.MagicBlockNumber == mb.MagicBlockNumber {
+				lc.next = next
+			}
+			lc.mutex.Unlock()
+			return mb, nil
+		}
+		lc.mutex.Lock()
+		if lc.magicBlock.MagicBlockNumber < next.MagicBlockNumber {
+			lc.magicBlock, lc.next = next, nil
+		}
+		lc.mutex.Unlock()
+		mb, next = next, nil
+	}
+}
+
+// VerifyRound fetches the block of the round and verifies it against
+// the governing magic block.
+func (lc *LightClient) VerifyRound(ctx context.Context, round int64) (*block.Header, error) {
+	if h, ok := lc.Header(round); ok {
+		return h, nil
+	}
+	b, err := GetBlockByRound(ctx, getMinShardersVerify(), round)
+	if err != nil {
+		return nil, err
+	}
+	return lc.VerifyBlock(ctx, b)
+}
+
+// VerifyBlock verifies the block against the magic block governing its
+// round and adds its header to the verified chain.
+func (lc *LightClient) VerifyBlock(ctx context.Context, b *block.Block) (*block.Header, error) {
+	mb, err := lc.magicBlockFor(ctx, b.Round)
+	if err != nil {
+		return nil, err
+	}
+	if err = mb.VerifyBlock(b, _config.chain.SignatureScheme); err != nil {
+		return nil, err
+	}
+	lc.addHeader(b.Header)
+	return b.Header, nil
+}
+
+func (lc *LightClient) addHeader(h *block.Header) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	lc.headers[h.Round] = h
+	if lc.head == nil || h.Round > lc.head.Round {
+		lc.head = h
+	}
+}
+
+// VerifyTransactionInclusion verifies that the transaction is included in
+// a verified block and that the block is extended by
+// min_confirmation chain length verified blocks. The verification is
+// bounded by the context deadline, or by the transaction expiration when
+// the context has none.
+func (lc *LightClient) VerifyTransactionInclusion(ctx context.Context, txnHash string) (*block.Header, error) {
+	if err := checkSdkInit(); err != nil {
+		return nil, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultInclusionTimeout)
+		defer cancel()
+	}
+
+	var (
+		cfmBlock *blockHeader
+		err      error
+	)
+	for {
+		cfmBlock, _, _, err = getTransactionConfirmation(getMinShardersVerify(), txnHash)
+		if err == nil {
+			break
+		}
+		if err = lc.wait(ctx); err != nil {
+			return nil, errors.Wrap(err, "transaction confirmation not found")
+		}
+	}
+
+	header, err := lc.verifyRoundWait(ctx, cfmBlock.Round)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying confirmation block")
+	}
+	if header.Hash != cfmBlock.Hash {
+		return nil, errors.New("light_client", "confirmation block is not in the verified chain")
+	}
+
+	var prev = header
+	for round := header.Round + 1; round <= header.Round+getMinRequiredChainLength(); round++ {
+		next, err := lc.verifyRoundWait(ctx, round)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("verifying round %d", round))
+		}
+		if !next.IsBlockExtends(prev.Hash) {
+			return nil, errors.New("light_client",
+				fmt.Sprintf("block of round %d does not extend round %d", round, prev.Round))
+		}
+		prev = next
+	}
+	return header, nil
+}
+
+// verifyRoundWait waits for the block of the round to be available and
+// verifies it. Verification failures are returned immediately.
+func (lc *LightClient) verifyRoundWait(ctx context.Context, round int64) (*block.Header, error) {
+	if h, ok := lc.Header(round); ok {
+		return h, nil
+	}
+	for {
+		b, err := GetBlockByRound(ctx, getMinShardersVerify(), round)
+		if err == nil {
+			return lc.VerifyBlock(ctx, b)
+		}
+		if err = lc.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (lc *LightClient) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(defaultWaitSeconds):
+		return nil
+	}
+}
@@ -0,0 +1,203 @@
+package zcncore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/0chain/gosdk/core/block"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/herumi/bls-go-binary/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMpk(t int) []string {
+	var sk bls.SecretKey
+	sk.SetByCSPRNG()
+	var mpk []string
+	for _, pk := range bls.GetMasterPublicKey(sk.GetMasterSecretKey(t)) {
+		mpk = append(mpk, pk.GetHexString())
+	}
+	return mpk
+}
+
+type testChain struct {
+	ids     []string
+	signers map[string]zcncrypto.SignatureScheme
+	mbs     []*block.MagicBlock
+}
+
+func newTestChain(t *testing.T, miners int) *testChain {
+	var (
+		c  = &testChain{signers: make(map[string]zcncrypto.SignatureScheme)}
+		mb = &block.MagicBlock{
+			MagicBlockNumber: 1,
+			StartingRound:    10,
+			Miners:           &block.NodePool{Nodes: make(map[string]block.Node)},
+			Mpks:             &block.Mpks{Mpks: make(map[string]*block.MPK)},
+			T:                2,
+		}
+	)
+	for i := 0; i < miners; i++ {
+		ss := zcncrypto.NewSignatureScheme("bls0chain")
+		w, err := ss.GenerateKeys()
+		require.NoError(t, err)
+		id := w.ClientID
+		mb.Miners.Nodes[id] = block.Node{ID: id, PublicKey: w.Keys[0].PublicKey}
+		mb.Mpks.Mpks[id] = &block.MPK{ID: id, Mpk: newTestMpk(mb.T)}
+		c.ids = append(c.ids, id)
+		c.signers[id] = ss
+	}
+	mb.Hash = mb.ComputeHash()
+	c.mbs = append(c.mbs, mb)
+	return c
+}
+
+// rotate adds a magic block with the same miners starting at the round.
+func (c *testChain) rotate(startingRound int64) *block.MagicBlock {
+	var (
+		prev = c.mbs[len(c.mbs)-1]
+		mb   = *prev
+	)
+	mb.PreviousMagicBlockHash = prev.Hash
+	mb.MagicBlockNumber++
+	mb.StartingRound = startingRound
+	mb.Hash = mb.ComputeHash()
+	c.mbs = append(c.mbs, &mb)
+	return &mb
+}
+
+func (c *testChain) block(t *testing.T, round int64, mb *block.MagicBlock, verifiers ...int) *block.Block {
+	var h = &block.Header{
+		MinerID:        c.ids[0],
+		Round:          round,
+		CreationDate:   1000,
+		MerkleTreeRoot: encryption.Hash("txns"),
+	}
+	var prevHash = encryption.Hash("prev")
+	h.Hash = encryption.Hash(fmt.Sprintf("%s:%s:%d:%d:%d:%s:%s", h.MinerID,
+		prevHash, h.CreationDate, h.Round, h.RoundRandomSeed,
+		h.MerkleTreeRoot, h.ReceiptMerkleTreeRoot))
+
+	sig, err := c.signers[h.MinerID].Sign(h.Hash)
+	require.NoError(t, err)
+	var b = &block.Block{
+		Header:     h,
+		MinerID:    common.Key(h.MinerID),
+		Round:      round,
+		Hash:       common.Key(h.Hash),
+		Signature:  sig,
+		PrevHash:   prevHash,
+		MagicBlock: mb,
+	}
+	for _, i := range verifiers {
+		sig, err := c.signers[c.ids[i]].Sign(h.Hash)
+		require.NoError(t, err)
+		b.VerificationTickets = append(b.VerificationTickets,
+			&block.VerificationTicket{VerifierID: c.ids[i], Signature: sig})
+	}
+	return b
+}
+
+func newTestLightClient(t *testing.T, c *testChain, carriers map[int64]*block.Block) *LightClient {
+	_config.chain.SignatureScheme = "bls0chain"
+	lc, err := NewLightClient(c.mbs[0])
+	require.NoError(t, err)
+	lc.getMagicBlockBlock = func(ctx context.Context, number int64) (*block.Block, error) {
+		if b, ok := carriers[number]; ok {
+			return b, nil
+		}
+		return nil, errors.New("magic block info not found")
+	}
+	return lc
+}
+
+func TestLightClientMagicBlockFor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no rotation", func(t *testing.T) {
+		c := newTestChain(t, 3)
+		lc := newTestLightClient(t, c, nil)
+		mb, err := lc.magicBlockFor(ctx, 50)
+		require.NoError(t, err)
+		require.Equal(t, c.mbs[0], mb)
+
+		_, err = lc.magicBlockFor(ctx, 5)
+		require.Error(t, err)
+	})
+
+	t.Run("endorsed rotation", func(t *testing.T) {
+		c := newTestChain(t, 3)
+		next := c.rotate(100)
+		lc := newTestLightClient(t, c, map[int64]*block.Block{
+			2: c.block(t, 50, next, 1, 2),
+		})
+
+		mb, err := lc.magicBlockFor(ctx, 60)
+		require.NoError(t, err)
+		require.Equal(t, c.mbs[0], mb)
+
+		mb, err = lc.magicBlockFor(ctx, 120)
+		require.NoError(t, err)
+		require.Equal(t, next.Hash, mb.Hash)
+		require.Equal(t, next.Hash, lc.MagicBlock().Hash)
+	})
+
+	t.Run("forged hash", func(t *testing.T) {
+		c := newTestChain(t, 3)
+		next := c.rotate(100)
+		next.Hash = encryption.Hash("forged")
+		lc := newTestLightClient(t, c, map[int64]*block.Block{
+			2: c.block(t, 50, next, 1, 2),
+		})
+		_, err := lc.magicBlockFor(ctx, 120)
+		require.Error(t, err)
+		require.Equal(t, c.mbs[0], lc.MagicBlock())
+	})
+
+	t.Run("not endorsed", func(t *testing.T) {
+		c := newTestChain(t, 3)
+		next := c.rotate(100)
+		lc := newTestLightClient(t, c, map[int64]*block.Block{
+			2: c.block(t, 50, next, 1),
+		})
+		_, err := lc.magicBlockFor(ctx, 120)
+		require.Error(t, err)
+		require.Equal(t, c.mbs[0], lc.MagicBlock())
+	})
+
+	t.Run("endorsed by other miners", func(t *testing.T) {
+		c := newTestChain(t, 3)
+		next := c.rotate(100)
+		other := newTestChain(t, 3)
+		lc := newTestLightClient(t, c, map[int64]*block.Block{
+			2: other.block(t, 50, next, 1, 2),
+		})
+		_, err := lc.magicBlockFor(ctx, 120)
+		require.Error(t, err)
+	})
+}
+
+func TestLightClientVerifyBlock(t *testing.T) {
+	ctx := context.Background()
+	c := newTestChain(t, 3)
+	next := c.rotate(100)
+	lc := newTestLightClient(t, c, map[int64]*block.Block{
+		2: c.block(t, 50, next, 1, 2),
+	})
+
+	h, err := lc.VerifyBlock(ctx, c.block(t, 120, nil, 1, 2))
+	require.NoError(t, err)
+	require.Equal(t, h, lc.Head())
+	got, ok := lc.Header(120)
+	require.True(t, ok)
+	require.Equal(t, h, got)
+
+	_, err = lc.VerifyBlock(ctx, c.block(t, 121, nil, 1))
+	require.Error(t, err)
+	_, ok = lc.Header(121)
+	require.False(t, ok)
+}
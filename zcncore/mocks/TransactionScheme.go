@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	transaction "github.com/0chain/gosdk/core/transaction"
 	zcncore "github.com/0chain/gosdk/zcncore"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +13,20 @@ type TransactionScheme struct {
 	mock.Mock
 }
 
+// CallSmartContract provides a mock function with given fields: sc, methodName, input, val, fee
+func (_m *TransactionScheme) CallSmartContract(sc *transaction.SmartContract, methodName string, input interface{}, val int64, fee int64) error {
+	ret := _m.Called(sc, methodName, input, val, fee)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*transaction.SmartContract, string, interface{}, int64, int64) error); ok {
+		r0 = rf(sc, methodName, input, val, fee)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CancelAllocation provides a mock function with given fields: allocID, fee
 func (_m *TransactionScheme) CancelAllocation(allocID string, fee int64) error {
 	ret := _m.Called(allocID, fee)
@@ -152,6 +167,20 @@ func (_m *TransactionScheme) GetVerifyOutput() string {
 	return r0
 }
 
+// GetVerifyResult provides a mock function with given fields: out
+func (_m *TransactionScheme) GetVerifyResult(out interface{}) error {
+	ret := _m.Called(out)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
+		r0 = rf(out)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // LockTokens provides a mock function with given fields: val, durationHr, durationMin
 func (_m *TransactionScheme) LockTokens(val int64, durationHr int64, durationMin int) error {
 	ret := _m.Called(val, durationHr, durationMin)
@@ -0,0 +1,355 @@
+package zcncore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/transaction"
+)
+
+// multisig proposal statuses
+const (
+	MSProposalPending  = "pending"
+	MSProposalExecuted = "executed"
+	MSProposalExpired  = "expired"
+)
+
+// MSProposal -- this should mimic the proposal definition in MultiSig SC
+type MSProposal struct {
+	ProposalID       string           `json:"proposal_id"`
+	ExpirationDate   common.Timestamp `json:"expiration_date"`
+	Transfer         MSTransfer       `json:"transfer"`
+	SignerSignatures []string         `json:"signer_signatures"`
+	ClientSignature  string           `json:"client_signature"`
+}
+
+// Status returns the status of the proposal. The client signature is set
+// by the SC when the transfer is executed.
+func (p *MSProposal) Status() string {
+	switch {
+	case p.ClientSignature != "":
+		return MSProposalExecuted
+	case p.ExpirationDate != 0 && common.Now() > p.ExpirationDate:
+		return MSProposalExpired
+	}
+	return MSProposalPending
+}
+
+// MSVoteResult is the result of a vote transaction.
+type MSVoteResult struct {
+	Hash   string
+	Output string
+	// Proposal is the state of the proposal after the vote, nil if the
+	// proposal can't be queried.
+	Proposal *MSProposal
+}
+
+// Executed reports whether the vote executed the transfer.
+func (r *MSVoteResult) Executed() bool {
+	return r.Proposal != nil && r.Proposal.Status() == MSProposalExecuted
+}
+
+// MSProposalVotes are the votes collected for a proposal, by signer client
+// ID.
+type MSProposalVotes struct {
+	ProposalID     string             `json:"proposal_id"`
+	Transfer       MSTransfer         `json:"transfer"`
+	ExpirationDate common.Timestamp   `json:"expiration_date"`
+	Votes          map[string]*MSVote `json:"votes"`
+	Expired        bool               `json:"expired"`
+}
+
+// MSClient creates proposals of a multisig wallet, collects the votes of
+// the signers and submits them to the MultiSig SC. The proposals are kept
+// in memory, MarshalProposals and LoadProposals persist them.
+type MSClient struct {
+	GroupClientID string
+	Threshold     int
+	// Expiration is the lifetime of the proposals created.
+	Expiration time.Duration
+
+	mutex     sync.Mutex
+	signers   map[string]string
+	proposals map[string]*MSProposalVotes
+}
+
+// NewMSClient creates a client of the multisig wallet with the group
+// client ID, requiring threshold votes. The signers are added with
+// AddSigner.
+func NewMSClient(groupClientID string, threshold int, expiration time.Duration) *MSClient {
+	return &MSClient{
+		GroupClientID: groupClientID,
+		Threshold:     threshold,
+		Expiration:    expiration,
+		signers:       make(map[string]string),
+		proposals:     make(map[string]*MSProposalVotes),
+	}
+}
+
+// AddSigner adds the signer of the multisig wallet with its public key,
+// only the votes of the signers are collected.
+func (c *MSClient) AddSigner(signerClientID, publicKey string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.signers[signerClientID] = publicKey
+}
+
+// MarshalProposals returns the proposals with their collected votes.
+func (c *MSClient) MarshalProposals() ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return json.Marshal(c.proposals)
+}
+
+// LoadProposals adds the proposals returned by MarshalProposals, replacing
+// the proposals with the same IDs.
+func (c *MSClient) LoadProposals(data []byte) error {
+	var proposals map[string]*MSProposalVotes
+	if err := json.Unmarshal(data, &proposals); err != nil {
+		return errors.Wrap(err, "invalid proposals")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for id, p := range proposals {
+		if p == nil || p.ProposalID != id {
+			return errors.New("ms_proposal", fmt.Sprintf("invalid proposal %s", id))
+		}
+		if p.Votes == nil {
+			p.Votes = make(map[string]*MSVote)
+		}
+	}
+	for id, p := range proposals {
+		c.proposals[id] = p
+	}
+	return nil
+}
+
+// CreateProposal creates the proposal to transfer token to the client.
+// The proposal is sent to the signers, which vote with CreateMSVote.
+func (c *MSClient) CreateProposal(proposalID, toClientID string, token int64) (*MSProposalVotes, error) {
+	if proposalID == "" || toClientID == "" {
+		return nil, errors.New("proposal or toClientID cannot be empty")
+	}
+	if token < 1 {
+		return nil, errors.New("Token cannot be less than 1")
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.proposals[proposalID]; ok {
+		return nil, errors.New("ms_proposal", fmt.Sprintf("proposal %s already exists", proposalID))
+	}
+	p := &MSProposalVotes{
+		ProposalID: proposalID,
+		Transfer: MSTransfer{
+			ClientID:   c.GroupClientID,
+			ToClientID: toClientID,
+			Amount:     token,
+		},
+		Votes: make(map[string]*MSVote),
+	}
+	if c.Expiration > 0 {
+		p.ExpirationDate = common.Now() + common.Timestamp(c.Expiration/time.Second)
+	}
+	c.proposals[proposalID] = p
+	return p, nil
+}
+
+func (c *MSClient) proposal(proposalID string) (*MSProposalVotes, error) {
+	p, ok := c.proposals[proposalID]
+	if !ok {
+		return nil, errors.New("ms_proposal", fmt.Sprintf("unknown proposal %s", proposalID))
+	}
+	if p.Expired || (p.ExpirationDate != 0 && common.Now() > p.ExpirationDate) {
+		return nil, errors.New("ms_proposal", fmt.Sprintf("proposal %s expired", proposalID))
+	}
+	return p, nil
+}
+
+// Vote signs the proposal with the signer wallet and collects the vote.
+func (c *MSClient) Vote(proposalID, signerWalletstr string) (*MSVote, error) {
+	c.mutex.Lock()
+	p, err := c.proposal(proposalID)
+	c.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	signerWallet, err := GetWallet(signerWalletstr)
+	if err != nil {
+		return nil, err
+	}
+	vstr, err := CreateMSVote(proposalID, c.GroupClientID, signerWalletstr,
+		p.Transfer.ToClientID, p.Transfer.Amount)
+	if err != nil {
+		return nil, err
+	}
+	var vote MSVote
+	if err = json.Unmarshal([]byte(vstr), &vote); err != nil {
+		return nil, err
+	}
+	if err = c.AddVote(signerWallet.ClientID, &vote); err != nil {
+		return nil, err
+	}
+	return &vote, nil
+}
+
+// AddVote collects the vote of the signer, checking it is for the
+// transfer of the proposal and signed by the signer.
+func (c *MSClient) AddVote(signerClientID string, vote *MSVote) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p, err := c.proposal(vote.ProposalID)
+	if err != nil {
+		return err
+	}
+	if vote.Transfer != p.Transfer {
+		return errors.New("ms_vote", "vote transfer doesn't match the proposal")
+	}
+	publicKey, ok := c.signers[signerClientID]
+	if !ok {
+		return errors.New("ms_vote", fmt.Sprintf("%s is not a signer", signerClientID))
+	}
+	if vote.Signature == "" {
+		return errors.New("ms_vote", "vote is not signed")
+	}
+	buff, err := json.Marshal(vote.Transfer)
+	if err != nil {
+		return err
+	}
+	if _, err = verifyFn(vote.Signature, encryption.Hash(buff), publicKey); err != nil {
+		return errors.New("ms_vote", "invalid vote signature")
+	}
+	p.Votes[signerClientID] = vote
+	return nil
+}
+
+// Votes returns the votes collected for the proposal.
+func (c *MSClient) Votes(proposalID string) (map[string]*MSVote, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p, err := c.proposal(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	votes := make(map[string]*MSVote, len(p.Votes))
+	for id, v := range p.Votes {
+		votes[id] = v
+	}
+	return votes, nil
+}
+
+// Ready reports whether the threshold of votes is collected.
+func (c *MSClient) Ready(proposalID string) bool {
+	votes, err := c.Votes(proposalID)
+	return err == nil && len(votes) >= c.Threshold
+}
+
+// Expire expires the proposal, no more votes are collected for it.
+func (c *MSClient) Expire(proposalID string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p, ok := c.proposals[proposalID]
+	if !ok {
+		return errors.New("ms_proposal", fmt.Sprintf("unknown proposal %s", proposalID))
+	}
+	p.Expired = true
+	return nil
+}
+
+// SubmitVote submits the vote with the signer wallet, the transaction must
+// be sent by the signer, and waits for its confirmation. The state of the
+// proposal after the vote is queried from the SC.
+func (c *MSClient) SubmitVote(ctx context.Context, signerWalletstr string, vote *MSVote) (*MSVoteResult, error) {
+	vbytes, err := json.Marshal(vote)
+	if err != nil {
+		return nil, err
+	}
+	waiter := newTxnWaiter()
+	txn, err := NewMSTransaction(signerWalletstr, waiter)
+	if err != nil {
+		return nil, err
+	}
+	if err = txn.RegisterVote(signerWalletstr, string(vbytes)); err != nil {
+		return nil, err
+	}
+	hash, output, err := waiter.wait(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	result := &MSVoteResult{Hash: hash, Output: output}
+	if result.Proposal, err = c.QueryProposal(ctx, vote.ProposalID); err != nil {
+		Logger.Error("multisig proposal query failed: ", err)
+	}
+	return result, nil
+}
+
+// QueryProposal returns the state of the proposal in the SC.
+func (c *MSClient) QueryProposal(ctx context.Context, proposalID string, opts ...QueryOption) (p *MSProposal, err error) {
+	p = new(MSProposal)
+	err = QuerySmartContract(ctx, transaction.MultiSigSmartContract, "getProposal", Params{
+		"client_id":   c.GroupClientID,
+		"proposal_id": proposalID,
+	}, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryProposals returns the proposals of the multisig wallet in the SC.
+func (c *MSClient) QueryProposals(ctx context.Context, opts ...QueryOption) (ps []*MSProposal, err error) {
+	err = QuerySmartContract(ctx, transaction.MultiSigSmartContract, "getProposals", Params{
+		"client_id": c.GroupClientID,
+	}, &ps, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// txnWaiter waits for a transaction to be submitted and verified.
+type txnWaiter struct {
+	done chan int
+}
+
+func newTxnWaiter() *txnWaiter {
+	return &txnWaiter{done: make(chan int, 1)}
+}
+
+func (w *txnWaiter) OnTransactionComplete(t *Transaction, status int) { w.done <- status }
+func (w *txnWaiter) OnVerifyComplete(t *Transaction, status int)      { w.done <- status }
+func (w *txnWaiter) OnAuthComplete(t *Transaction, status int)        {}
+
+func (w *txnWaiter) wait(ctx context.Context, t TransactionScheme) (hash, output string, err error) {
+	select {
+	case status := <-w.done:
+		if status != StatusSuccess {
+			return "", "", errors.New("transaction_failed", t.GetTransactionError())
+		}
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+	hash = t.GetTransactionHash()
+	if err = t.Verify(); err != nil {
+		return hash, "", err
+	}
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return hash, "", ctx.Err()
+	}
+	err = t.GetVerifyResult(&output)
+	return hash, output, err
+}
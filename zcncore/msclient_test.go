@@ -0,0 +1,126 @@
+package zcncore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigner(t *testing.T, c *MSClient) (*zcncrypto.Wallet, string) {
+	w, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	wStr, err := w.Marshal()
+	require.NoError(t, err)
+	if c != nil {
+		c.AddSigner(w.ClientID, w.ClientKey)
+	}
+	return w, wStr
+}
+
+func TestMSClientVotes(t *testing.T) {
+	_config.chain.SignatureScheme = "bls0chain"
+	c := NewMSClient("group", 2, time.Hour)
+	_, err := c.CreateProposal("p1", "to", 10)
+	require.NoError(t, err)
+	_, err = c.CreateProposal("p1", "to", 10)
+	require.Error(t, err)
+
+	s1, s1Str := newTestSigner(t, c)
+	_, s2Str := newTestSigner(t, c)
+	other, otherStr := newTestSigner(t, nil)
+
+	_, err = c.Vote("p1", s1Str)
+	require.NoError(t, err)
+	require.False(t, c.Ready("p1"))
+
+	t.Run("not a signer", func(t *testing.T) {
+		_, err := c.Vote("p1", otherStr)
+		require.Error(t, err)
+	})
+
+	t.Run("forged signature", func(t *testing.T) {
+		vote, err := CreateMSVote("p1", "group", otherStr, "to", 10)
+		require.NoError(t, err)
+		var v MSVote
+		require.NoError(t, json.Unmarshal([]byte(vote), &v))
+		require.Error(t, c.AddVote(s1.ClientID, &v))
+		c.AddSigner(other.ClientID, other.ClientKey)
+		require.NoError(t, c.AddVote(other.ClientID, &v))
+		delete(c.proposals["p1"].Votes, other.ClientID)
+	})
+
+	t.Run("other transfer", func(t *testing.T) {
+		vote, err := CreateMSVote("p1", "group", s2Str, "to", 20)
+		require.NoError(t, err)
+		var v MSVote
+		require.NoError(t, json.Unmarshal([]byte(vote), &v))
+		require.Error(t, c.AddVote(s1.ClientID, &v))
+	})
+
+	_, err = c.Vote("p1", s2Str)
+	require.NoError(t, err)
+	require.True(t, c.Ready("p1"))
+	votes, err := c.Votes("p1")
+	require.NoError(t, err)
+	require.Len(t, votes, 2)
+
+	require.NoError(t, c.Expire("p1"))
+	require.False(t, c.Ready("p1"))
+	_, err = c.Vote("p1", s1Str)
+	require.Error(t, err)
+}
+
+func TestMSClientLoadProposals(t *testing.T) {
+	_config.chain.SignatureScheme = "bls0chain"
+	c := NewMSClient("group", 1, time.Hour)
+	_, err := c.CreateProposal("p1", "to", 10)
+	require.NoError(t, err)
+	_, err = c.CreateProposal("p2", "to", 10)
+	require.NoError(t, err)
+	_, sStr := newTestSigner(t, c)
+	_, err = c.Vote("p1", sStr)
+	require.NoError(t, err)
+	require.NoError(t, c.Expire("p2"))
+
+	data, err := c.MarshalProposals()
+	require.NoError(t, err)
+
+	restored := NewMSClient("group", 1, time.Hour)
+	require.NoError(t, restored.LoadProposals(data))
+	require.True(t, restored.Ready("p1"))
+	_, err = restored.Votes("p2")
+	require.Error(t, err)
+
+	require.Error(t, restored.LoadProposals([]byte(`{"p3":{"proposal_id":"other"}}`)))
+}
+
+func TestMSWalletMarshal(t *testing.T) {
+	_config.chain.SignatureScheme = "bls0chain"
+	mswStr, groupClientID, wallets, err := CreateMSWallet(2, 3)
+	require.NoError(t, err)
+	require.Len(t, wallets, 4)
+
+	var msw MSWallet
+	require.NoError(t, json.Unmarshal([]byte(mswStr), &msw))
+	require.Equal(t, groupClientID, msw.GroupClientID)
+	require.NotEmpty(t, msw.GroupKey.PrivateKey)
+	require.Len(t, msw.SignerKeys, 3)
+	for i, key := range msw.SignerKeys {
+		w, err := GetWallet(wallets[i+1])
+		require.NoError(t, err)
+		require.Equal(t, w.Keys[0].PrivateKey, key.PrivateKey)
+		require.NotEmpty(t, key.Ids)
+	}
+
+	// json.Marshal keeps the keys
+	b, err := json.Marshal(&msw)
+	require.NoError(t, err)
+	require.Contains(t, string(b), msw.GroupKey.PrivateKey)
+
+	payload, err := GetMultisigPayload(mswStr)
+	require.NoError(t, err)
+	require.Equal(t, groupClientID, payload.(MultisigSCWallet).ClientID)
+}
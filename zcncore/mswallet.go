@@ -166,6 +166,7 @@ func CreateMSVote(proposal, grpClientID, signerWalletstr, toClientID string, tok
 
 	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
 	sigScheme.SetPrivateKey(signerWallet.Keys[0].PrivateKey)
+	defer zcncrypto.WipeKeys(sigScheme)
 	sig, err := sigScheme.Sign(hash)
 	if err != nil {
 		return "", err
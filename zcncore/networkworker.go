@@ -3,10 +3,14 @@ package zcncore
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"reflect"
+	"sync"
 	"time"
 
+	"github.com/0chain/gosdk/core/block"
 	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/node"
 	"github.com/0chain/gosdk/core/util"
 	"go.uber.org/zap"
 )
@@ -15,6 +19,16 @@ const NETWORK_ENDPOINT = "/network"
 
 var networkWorkerTimerInHours = 1
 
+// minerRoundsInterval is how often the rounds of the miners are refreshed
+// for their round lag.
+const minerRoundsInterval = 30 * time.Second
+
+var minerRounds struct {
+	sync.Mutex
+	updated  time.Time
+	updating bool
+}
+
 type Network struct {
 	Miners   []string `json:"miners"`
 	Sharders []string `json:"sharders"`
@@ -91,3 +105,63 @@ func GetNetworkDetails() (*Network, error) {
 	return &networkResponse, nil
 
 }
+
+// GetNodeHealth returns the latency, error rate, round lag and quarantine
+// stats of the miners and sharders used by the SDK.
+func GetNodeHealth() *node.PoolStats {
+	return node.GetPoolStats()
+}
+
+// UpdateMinerRounds queries the current round of every miner and records
+// it in the miners health pool, so that miners lagging behind the others
+// are ranked after them.
+func UpdateMinerRounds(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, miner := range _config.chain.Miners {
+		wg.Add(1)
+		go func(minerurl string) {
+			defer wg.Done()
+			req, err := util.NewHTTPGetRequestContext(ctx, minerurl+GET_CHAIN_STATS)
+			if err != nil {
+				Logger.Error(minerurl, " new get request failed. ", err.Error())
+				return
+			}
+			start := time.Now()
+			res, err := req.Get()
+			node.Miners.ReportResponse(minerurl, start, res.StatusCode, err)
+			if err != nil || res.StatusCode != http.StatusOK {
+				return
+			}
+			var stats block.ChainStats
+			if err = json.Unmarshal([]byte(res.Body), &stats); err != nil {
+				Logger.Error(minerurl, " chain stats parse error. ", err.Error())
+				return
+			}
+			node.Miners.ReportRound(minerurl, int64(stats.CurrentRound))
+		}(miner)
+	}
+	wg.Wait()
+
+	minerRounds.Lock()
+	minerRounds.updated = time.Now()
+	minerRounds.Unlock()
+}
+
+// refreshMinerRounds updates the rounds of the miners in the background
+// when they are older than minerRoundsInterval.
+func refreshMinerRounds() {
+	minerRounds.Lock()
+	defer minerRounds.Unlock()
+	if minerRounds.updating || time.Since(minerRounds.updated) < minerRoundsInterval {
+		return
+	}
+	minerRounds.updating = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), minerRoundsInterval)
+		defer cancel()
+		UpdateMinerRounds(ctx)
+		minerRounds.Lock()
+		minerRounds.updating = false
+		minerRounds.Unlock()
+	}()
+}
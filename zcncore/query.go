@@ -0,0 +1,386 @@
+package zcncore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/util"
+	"github.com/0chain/gosdk/zboxcore/sdk"
+)
+
+// QueryOption configures a synchronous smart contract query.
+type QueryOption func(*queryConfig)
+
+type queryConfig struct {
+	consensus float32 // percent of the queried sharders
+}
+
+// WithQueryConsensus sets the percent of the queried sharders that must
+// return the same response body, and at least two of them. The default
+// is 25.
+func WithQueryConsensus(percent float32) QueryOption {
+	return func(qc *queryConfig) {
+		qc.consensus = percent
+	}
+}
+
+func newQueryConfig(opts []QueryOption) *queryConfig {
+	var qc = &queryConfig{consensus: consensusThresh}
+	for _, opt := range opts {
+		opt(qc)
+	}
+	return qc
+}
+
+type queryResponse struct {
+	StatusCode int
+	Body       string
+	count      int
+}
+
+// canonicalBody returns the body with JSON objects keys sorted, so the
+// same response from different sharders compares equal.
+func canonicalBody(body string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return strings.TrimSpace(body)
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return strings.TrimSpace(body)
+	}
+	return string(canonical)
+}
+
+// bodyConsensus groups the responses by status code and body and returns
+// the largest group. The error is set when the group is not agreed on by
+// the given percent of the queried sharders.
+func bodyConsensus(responses []*util.GetResponse, queried int, percent float32) (*queryResponse, error) {
+	var groups = make(map[string]*queryResponse)
+	for _, rsp := range responses {
+		if rsp == nil || rsp.PostResponse == nil || rsp.StatusCode == 0 {
+			continue // not reachable
+		}
+		var key = fmt.Sprintf("%d:%s", rsp.StatusCode,
+			encryption.FastHash([]byte(canonicalBody(rsp.Body))))
+		group, ok := groups[key]
+		if !ok {
+			group = &queryResponse{StatusCode: rsp.StatusCode, Body: rsp.Body}
+			groups[key] = group
+		}
+		group.count++
+	}
+
+	if len(groups) == 0 {
+		return nil, errors.New("consensus_failed", "no response from sharders")
+	}
+
+	var sorted = make([]*queryResponse, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].count > sorted[j].count })
+
+	var (
+		best = sorted[0]
+		rate = float32(best.count) * 100 / float32(queried)
+	)
+	if !isConsensus(best.count, queried, percent) || (len(sorted) > 1 && sorted[1].count == best.count) {
+		var counts = make([]string, 0, len(sorted))
+		for _, group := range sorted {
+			counts = append(counts, fmt.Sprintf("%d x %d", group.count, group.StatusCode))
+		}
+		return best, errors.New("sharders_disagree",
+			fmt.Sprintf("%d distinct responses from %d of %d sharders (%s), best agreed by %.1f%%, required %.1f%%",
+				len(sorted), len(responses), queried, strings.Join(counts, ", "), rate, percent))
+	}
+	return best, nil
+}
+
+// queryShardersConsensus queries all the sharders and returns the response
+// agreed on by the configured consensus.
+func queryShardersConsensus(ctx context.Context, urlSuffix string, qc *queryConfig) (*queryResponse, error) {
+	var (
+		numSharders = len(_config.chain.Sharders)
+		result      = make(chan *util.GetResponse, numSharders)
+		responses   = make([]*util.GetResponse, 0, numSharders)
+	)
+	// the channel is not closed, late responses are buffered
+	queried := queryFromShardersContext(ctx, numSharders, urlSuffix, result)
+
+	for i := 0; i < numSharders; i++ {
+		select {
+		case rsp := <-result:
+			Logger.Debug(rsp.Url, rsp.Status)
+			responses = append(responses, rsp)
+		case <-ctx.Done():
+			i = numSharders
+		}
+	}
+	return bodyConsensus(responses, queried, qc.consensus)
+}
+
+// QuerySmartContract requests the declared REST endpoint of the smart
+// contract from the sharders and decodes the agreed response into out.
+func QuerySmartContract(ctx context.Context, sc *transaction.SmartContract, name string, params Params, out interface{}, opts ...QueryOption) error {
+	if err := checkSdkInit(); err != nil {
+		return err
+	}
+	path, err := sc.QueryPath(name, params)
+	if err != nil {
+		return err
+	}
+	rsp, err := queryShardersConsensus(ctx,
+		withParams("/v1/screst/"+sc.Address+path, params), newQueryConfig(opts))
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("sharder_error", fmt.Sprintf("status %d: %s", rsp.StatusCode, rsp.Body))
+	}
+	return sc.DecodeQuery(name, []byte(rsp.Body), out)
+}
+
+func clientIDOrSelf(clientID string) string {
+	if clientID == "" {
+		return _config.wallet.ClientID
+	}
+	return clientID
+}
+
+//
+// vesting SC
+//
+
+// QueryVestingPoolInfo returns the vesting pool.
+func QueryVestingPoolInfo(ctx context.Context, poolID string, opts ...QueryOption) (info *VestingPoolInfo, err error) {
+	info = new(VestingPoolInfo)
+	err = QuerySmartContract(ctx, transaction.VestingSmartContract, "getPoolInfo", Params{
+		"pool_id": poolID,
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryVestingClientList returns the vesting pools of the client, or of
+// the current wallet if clientID is empty.
+func QueryVestingClientList(ctx context.Context, clientID string, opts ...QueryOption) (list *VestingClientList, err error) {
+	list = new(VestingClientList)
+	err = QuerySmartContract(ctx, transaction.VestingSmartContract, "getClientPools", Params{
+		"client_id": clientIDOrSelf(clientID),
+	}, list, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryVestingSCConfig returns the vesting SC configurations.
+func QueryVestingSCConfig(ctx context.Context, opts ...QueryOption) (conf *VestingSCConfig, err error) {
+	conf = new(VestingSCConfig)
+	if err = QuerySmartContract(ctx, transaction.VestingSmartContract, "getConfig", nil, conf, opts...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+//
+// miner SC
+//
+
+// QueryMiners returns all the active miners.
+func QueryMiners(ctx context.Context, opts ...QueryOption) (nodes *MinerSCNodes, err error) {
+	nodes = new(MinerSCNodes)
+	if err = QuerySmartContract(ctx, transaction.MinerSmartContract, "getMinerList", nil, nodes, opts...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QuerySharders returns all the active sharders.
+func QuerySharders(ctx context.Context, opts ...QueryOption) (nodes *MinerSCNodes, err error) {
+	nodes = new(MinerSCNodes)
+	if err = QuerySmartContract(ctx, transaction.MinerSmartContract, "getSharderList", nil, nodes, opts...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryMinerSCNodeInfo returns the miner or sharder.
+func QueryMinerSCNodeInfo(ctx context.Context, id string, opts ...QueryOption) (miner *Miner, err error) {
+	miner = new(Miner)
+	err = QuerySmartContract(ctx, transaction.MinerSmartContract, "nodeStat", Params{
+		"id": id,
+	}, miner, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryMinerSCNodePool returns the delegate pool of the miner or sharder.
+func QueryMinerSCNodePool(ctx context.Context, id, poolID string, opts ...QueryOption) (info *MinerSCDelegatePoolInfo, err error) {
+	info = new(MinerSCDelegatePoolInfo)
+	err = QuerySmartContract(ctx, transaction.MinerSmartContract, "nodePoolStat", Params{
+		"id":      id,
+		"pool_id": poolID,
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryMinerSCUserInfo returns the delegate pools of the client, or of
+// the current wallet if clientID is empty.
+func QueryMinerSCUserInfo(ctx context.Context, clientID string, opts ...QueryOption) (info *MinerSCUserPoolsInfo, err error) {
+	info = new(MinerSCUserPoolsInfo)
+	err = QuerySmartContract(ctx, transaction.MinerSmartContract, "getUserPools", Params{
+		"client_id": clientIDOrSelf(clientID),
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryMinerSCConfig returns the miner SC configurations.
+func QueryMinerSCConfig(ctx context.Context, opts ...QueryOption) (conf *MinerSCConfig, err error) {
+	conf = new(MinerSCConfig)
+	if err = QuerySmartContract(ctx, transaction.MinerSmartContract, "configs", nil, conf, opts...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+//
+// storage SC
+//
+
+// QueryStorageSCConfig returns the storage SC configurations.
+func QueryStorageSCConfig(ctx context.Context, opts ...QueryOption) (conf *sdk.StorageSCConfig, err error) {
+	conf = new(sdk.StorageSCConfig)
+	if err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getConfig", nil, conf, opts...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryChallengePoolInfo returns the challenge pool of the allocation.
+func QueryChallengePoolInfo(ctx context.Context, allocID string, opts ...QueryOption) (info *sdk.ChallengePoolInfo, err error) {
+	info = new(sdk.ChallengePoolInfo)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getChallengePoolStat", Params{
+		"allocation_id": allocID,
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryAllocation returns the allocation.
+func QueryAllocation(ctx context.Context, allocID string, opts ...QueryOption) (alloc *sdk.Allocation, err error) {
+	alloc = new(sdk.Allocation)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "allocation", Params{
+		"allocation": allocID,
+	}, alloc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryAllocations returns the allocations of the client, or of the
+// current wallet if clientID is empty.
+func QueryAllocations(ctx context.Context, clientID string, opts ...QueryOption) (allocs []*sdk.Allocation, err error) {
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "allocations", Params{
+		"client": clientIDOrSelf(clientID),
+	}, &allocs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryReadPoolInfo returns the read pools of the client, or of the
+// current wallet if clientID is empty.
+func QueryReadPoolInfo(ctx context.Context, clientID string, opts ...QueryOption) (info *sdk.AllocationPoolStats, err error) {
+	info = new(sdk.AllocationPoolStats)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getReadPoolStat", Params{
+		"client_id": clientIDOrSelf(clientID),
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryWritePoolInfo returns the write pools of the client, or of the
+// current wallet if clientID is empty.
+func QueryWritePoolInfo(ctx context.Context, clientID string, opts ...QueryOption) (info *sdk.AllocationPoolStats, err error) {
+	info = new(sdk.AllocationPoolStats)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getWritePoolStat", Params{
+		"client_id": clientIDOrSelf(clientID),
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryStakePoolInfo returns the stake pool of the blobber.
+func QueryStakePoolInfo(ctx context.Context, blobberID string, opts ...QueryOption) (info *sdk.StakePoolInfo, err error) {
+	info = new(sdk.StakePoolInfo)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getStakePoolStat", Params{
+		"blobber_id": blobberID,
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryStakePoolUserInfo returns the stake pools of the client, or of the
+// current wallet if clientID is empty.
+func QueryStakePoolUserInfo(ctx context.Context, clientID string, opts ...QueryOption) (info *sdk.StakePoolUserInfo, err error) {
+	info = new(sdk.StakePoolUserInfo)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getUserStakePoolStat", Params{
+		"client_id": clientIDOrSelf(clientID),
+	}, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// QueryBlobbers returns all the active blobbers.
+func QueryBlobbers(ctx context.Context, opts ...QueryOption) (blobbers []*sdk.Blobber, err error) {
+	var wrap struct {
+		Nodes []*sdk.Blobber `json:"Nodes"`
+	}
+	if err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getblobbers", nil, &wrap, opts...); err != nil {
+		return nil, err
+	}
+	return wrap.Nodes, nil
+}
+
+// QueryBlobber returns the blobber.
+func QueryBlobber(ctx context.Context, blobberID string, opts ...QueryOption) (blobber *sdk.Blobber, err error) {
+	blobber = new(sdk.Blobber)
+	err = QuerySmartContract(ctx, transaction.StorageSmartContract, "getBlobber", Params{
+		"blobber_id": blobberID,
+	}, blobber, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
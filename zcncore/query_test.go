@@ -0,0 +1,162 @@
+package zcncore
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/util"
+	"github.com/0chain/gosdk/zboxcore/sdk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalBody(t *testing.T) {
+	require.Equal(t, canonicalBody(`{"b": 1, "a": {"d": [1, 2], "c": "x"}}`),
+		canonicalBody(`{"a":{"c":"x","d":[1,2]},"b":1}`))
+	require.NotEqual(t, canonicalBody(`{"a":[1,2]}`), canonicalBody(`{"a":[2,1]}`))
+	require.Equal(t, "not json", canonicalBody(" not json\n"))
+}
+
+func newTestResponse(statusCode int, body string) *util.GetResponse {
+	return &util.GetResponse{PostResponse: &util.PostResponse{
+		StatusCode: statusCode,
+		Body:       body,
+	}}
+}
+
+func TestBodyConsensus(t *testing.T) {
+	t.Run("agreed body", func(t *testing.T) {
+		rsp, err := bodyConsensus([]*util.GetResponse{
+			newTestResponse(200, `{"a":1,"b":2}`),
+			newTestResponse(200, `{"b":2,"a":1}`),
+			newTestResponse(200, `{"a":3,"b":2}`),
+			newTestResponse(500, `error`),
+		}, 4, 50)
+		require.NoError(t, err)
+		require.Equal(t, 200, rsp.StatusCode)
+		require.Equal(t, 2, rsp.count)
+	})
+
+	t.Run("same status different bodies", func(t *testing.T) {
+		rsp, err := bodyConsensus([]*util.GetResponse{
+			newTestResponse(200, `{"a":1}`),
+			newTestResponse(200, `{"a":2}`),
+			newTestResponse(200, `{"a":3}`),
+		}, 3, 50)
+		require.Error(t, err)
+		require.NotNil(t, rsp)
+	})
+
+	t.Run("tie", func(t *testing.T) {
+		_, err := bodyConsensus([]*util.GetResponse{
+			newTestResponse(200, `{"a":1}`),
+			newTestResponse(200, `{"a":1}`),
+			newTestResponse(200, `{"a":2}`),
+			newTestResponse(200, `{"a":2}`),
+		}, 4, 25)
+		require.Error(t, err)
+	})
+
+	t.Run("below consensus of all sharders", func(t *testing.T) {
+		_, err := bodyConsensus([]*util.GetResponse{
+			newTestResponse(200, `{"a":1}`),
+		}, 5, 25)
+		require.Error(t, err)
+	})
+
+	t.Run("unreachable sharders", func(t *testing.T) {
+		_, err := bodyConsensus([]*util.GetResponse{
+			nil,
+			{},
+			newTestResponse(0, ""),
+		}, 3, 25)
+		require.Error(t, err)
+	})
+
+	t.Run("agreed error", func(t *testing.T) {
+		rsp, err := bodyConsensus([]*util.GetResponse{
+			newTestResponse(400, `{"error":"not found"}`),
+			newTestResponse(400, `{"error":"not found"}`),
+		}, 2, 50)
+		require.NoError(t, err)
+		require.Equal(t, 400, rsp.StatusCode)
+	})
+}
+
+func TestIsConsensus(t *testing.T) {
+	sharders := _config.chain.Sharders
+	defer func() { _config.chain.Sharders = sharders }()
+	_config.chain.Sharders = []string{"s1", "s2", "s3", "s4"}
+
+	// the rate is over the queried sharders only
+	require.True(t, isConsensus(2, 2, 100))
+	require.False(t, isConsensus(2, 4, 75))
+	// a single sharder is never a consensus
+	require.False(t, isConsensus(1, 1, 25))
+	require.False(t, isConsensus(0, 0, 25))
+
+	_config.chain.Sharders = []string{"s1"}
+	require.True(t, isConsensus(1, 1, 25))
+
+	_config.chain.Sharders = []string{"s1", "s2", "s3", "s4"}
+	_, err := bodyConsensus([]*util.GetResponse{
+		newTestResponse(200, `{"a":1}`),
+		{PostResponse: &util.PostResponse{Status: "quarantined"}},
+	}, 1, 25)
+	require.Error(t, err)
+}
+
+func TestQueryOutputTypes(t *testing.T) {
+	var blobbers struct {
+		Nodes []*sdk.Blobber `json:"Nodes"`
+	}
+	for _, tc := range []struct {
+		sc   *transaction.SmartContract
+		name string
+		out  interface{}
+	}{
+		{transaction.VestingSmartContract, "getPoolInfo", new(VestingPoolInfo)},
+		{transaction.VestingSmartContract, "getClientPools", new(VestingClientList)},
+		{transaction.VestingSmartContract, "getConfig", new(VestingSCConfig)},
+		{transaction.MinerSmartContract, "getMinerList", new(MinerSCNodes)},
+		{transaction.MinerSmartContract, "getSharderList", new(MinerSCNodes)},
+		{transaction.MinerSmartContract, "nodeStat", new(Miner)},
+		{transaction.MinerSmartContract, "nodePoolStat", new(MinerSCDelegatePoolInfo)},
+		{transaction.MinerSmartContract, "getUserPools", new(MinerSCUserPoolsInfo)},
+		{transaction.MinerSmartContract, "configs", new(MinerSCConfig)},
+		{transaction.StorageSmartContract, "getConfig", new(sdk.StorageSCConfig)},
+		{transaction.StorageSmartContract, "getChallengePoolStat", new(sdk.ChallengePoolInfo)},
+		{transaction.StorageSmartContract, "allocation", new(sdk.Allocation)},
+		{transaction.StorageSmartContract, "allocations", new([]*sdk.Allocation)},
+		{transaction.StorageSmartContract, "getReadPoolStat", new(sdk.AllocationPoolStats)},
+		{transaction.StorageSmartContract, "getWritePoolStat", new(sdk.AllocationPoolStats)},
+		{transaction.StorageSmartContract, "getStakePoolStat", new(sdk.StakePoolInfo)},
+		{transaction.StorageSmartContract, "getUserStakePoolStat", new(sdk.StakePoolUserInfo)},
+		{transaction.StorageSmartContract, "getblobbers", &blobbers},
+		{transaction.StorageSmartContract, "getBlobber", new(sdk.Blobber)},
+		{transaction.MultiSigSmartContract, "getProposal", new(MSProposal)},
+		{transaction.MultiSigSmartContract, "getProposals", new([]*MSProposal)},
+	} {
+		q, ok := tc.sc.Query(tc.name)
+		require.True(t, ok, tc.name)
+		body, err := json.Marshal(reflect.New(q.Output).Interface())
+		require.NoError(t, err)
+		require.NoError(t, tc.sc.DecodeQuery(tc.name, body, tc.out), tc.name)
+	}
+}
+
+func TestParseAccountBalance(t *testing.T) {
+	balance, err := parseAccountBalance(&queryResponse{StatusCode: 200, Body: `{"balance":42}`})
+	require.NoError(t, err)
+	require.EqualValues(t, 42, balance)
+
+	balance, err = parseAccountBalance(&queryResponse{StatusCode: 400, Body: `{"error":"value not present"}`})
+	require.NoError(t, err)
+	require.Zero(t, balance)
+
+	_, err = parseAccountBalance(&queryResponse{StatusCode: 500, Body: "error"})
+	require.Error(t, err)
+	_, err = parseAccountBalance(&queryResponse{StatusCode: 200, Body: "not json"})
+	require.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package zcncore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/0chain/gosdk/core/common/errors"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/util"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// setupAuth registers the auth key of the client with the auth service,
+// the auth service co-signs the transactions signed by the peer key.
+func setupAuth(ctx context.Context, authHost string, data map[string]string) error {
+	req, err := util.NewHTTPPostRequestContext(ctx, strings.TrimRight(authHost, "/")+"/setup", data)
+	if err != nil {
+		return errors.Wrap(err, "new post request failed for auth setup")
+	}
+	res, err := req.Post()
+	if err != nil {
+		return errors.Wrap(err, "auth setup failed")
+	}
+	if res.StatusCode != http.StatusOK {
+		return errors.New("auth_setup_failed", fmt.Sprintf("%s: %s", res.Status, res.Body))
+	}
+	return nil
+}
+
+// PairAuth registers the auth key of the split key wallet with the auth
+// service, which co-signs the transactions signed by the device key, the
+// first key of the wallet. Any previous pairing of the client is replaced.
+// The auth URL is set when the wallet is the current wallet.
+func PairAuth(ctx context.Context, authHost string, w *zcncrypto.Wallet, authKey zcncrypto.KeyPair) error {
+	if len(w.Keys) == 0 {
+		return errors.New("invalid_wallet", "wallet has no device key")
+	}
+	err := setupAuth(ctx, authHost, map[string]string{
+		"client_id":       w.ClientID,
+		"client_key":      w.ClientKey,
+		"public_key":      authKey.PublicKey,
+		"private_key":     authKey.PrivateKey,
+		"peer_public_key": w.Keys[0].PublicKey,
+	})
+	if err != nil {
+		return err
+	}
+	if _config.isSplitWallet && _config.wallet.ClientID == w.ClientID {
+		_config.authUrl = strings.TrimRight(authHost, "/")
+	}
+	return nil
+}
+
+// RotateSplitKeys splits the primary key of the mnemonic into a new device
+// key and auth key, and pairs the auth service with them. The client ID
+// doesn't change. The previous device key is revoked, the auth service no
+// longer co-signs with it. It returns the device wallet, which replaces
+// the current wallet if it is the same client.
+func RotateSplitKeys(ctx context.Context, mnemonic, authHost string) (string, error) {
+	if _config.chain.SignatureScheme != "bls0chain" {
+		return "", errors.New("signature key doesn't support split key")
+	}
+	if zcncrypto.IsMnemonicValid(mnemonic) != true {
+		return "", errors.New("Invalid mnemonic")
+	}
+	primary := zcncrypto.NewBLS0ChainScheme()
+	if _, err := primary.RecoverKeys(mnemonic); err != nil {
+		return "", err
+	}
+	sw, err := primary.SplitKeys(2)
+	if err != nil {
+		return "", errors.Wrap(err, "split key failed.")
+	}
+	authKey := sw.Keys[1]
+	sw.Keys = sw.Keys[:1]
+	if err = PairAuth(ctx, authHost, sw, authKey); err != nil {
+		return "", err
+	}
+	if _config.isSplitWallet && _config.wallet.ClientID == sw.ClientID {
+		_config.wallet = *sw
+		_config.signer = nil
+	}
+	return sw.Marshal()
+}
+
+// WalletMigration is the result of a wallet migration.
+type WalletMigration struct {
+	// AllocationTxns are the hashes of the ownership transfer transactions,
+	// by allocation ID.
+	AllocationTxns map[string]string
+	// TransferTxn is the hash of the balance transfer, empty if there was
+	// no balance left to transfer.
+	TransferTxn string
+	Amount      int64
+}
+
+// MigrateWallet moves the allocations and the balance of the current
+// wallet to the new wallet, e.g. when both split keys are compromised.
+// The new wallet is added as curator of every allocation and takes its
+// ownership, then the balance left after fee is sent to the new wallet.
+func MigrateWallet(ctx context.Context, newWalletStr string, allocationIDs []string, fee int64) (*WalletMigration, error) {
+	if err := checkConfig(); err != nil {
+		return nil, err
+	}
+	nw, err := GetWallet(newWalletStr)
+	if err != nil {
+		return nil, err
+	}
+	m := &WalletMigration{AllocationTxns: make(map[string]string)}
+	for _, allocID := range allocationIDs {
+		_, err = callSmartContract(ctx, transaction.StorageSmartContract,
+			transaction.STORAGESC_ADD_CURATOR, map[string]interface{}{
+				"curator_id":    nw.ClientID,
+				"allocation_id": allocID,
+			}, fee)
+		if err != nil {
+			return m, errors.Wrap(err, "add curator failed for "+allocID)
+		}
+		hash, err := callSmartContractWithWallet(ctx, newWalletStr, transaction.StorageSmartContract,
+			transaction.STORAGESC_CURATOR_TRANSFER, map[string]interface{}{
+				"allocation_id":        allocID,
+				"new_owner_id":         nw.ClientID,
+				"new_owner_public_key": nw.ClientKey,
+			}, fee)
+		if err != nil {
+			return m, errors.Wrap(err, "transfer allocation failed for "+allocID)
+		}
+		m.AllocationTxns[allocID] = hash
+	}
+
+	balance, _, err := getBalanceFromSharders(_config.wallet.ClientID)
+	if err != nil {
+		return m, err
+	}
+	if balance <= fee {
+		return m, nil
+	}
+	waiter := newTxnWaiter()
+	txn, err := NewTransaction(waiter, fee)
+	if err != nil {
+		return m, err
+	}
+	if err = txn.Send(nw.ClientID, balance-fee, "wallet migration"); err != nil {
+		return m, err
+	}
+	if m.TransferTxn, _, err = waiter.wait(ctx, txn); err != nil {
+		return m, err
+	}
+	m.Amount = balance - fee
+	return m, nil
+}
+
+// callSmartContract executes the function with the current wallet and
+// waits for the confirmation.
+func callSmartContract(ctx context.Context, sc *transaction.SmartContract, methodName string, input interface{}, fee int64) (string, error) {
+	waiter := newTxnWaiter()
+	txn, err := NewTransaction(waiter, fee)
+	if err != nil {
+		return "", err
+	}
+	if err = txn.CallSmartContract(sc, methodName, input, 0, fee); err != nil {
+		return "", err
+	}
+	hash, _, err := waiter.wait(ctx, txn)
+	return hash, err
+}
+
+// callSmartContractWithWallet executes the function with the wallet and
+// waits for the confirmation.
+func callSmartContractWithWallet(ctx context.Context, walletStr string, sc *transaction.SmartContract, methodName string, input interface{}, fee int64) (string, error) {
+	w, err := GetWallet(walletStr)
+	if err != nil {
+		return "", err
+	}
+	waiter := newTxnWaiter()
+	txn, err := NewMSTransaction(walletStr, waiter)
+	if err != nil {
+		return "", err
+	}
+	if err = txn.createSmartContractTxn(sc, methodName, input, 0); err != nil {
+		return "", err
+	}
+	txn.SetTransactionFee(fee)
+	go func() {
+		txn.txn.ComputeHashAndSignWithWallet(signWithWallet, w)
+		txn.submitTxn()
+	}()
+	hash, _, err := waiter.wait(ctx, txn)
+	return hash, err
+}
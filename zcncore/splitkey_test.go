@@ -0,0 +1,103 @@
+package zcncore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zcncore/zauthtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSplitKeys(t *testing.T) {
+	defer func(c localConfig) { _config = c }(_config)
+	_config.chain.SignatureScheme = "bls0chain"
+
+	auth := zauthtest.NewServer("bls0chain")
+	defer auth.Close()
+
+	primary, err := zcncrypto.NewBLS0ChainScheme().GenerateKeys()
+	require.NoError(t, err)
+
+	var devices []*zcncrypto.Wallet
+	for i := 0; i < 2; i++ {
+		wStr, err := RotateSplitKeys(context.Background(), primary.Mnemonic, auth.URL)
+		require.NoError(t, err)
+		w, err := GetWallet(wStr)
+		require.NoError(t, err)
+		require.Equal(t, primary.ClientID, w.ClientID)
+		require.Equal(t, primary.ClientKey, w.ClientKey)
+		require.Len(t, w.Keys, 1)
+		devices = append(devices, w)
+	}
+	require.NotEqual(t, devices[0].Keys[0].PublicKey, devices[1].Keys[0].PublicKey)
+
+	// the auth service co-signs for the last device key only
+	p := auth.Pairing(primary.ClientID)
+	require.NotNil(t, p)
+	require.Equal(t, devices[1].Keys[0].PublicKey, p.PeerPublicKey)
+
+	// the device and auth signatures aggregate into a signature of the
+	// client key
+	hash := zcncrypto.Sha3Sum256("rotated")
+	sign := func(privateKey string) string {
+		s := zcncrypto.NewBLS0ChainScheme()
+		require.NoError(t, s.SetPrivateKey(privateKey))
+		sig, err := s.Sign(hash)
+		require.NoError(t, err)
+		return sig
+	}
+	sig, err := zcncrypto.AggregateSignatures("bls0chain",
+		sign(devices[1].Keys[0].PrivateKey), sign(p.PrivateKey))
+	require.NoError(t, err)
+	v := zcncrypto.NewBLS0ChainScheme()
+	require.NoError(t, v.SetPublicKey(primary.ClientKey))
+	ok, err := v.Verify(sig, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = RotateSplitKeys(context.Background(), "not a mnemonic", auth.URL)
+	require.Error(t, err)
+
+	_config.chain.SignatureScheme = "ed25519"
+	_, err = RotateSplitKeys(context.Background(), primary.Mnemonic, auth.URL)
+	require.Error(t, err)
+}
+
+func TestMigrateWallet(t *testing.T) {
+	defer func(c localConfig) { _config = c }(_config)
+	_config.chain.SignatureScheme = "bls0chain"
+
+	newWallet, err := zcncrypto.NewBLS0ChainScheme().GenerateKeys()
+	require.NoError(t, err)
+	newWalletStr, err := newWallet.Marshal()
+	require.NoError(t, err)
+
+	_, err = MigrateWallet(context.Background(), newWalletStr, nil, 10)
+	require.Error(t, err)
+
+	old, err := zcncrypto.NewBLS0ChainScheme().GenerateKeys()
+	require.NoError(t, err)
+	sharder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"client_id":%q,"balance":5}`, old.ClientID)
+	}))
+	defer sharder.Close()
+	_config.chain.Miners = []string{"http://127.0.0.1:0"}
+	_config.chain.Sharders = []string{sharder.URL}
+	_config.isConfigured = true
+	_config.wallet = *old
+	_config.isValidWallet = true
+
+	_, err = MigrateWallet(context.Background(), "not a wallet", nil, 10)
+	require.Error(t, err)
+
+	// no balance is left to transfer after fee
+	m, err := MigrateWallet(context.Background(), newWalletStr, nil, 10)
+	require.NoError(t, err)
+	require.Empty(t, m.AllocationTxns)
+	require.Empty(t, m.TransferTxn)
+	require.Zero(t, m.Amount)
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/0chain/gosdk/core/block"
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/node"
 	"github.com/0chain/gosdk/core/transaction"
 	"github.com/0chain/gosdk/core/util"
 	"github.com/0chain/gosdk/core/zcncrypto"
@@ -80,6 +82,7 @@ type Transaction struct {
 	verifyStatus int
 	verifyOut    string
 	verifyError  error
+	verifyTxn    *transaction.Transaction
 }
 
 // TransactionScheme implements few methods for block chain.
@@ -97,6 +100,8 @@ type TransactionScheme interface {
 	ExecuteSmartContract(address, methodName, jsoninput string, val int64) error
 	// ExecuteFaucetSCWallet impements the Faucet Smart contract for a given wallet
 	ExecuteFaucetSCWallet(walletStr string, methodName string, input []byte) error
+	// CallSmartContract executes a declared function of the smart contract
+	CallSmartContract(sc *transaction.SmartContract, methodName string, input interface{}, val, fee int64) error
 	// GetTransactionHash implements retrieval of hash of the submitted transaction
 	GetTransactionHash() string
 	// LockTokens implements the lock token.
@@ -117,6 +122,9 @@ type TransactionScheme interface {
 	GetTransactionError() string
 	// GetVerifyError implements error string incase of verify failure error
 	GetVerifyError() string
+	// GetVerifyResult returns the error of a transaction rejected by the
+	// smart contract, or decodes the output of the confirmed transaction
+	GetVerifyResult(out interface{}) error
 
 	// Output of transaction.
 	Output() []byte
@@ -153,9 +161,7 @@ type TransactionScheme interface {
 }
 
 func signFn(hash string) (string, error) {
-	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
-	sigScheme.SetPrivateKey(_config.wallet.Keys[0].PrivateKey)
-	return sigScheme.Sign(hash)
+	return GetSigner().Sign(hash)
 }
 
 func signWithWallet(hash string, wi interface{}) (string, error) {
@@ -167,6 +173,7 @@ func signWithWallet(hash string, wi interface{}) (string, error) {
 	}
 	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
 	sigScheme.SetPrivateKey(w.Keys[0].PrivateKey)
+	defer zcncrypto.WipeKeys(sigScheme)
 	return sigScheme.Sign(hash)
 }
 
@@ -226,7 +233,8 @@ func (t *Transaction) submitTxn() {
 	defer close(result)
 	var tSuccessRsp string
 	var tFailureRsp string
-	randomMiners := util.GetRandom(_config.chain.Miners, getMinMinersSubmit())
+	refreshMinerRounds()
+	randomMiners := node.Miners.Select(_config.chain.Miners, getMinMinersSubmit())
 	for _, miner := range randomMiners {
 		go func(minerurl string) {
 			url := minerurl + PUT_TRANSACTION
@@ -236,7 +244,9 @@ func (t *Transaction) submitTxn() {
 				Logger.Error(minerurl, " new post request failed. ", err.Error())
 				return
 			}
+			start := time.Now()
 			res, err := req.Post()
+			node.Miners.ReportResponse(minerurl, start, res.StatusCode, err)
 			if err != nil {
 				Logger.Error(minerurl, " submit transaction error. ", err.Error())
 			}
@@ -343,8 +353,7 @@ func (t *Transaction) StoreData(data string) error {
 	return nil
 }
 
-func (t *Transaction) createSmartContractTxn(address, methodName string, input interface{}, value int64) error {
-	sn := transaction.SmartContractTxnData{Name: methodName, InputArgs: input}
+func (t *Transaction) setSmartContractTxn(address string, sn *transaction.SmartContractTxnData, value int64) error {
 	snBytes, err := json.Marshal(sn)
 	if err != nil {
 		return errors.Wrap(err, "create smart contract failed due to invalid data.")
@@ -356,13 +365,22 @@ func (t *Transaction) createSmartContractTxn(address, methodName string, input i
 	return nil
 }
 
+func (t *Transaction) createSmartContractTxn(sc *transaction.SmartContract, methodName string, input interface{}, value int64) error {
+	sn, err := sc.TxnData(methodName, input)
+	if err != nil {
+		return err
+	}
+	return t.setSmartContractTxn(sc.Address, sn, value)
+}
+
 func (t *Transaction) createFaucetSCWallet(walletStr string, methodName string, input []byte) (*zcncrypto.Wallet, error) {
 	w, err := GetWallet(walletStr)
 	if err != nil {
 		fmt.Printf("Error while parsing the wallet. %v\n", err)
 		return nil, err
 	}
-	err = t.createSmartContractTxn(FaucetSmartContractAddress, methodName, input, 0)
+	err = t.setSmartContractTxn(FaucetSmartContractAddress,
+		&transaction.SmartContractTxnData{Name: methodName, InputArgs: input}, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -386,7 +404,8 @@ func (t *Transaction) ExecuteFaucetSCWallet(walletStr string, methodName string,
 func (t *Transaction) ExecuteSmartContract(address, methodName, jsoninput string, val int64) error {
 	scData := make(map[string]interface{})
 	json.Unmarshal([]byte(jsoninput), &scData)
-	err := t.createSmartContractTxn(address, methodName, scData, val)
+	err := t.setSmartContractTxn(address,
+		&transaction.SmartContractTxnData{Name: methodName, InputArgs: scData}, val)
 	if err != nil {
 		return err
 	}
@@ -396,6 +415,19 @@ func (t *Transaction) ExecuteSmartContract(address, methodName, jsoninput string
 	return nil
 }
 
+// CallSmartContract executes the declared function of the smart contract.
+// The function and the type of the input are checked against the
+// declaration.
+func (t *Transaction) CallSmartContract(sc *transaction.SmartContract, methodName string, input interface{}, val, fee int64) error {
+	err := t.createSmartContractTxn(sc, methodName, input, val)
+	if err != nil {
+		return err
+	}
+	t.SetTransactionFee(fee)
+	go func() { t.submitTxn() }()
+	return nil
+}
+
 func (t *Transaction) SetTransactionHash(hash string) error {
 	if t.txnStatus != StatusUnknown {
 		return errors.New("transaction already exists. cannot set transaction hash.")
@@ -429,24 +461,61 @@ func (t *Transaction) GetTransactionHash() string {
 }
 
 func queryFromSharders(numSharders int, query string,
-	result chan *util.GetResponse) {
+	result chan *util.GetResponse) int {
+
+	return queryFromShardersContext(context.Background(), numSharders, query, result)
+}
+
+// minConsensusSharders is the least number of sharders that must agree on
+// a response, so that one sharder cannot make a consensus on its own when
+// the others are quarantined.
+const minConsensusSharders = 2
 
-	queryFromShardersContext(context.Background(), numSharders, query, result)
+// isConsensus reports whether count agreeing sharders reach the percent of
+// the queried sharders, quarantined ones excluded, and the floor of
+// minConsensusSharders, or of all the sharders if there are fewer.
+func isConsensus(count, queried int, percent float32) bool {
+	if queried <= 0 {
+		return false
+	}
+	var floor = minConsensusSharders
+	if n := len(_config.chain.Sharders); n < floor {
+		floor = n
+	}
+	return count >= floor && float32(count)*100/float32(queried) >= percent
 }
 
+// queryFromShardersContext queries all the sharders and sends a response
+// of each to the result channel. Quarantined sharders are not queried and
+// get a placeholder response. It returns the number of sharders queried.
 func queryFromShardersContext(ctx context.Context, numSharders int,
-	query string, result chan *util.GetResponse) {
+	query string, result chan *util.GetResponse) int {
+
+	var available = make(map[string]bool)
+	for _, sharder := range node.Sharders.Available(_config.chain.Sharders) {
+		available[sharder] = true
+	}
 
 	for _, sharder := range util.Shuffle(_config.chain.Sharders) {
 		go func(sharderurl string) {
-			Logger.Info("Query from ", sharderurl+query)
 			url := fmt.Sprintf("%v%v", sharderurl, query)
+			if !available[sharderurl] {
+				Logger.Debug("Skipping quarantined sharder ", sharderurl)
+				result <- &util.GetResponse{PostResponse: &util.PostResponse{
+					Url:    url,
+					Status: "quarantined",
+				}}
+				return
+			}
+			Logger.Info("Query from ", sharderurl+query)
 			req, err := util.NewHTTPGetRequestContext(ctx, url)
 			if err != nil {
 				Logger.Error(sharderurl, " new get request failed. ", err.Error())
 				return
 			}
+			start := time.Now()
 			res, err := req.Get()
+			node.Sharders.ReportResponse(sharderurl, start, res.StatusCode, err)
 			if err != nil {
 				Logger.Error(sharderurl, " get error. ", err.Error())
 			}
@@ -454,6 +523,7 @@ func queryFromShardersContext(ctx context.Context, numSharders int,
 			return
 		}(sharder)
 	}
+	return len(available)
 }
 
 func getBlockHeaderFromTransactionConfirmation(txnHash string, cfmBlock map[string]json.RawMessage) (*blockHeader, error) {
@@ -495,6 +565,19 @@ func getBlockHeaderFromTransactionConfirmation(txnHash string, cfmBlock map[stri
 	return nil, errors.New("txn confirmation not found.")
 }
 
+// getConfirmedTransaction returns the transaction of the confirmation with
+// its execution status.
+func getConfirmedTransaction(cfmLfb map[string]json.RawMessage) *transaction.Transaction {
+	var cfm confirmation
+	if err := json.Unmarshal(cfmLfb["confirmation"], &cfm); err != nil || cfm.Transaction == nil {
+		return nil
+	}
+	if cfm.Transaction.Status == 0 {
+		cfm.Transaction.Status = cfm.Status
+	}
+	return cfm.Transaction
+}
+
 func getTransactionConfirmation(numSharders int, txnHash string) (*blockHeader, map[string]json.RawMessage, *blockHeader, error) {
 	result := make(chan *util.GetResponse)
 	defer close(result)
@@ -573,6 +656,8 @@ func GetLatestFinalized(ctx context.Context, numSharders int) (b *block.Header,
 			continue
 		}
 
+		node.Sharders.ReportRound(strings.TrimSuffix(rsp.Url, GET_LATEST_FINALIZED), b.Round)
+
 		var h = encryption.FastHash([]byte(b.Hash))
 		if roundConsensus[h]++; roundConsensus[h] > maxConsensus {
 			maxConsensus = roundConsensus[h]
@@ -776,6 +861,64 @@ func GetMagicBlockByNumber(ctx context.Context, numSharders int, number int64) (
 	return
 }
 
+// GetMagicBlockBlockByNumber returns the block carrying the magic block of
+// the number, with its header and verification tickets, so that the magic
+// block can be verified against the miners of the previous one.
+func GetMagicBlockBlockByNumber(ctx context.Context, numSharders int, number int64) (*block.Block, error) {
+	var result = make(chan *util.GetResponse, numSharders)
+	defer close(result)
+
+	numSharders = len(_config.chain.Sharders) // overwrite, use all
+	queryFromShardersContext(ctx, numSharders,
+		fmt.Sprintf("%smagic_block_number=%d", GET_MAGIC_BLOCK_INFO, number),
+		result)
+
+	var (
+		maxConsensus   int
+		roundConsensus = make(map[string]int)
+		carrier        *block.Block
+	)
+
+	for i := 0; i < numSharders; i++ {
+		var rsp = <-result
+
+		Logger.Debug(rsp.Url, rsp.Status)
+
+		if rsp.StatusCode != http.StatusOK {
+			Logger.Error(rsp.Body)
+			continue
+		}
+
+		var b block.Block
+		if err := json.Unmarshal([]byte(rsp.Body), &b); err != nil || b.MagicBlock == nil {
+			Logger.Error(" magic block parse error: ", err)
+			continue
+		}
+
+		var h = encryption.FastHash([]byte(string(b.Hash) + ":" + b.MagicBlock.Hash))
+		if roundConsensus[h]++; roundConsensus[h] > maxConsensus {
+			maxConsensus = roundConsensus[h]
+			carrier = &b
+		}
+	}
+
+	if maxConsensus == 0 {
+		return nil, errors.New("magic block info not found")
+	}
+
+	b, err := GetBlockByRound(ctx, numSharders, carrier.Round)
+	if err != nil {
+		return nil, err
+	}
+	if b.Hash != carrier.Hash {
+		return nil, errors.New(fmt.Sprintf("magic block carrier is not the block of round %d", carrier.Round))
+	}
+	if b.MagicBlock == nil {
+		b.MagicBlock = carrier.MagicBlock
+	}
+	return b, nil
+}
+
 func getBlockInfoByRound(numSharders int, round int64, content string) (*blockHeader, error) {
 	result := make(chan *util.GetResponse)
 	defer close(result)
@@ -928,6 +1071,12 @@ func (t *Transaction) Verify() error {
 					t.completeVerify(StatusError, "", errors.New(`{"error": "transaction confirmation json marshal error"`))
 					return
 				}
+				t.verifyTxn = getConfirmedTransaction(confirmation)
+				if t.verifyTxn != nil && t.verifyTxn.IsFailed() &&
+					_config.chain.SmartContractErrorStatus {
+					t.completeVerify(StatusSmartContractError, string(output), t.verifyTxn.OutputError())
+					return
+				}
 				t.completeVerify(StatusSuccess, string(output), nil)
 				return
 			}
@@ -957,6 +1106,22 @@ func (t *Transaction) GetVerifyError() string {
 	return ""
 }
 
+// GetVerifyResult returns the error of the verification, typed by code if
+// the transaction was rejected by the smart contract even when reported
+// with StatusSuccess, or decodes the output of the confirmed transaction
+// into out. A *string out receives the raw output.
+func (t *Transaction) GetVerifyResult(out interface{}) error {
+	switch {
+	case t.verifyStatus == StatusUnknown:
+		return errors.New("verify_error", "transaction not verified")
+	case t.verifyStatus != StatusSuccess:
+		return t.verifyError
+	case t.verifyTxn == nil:
+		return errors.New("verify_error", "missing confirmed transaction")
+	}
+	return t.verifyTxn.DecodeOutput(out)
+}
+
 // ========================================================================== //
 //                               vesting pool                                 //
 // ========================================================================== //
@@ -968,7 +1133,7 @@ type vestingRequest struct {
 func (t *Transaction) vestingPoolTxn(function string, poolID string,
 	value int64) error {
 
-	return t.createSmartContractTxn(VestingSmartContractAddress,
+	return t.createSmartContractTxn(transaction.VestingSmartContract,
 		function, vestingRequest{PoolID: common.Key(poolID)}, int64(value))
 }
 
@@ -990,7 +1155,7 @@ type VestingStopRequest struct {
 
 func (t *Transaction) VestingStop(sr *VestingStopRequest) (err error) {
 
-	err = t.createSmartContractTxn(VestingSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.VestingSmartContract,
 		transaction.VESTING_STOP, sr, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1026,7 +1191,7 @@ type VestingAddRequest struct {
 func (t *Transaction) VestingAdd(ar *VestingAddRequest, value int64) (
 	err error) {
 
-	err = t.createSmartContractTxn(VestingSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.VestingSmartContract,
 		transaction.VESTING_ADD, ar, value)
 	if err != nil {
 		Logger.Error(err)
@@ -1049,7 +1214,7 @@ func (t *Transaction) VestingDelete(poolID string) (err error) {
 
 func (t *Transaction) VestingUpdateConfig(vscc *VestingSCConfig) (err error) {
 
-	err = t.createSmartContractTxn(VestingSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.VestingSmartContract,
 		transaction.VESTING_UPDATE_CONFIG, vscc, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1113,7 +1278,7 @@ type SimpleMienrSCMinerInfo struct {
 }
 
 func (t *Transaction) MinerSCSettings(info *MinerSCMinerInfo) (err error) {
-	err = t.createSmartContractTxn(MinerSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.MinerSmartContract,
 		transaction.MINERSC_SETTINGS, info, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1132,7 +1297,7 @@ func (t *Transaction) MinerSCLock(nodeID string, lock int64) (err error) {
 	var mscl MinerSCLock
 	mscl.ID = nodeID
 
-	err = t.createSmartContractTxn(MinerSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.MinerSmartContract,
 		transaction.MINERSC_LOCK, &mscl, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -1152,7 +1317,7 @@ func (t *Transaction) MienrSCUnlock(nodeID, poolID string) (err error) {
 	mscul.ID = nodeID
 	mscul.PoolID = poolID
 
-	err = t.createSmartContractTxn(MinerSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.MinerSmartContract,
 		transaction.MINERSC_UNLOCK, &mscul, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1169,7 +1334,7 @@ func (t *Transaction) MienrSCUnlock(nodeID, poolID string) (err error) {
 func (t *Transaction) createLockTokensTxn(val int64, durationHr int64, durationMin int) error {
 	lockInput := make(map[string]interface{})
 	lockInput["duration"] = fmt.Sprintf("%dh%dm", durationHr, durationMin)
-	err := t.createSmartContractTxn(InterestPoolSmartContractAddress, transaction.LOCK_TOKEN, lockInput, val)
+	err := t.createSmartContractTxn(transaction.InterestPoolSmartContract, transaction.LOCK_TOKEN, lockInput, val)
 	return err
 }
 
@@ -1188,7 +1353,7 @@ func (t *Transaction) LockTokens(val int64, durationHr int64, durationMin int) e
 func (t *Transaction) createUnlockTokensTxn(poolID string) error {
 	unlockInput := make(map[string]interface{})
 	unlockInput["pool_id"] = poolID
-	return t.createSmartContractTxn(InterestPoolSmartContractAddress, transaction.UNLOCK_TOKEN, unlockInput, 0)
+	return t.createSmartContractTxn(transaction.InterestPoolSmartContract, transaction.UNLOCK_TOKEN, unlockInput, 0)
 }
 
 func (t *Transaction) UnlockTokens(poolID string) error {
@@ -1216,16 +1381,11 @@ func (t *Transaction) RegisterMultiSig(walletstr string, mswallet string) error
 		fmt.Printf("\nError in registering. %v\n", err)
 		return err
 	}
-	sn := transaction.SmartContractTxnData{Name: MultiSigRegisterFuncName, InputArgs: msw}
-	snBytes, err := json.Marshal(sn)
+	err = t.createSmartContractTxn(transaction.MultiSigSmartContract, MultiSigRegisterFuncName, msw, 0)
 	if err != nil {
 		return errors.Wrap(err, "execute multisig register failed due to invalid data.")
 	}
 	go func() {
-		t.txn.TransactionType = transaction.TxnTypeSmartContract
-		t.txn.ToClientID = MultiSigSmartContractAddress
-		t.txn.TransactionData = string(snBytes)
-		t.txn.Value = 0
 		t.txn.ComputeHashAndSignWithWallet(signWithWallet, w)
 		t.submitTxn()
 	}()
@@ -1261,16 +1421,11 @@ func (t *Transaction) RegisterVote(signerwalletstr string, msvstr string) error
 		fmt.Printf("\nError in voting. %v\n", err)
 		return err
 	}
-	sn := transaction.SmartContractTxnData{Name: MultiSigVoteFuncName, InputArgs: msv}
-	snBytes, err := json.Marshal(sn)
+	err = t.createSmartContractTxn(transaction.MultiSigSmartContract, MultiSigVoteFuncName, msv, 0)
 	if err != nil {
 		return errors.Wrap(err, "execute multisig vote failed due to invalid data.")
 	}
 	go func() {
-		t.txn.TransactionType = transaction.TxnTypeSmartContract
-		t.txn.ToClientID = MultiSigSmartContractAddress
-		t.txn.TransactionData = string(snBytes)
-		t.txn.Value = 0
 		t.txn.ComputeHashAndSignWithWallet(signWithWallet, w)
 		t.submitTxn()
 	}()
@@ -1310,7 +1465,7 @@ func (t *Transaction) FinalizeAllocation(allocID string, fee int64) (
 	type finiRequest struct {
 		AllocationID string `json:"allocation_id"`
 	}
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_FINALIZE_ALLOCATION, &finiRequest{
 			AllocationID: allocID,
 		}, 0)
@@ -1330,7 +1485,7 @@ func (t *Transaction) CancelAllocation(allocID string, fee int64) (
 	type cancelRequest struct {
 		AllocationID string `json:"allocation_id"`
 	}
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_CANCEL_ALLOCATION, &cancelRequest{
 			AllocationID: allocID,
 		}, 0)
@@ -1367,7 +1522,7 @@ type CreateAllocationRequest struct {
 func (t *Transaction) CreateAllocation(car *CreateAllocationRequest,
 	lock, fee int64) (err error) {
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_CREATE_ALLOCATION, car, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -1381,7 +1536,7 @@ func (t *Transaction) CreateAllocation(car *CreateAllocationRequest,
 // CreateReadPool for current user.
 func (t *Transaction) CreateReadPool(fee int64) (err error) {
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_CREATE_READ_POOL, nil, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1409,7 +1564,7 @@ func (t *Transaction) ReadPoolLock(allocID, blobberID string,
 	lr.AllocationID = allocID
 	lr.BlobberID = blobberID
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_READ_POOL_LOCK, &lr, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -1425,7 +1580,7 @@ func (t *Transaction) ReadPoolUnlock(poolID string, fee int64) (err error) {
 	type unlockRequest struct {
 		PoolID string `json:"pool_id"`
 	}
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_READ_POOL_UNLOCK, &unlockRequest{
 			PoolID: poolID,
 		}, 0)
@@ -1449,7 +1604,7 @@ func (t *Transaction) StakePoolLock(blobberID string, lock, fee int64) (
 	var spr stakePoolRequest
 	spr.BlobberID = blobberID
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_STAKE_POOL_LOCK, &spr, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -1473,7 +1628,7 @@ func (t *Transaction) StakePoolUnlock(blobberID, poolID string,
 	spr.BlobberID = blobberID
 	spr.PoolID = poolID
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_STAKE_POOL_UNLOCK, &spr, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1495,7 +1650,7 @@ func (t *Transaction) StakePoolPayInterests(blobberID string, fee int64) (
 	var spr stakePoolRequest
 	spr.BlobberID = blobberID
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_STAKE_POOL_PAY_INTERESTS, &spr, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1534,7 +1689,7 @@ type Blobber struct {
 // UpdateBlobberSettings update settings of a blobber.
 func (t *Transaction) UpdateBlobberSettings(b *Blobber, fee int64) (err error) {
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_UPDATE_BLOBBER_SETTINGS, b, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -1560,7 +1715,7 @@ func (t *Transaction) UpdateAllocation(allocID string, sizeDiff int64,
 	uar.Size = sizeDiff
 	uar.Expiration = expirationDiff
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_UPDATE_ALLOCATION, &uar, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -1588,7 +1743,7 @@ func (t *Transaction) WritePoolLock(allocID, blobberID string, duration int64,
 	lr.AllocationID = allocID
 	lr.BlobberID = blobberID
 
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_WRITE_POOL_LOCK, &lr, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -1606,7 +1761,7 @@ func (t *Transaction) WritePoolUnlock(poolID string, fee int64) (
 	type unlockRequest struct {
 		PoolID string `json:"pool_id"`
 	}
-	err = t.createSmartContractTxn(StorageSmartContractAddress,
+	err = t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_WRITE_POOL_UNLOCK, &unlockRequest{
 			PoolID: poolID,
 		}, 0)
@@ -100,10 +100,12 @@ func verifyFn(signature, msgHash, publicKey string) (bool, error) {
 
 func (ta *TransactionWithAuth) sign(otherSig string) error {
 	ta.t.txn.ComputeHashData()
-	sig := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
-	sig.SetPrivateKey(_config.wallet.Keys[0].PrivateKey)
-	var err error
-	ta.t.txn.Signature, err = sig.Add(otherSig, ta.t.txn.Hash)
+	sig, err := signFn(ta.t.txn.Hash)
+	if err != nil {
+		return err
+	}
+	ta.t.txn.Signature, err = zcncrypto.AggregateSignatures(
+		_config.chain.SignatureScheme, otherSig, sig)
 	return err
 }
 
@@ -163,7 +165,8 @@ func (ta *TransactionWithAuth) ExecuteFaucetSCWallet(walletStr string, methodNam
 func (ta *TransactionWithAuth) ExecuteSmartContract(address, methodName, jsoninput string, val int64) error {
 	scData := make(map[string]interface{})
 	json.Unmarshal([]byte(jsoninput), &scData)
-	err := ta.t.createSmartContractTxn(address, methodName, scData, val)
+	err := ta.t.setSmartContractTxn(address,
+		&transaction.SmartContractTxnData{Name: methodName, InputArgs: scData}, val)
 	if err != nil {
 		return err
 	}
@@ -173,6 +176,16 @@ func (ta *TransactionWithAuth) ExecuteSmartContract(address, methodName, jsoninp
 	return nil
 }
 
+func (ta *TransactionWithAuth) CallSmartContract(sc *transaction.SmartContract, methodName string, input interface{}, val, fee int64) error {
+	err := ta.t.createSmartContractTxn(sc, methodName, input, val)
+	if err != nil {
+		return err
+	}
+	ta.t.SetTransactionFee(fee)
+	go func() { ta.submitTxn() }()
+	return nil
+}
+
 func (ta *TransactionWithAuth) SetTransactionHash(hash string) error {
 	return ta.t.SetTransactionHash(hash)
 }
@@ -197,6 +210,10 @@ func (ta *TransactionWithAuth) GetVerifyError() string {
 	return ta.t.GetVerifyError()
 }
 
+func (ta *TransactionWithAuth) GetVerifyResult(out interface{}) error {
+	return ta.t.GetVerifyResult(out)
+}
+
 func (ta *TransactionWithAuth) Output() []byte {
 	return []byte(ta.t.txnOut)
 }
@@ -216,7 +233,7 @@ func (ta *TransactionWithAuth) VestingTrigger(poolID string) (err error) {
 }
 
 func (ta *TransactionWithAuth) VestingStop(sr *VestingStopRequest) (err error) {
-	err = ta.t.createSmartContractTxn(VestingSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.VestingSmartContract,
 		transaction.VESTING_STOP, sr, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -240,7 +257,7 @@ func (ta *TransactionWithAuth) VestingUnlock(poolID string) (err error) {
 func (ta *TransactionWithAuth) VestingAdd(ar *VestingAddRequest,
 	value int64) (err error) {
 
-	err = ta.t.createSmartContractTxn(VestingSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.VestingSmartContract,
 		transaction.VESTING_ADD, ar, value)
 	if err != nil {
 		Logger.Error(err)
@@ -263,7 +280,7 @@ func (ta *TransactionWithAuth) VestingDelete(poolID string) (err error) {
 func (ta *TransactionWithAuth) VestingUpdateConfig(
 	vscc *VestingSCConfig) (err error) {
 
-	err = ta.t.createSmartContractTxn(VestingSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.VestingSmartContract,
 		transaction.VESTING_UPDATE_CONFIG, vscc, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -280,7 +297,7 @@ func (ta *TransactionWithAuth) VestingUpdateConfig(
 func (ta *TransactionWithAuth) MinerSCSettings(info *MinerSCMinerInfo) (
 	err error) {
 
-	err = ta.t.createSmartContractTxn(MinerSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.MinerSmartContract,
 		transaction.MINERSC_SETTINGS, info, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -296,7 +313,7 @@ func (ta *TransactionWithAuth) MinerSCLock(minerID string,
 	var mscl MinerSCLock
 	mscl.ID = minerID
 
-	err = ta.t.createSmartContractTxn(MinerSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.MinerSmartContract,
 		transaction.MINERSC_LOCK, &mscl, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -313,7 +330,7 @@ func (ta *TransactionWithAuth) MienrSCUnlock(nodeID, poolID string) (
 	mscul.ID = nodeID
 	mscul.PoolID = poolID
 
-	err = ta.t.createSmartContractTxn(MinerSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.MinerSmartContract,
 		transaction.MINERSC_UNLOCK, &mscul, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -367,7 +384,7 @@ func (ta *TransactionWithAuth) FinalizeAllocation(allocID string, fee int64) (
 	type finiRequest struct {
 		AllocationID string `json:"allocation_id"`
 	}
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_FINALIZE_ALLOCATION, &finiRequest{
 			AllocationID: allocID,
 		}, 0)
@@ -387,7 +404,7 @@ func (ta *TransactionWithAuth) CancelAllocation(allocID string, fee int64) (
 	type cancelRequest struct {
 		AllocationID string `json:"allocation_id"`
 	}
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_CANCEL_ALLOCATION, &cancelRequest{
 			AllocationID: allocID,
 		}, 0)
@@ -404,7 +421,7 @@ func (ta *TransactionWithAuth) CancelAllocation(allocID string, fee int64) (
 func (ta *TransactionWithAuth) CreateAllocation(car *CreateAllocationRequest,
 	lock, fee int64) (err error) {
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_CREATE_ALLOCATION, car, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -418,7 +435,7 @@ func (ta *TransactionWithAuth) CreateAllocation(car *CreateAllocationRequest,
 // CreateReadPool for current user.
 func (ta *TransactionWithAuth) CreateReadPool(fee int64) (err error) {
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_CREATE_READ_POOL, nil, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -446,7 +463,7 @@ func (ta *TransactionWithAuth) ReadPoolLock(allocID, blobberID string,
 	lr.AllocationID = allocID
 	lr.BlobberID = blobberID
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_READ_POOL_LOCK, &lr, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -464,7 +481,7 @@ func (ta *TransactionWithAuth) ReadPoolUnlock(poolID string, fee int64) (
 	type unlockRequest struct {
 		PoolID string `json:"pool_id"`
 	}
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_READ_POOL_UNLOCK, &unlockRequest{
 			PoolID: poolID,
 		}, 0)
@@ -488,7 +505,7 @@ func (ta *TransactionWithAuth) StakePoolLock(blobberID string,
 	var spr stakePoolRequest
 	spr.BlobberID = blobberID
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_STAKE_POOL_LOCK, &spr, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -512,7 +529,7 @@ func (ta *TransactionWithAuth) StakePoolUnlock(blobberID, poolID string,
 	spr.BlobberID = blobberID
 	spr.PoolID = poolID
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_STAKE_POOL_UNLOCK, &spr, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -534,7 +551,7 @@ func (ta *TransactionWithAuth) StakePoolPayInterests(blobberID string,
 	var spr stakePoolRequest
 	spr.BlobberID = blobberID
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_STAKE_POOL_PAY_INTERESTS, &spr, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -549,7 +566,7 @@ func (ta *TransactionWithAuth) StakePoolPayInterests(blobberID string,
 func (ta *TransactionWithAuth) UpdateBlobberSettings(blob *Blobber, fee int64) (
 	err error) {
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_UPDATE_BLOBBER_SETTINGS, blob, 0)
 	if err != nil {
 		Logger.Error(err)
@@ -575,7 +592,7 @@ func (ta *TransactionWithAuth) UpdateAllocation(allocID string, sizeDiff int64,
 	uar.Size = sizeDiff
 	uar.Expiration = expirationDiff
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_UPDATE_ALLOCATION, &uar, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -603,7 +620,7 @@ func (ta *TransactionWithAuth) WritePoolLock(allocID, blobberID string,
 	lr.AllocationID = allocID
 	lr.BlobberID = blobberID
 
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_WRITE_POOL_LOCK, &lr, lock)
 	if err != nil {
 		Logger.Error(err)
@@ -621,7 +638,7 @@ func (ta *TransactionWithAuth) WritePoolUnlock(poolID string, fee int64) (
 	type unlockRequest struct {
 		PoolID string `json:"pool_id"`
 	}
-	err = ta.t.createSmartContractTxn(StorageSmartContractAddress,
+	err = ta.t.createSmartContractTxn(transaction.StorageSmartContract,
 		transaction.STORAGESC_WRITE_POOL_UNLOCK, &unlockRequest{
 			PoolID: poolID,
 		}, 0)
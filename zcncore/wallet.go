@@ -16,6 +16,8 @@ import (
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/common/errors"
 	"github.com/0chain/gosdk/core/logger"
+	"github.com/0chain/gosdk/core/node"
+	"github.com/0chain/gosdk/core/transaction"
 	"github.com/0chain/gosdk/core/util"
 	"github.com/0chain/gosdk/core/version"
 	"github.com/0chain/gosdk/core/zcncrypto"
@@ -31,6 +33,9 @@ type ChainConfig struct {
 	MinSubmit               int      `json:"min_submit"`
 	MinConfirmation         int      `json:"min_confirmation"`
 	ConfirmationChainLength int      `json:"confirmation_chain_length"`
+	// SmartContractErrorStatus reports transactions rejected by the smart
+	// contract with StatusSmartContractError instead of StatusSuccess
+	SmartContractErrorStatus bool `json:"sc_error_status,omitempty"`
 }
 
 var defaultLogLevel = logger.DEBUG
@@ -86,14 +91,14 @@ const (
 )
 
 const (
-	StorageSmartContractAddress      = `6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d7`
-	VestingSmartContractAddress      = `2bba5b05949ea59c80aed3ac3474d7379d3be737e8eb5a968c52295e48333ead`
-	FaucetSmartContractAddress       = `6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d3`
-	InterestPoolSmartContractAddress = `cf8d0df9bd8cc637a4ff4e792ffe3686da6220c45f0e1103baa609f3f1751ef4`
-	MultiSigSmartContractAddress     = `27b5ef7120252b79f9dd9c05505dd28f328c80f6863ee446daede08a84d651a7`
-	MinerSmartContractAddress        = `6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d9`
-	MultiSigRegisterFuncName         = "register"
-	MultiSigVoteFuncName             = "vote"
+	StorageSmartContractAddress      = transaction.StorageSmartContractAddress
+	VestingSmartContractAddress      = transaction.VestingSmartContractAddress
+	FaucetSmartContractAddress       = transaction.FaucetSmartContractAddress
+	InterestPoolSmartContractAddress = transaction.InterestPoolSmartContractAddress
+	MultiSigSmartContractAddress     = transaction.MultiSigSmartContractAddress
+	MinerSmartContractAddress        = transaction.MinerSmartContractAddress
+	MultiSigRegisterFuncName         = transaction.MULTISIG_REGISTER
+	MultiSigVoteFuncName             = transaction.MULTISIG_VOTE
 )
 
 // In percentage
@@ -117,7 +122,10 @@ const (
 	StatusAuthError        int = 5
 	StatusAuthVerifyFailed int = 6
 	StatusAuthTimeout      int = 7
-	StatusUnknown          int = -1
+	// StatusSmartContractError is set when the transaction is confirmed but
+	// rejected by the smart contract, see WithSmartContractErrorStatus
+	StatusSmartContractError int = 8
+	StatusUnknown            int = -1
 )
 
 const TOKEN_UNIT int64 = 1e10
@@ -185,6 +193,7 @@ type httpResponse struct {
 type localConfig struct {
 	chain         ChainConfig
 	wallet        zcncrypto.Wallet
+	signer        zcncrypto.Signer
 	authUrl       string
 	isConfigured  bool
 	isValidWallet bool
@@ -292,7 +301,7 @@ func Init(c string) error {
 	err := json.Unmarshal([]byte(c), &_config.chain)
 	if err == nil {
 		// Check signature scheme is supported
-		if _config.chain.SignatureScheme != "ed25519" && _config.chain.SignatureScheme != "bls0chain" {
+		if !zcncrypto.IsSignatureSchemeSupported(_config.chain.SignatureScheme) {
 			return errors.New("invalid/unsupported signature scheme")
 		}
 
@@ -338,9 +347,20 @@ func WithConfirmationChainLength(m int) func(c *ChainConfig) error {
 	}
 }
 
+// WithSmartContractErrorStatus makes Verify report transactions rejected by
+// the smart contract with StatusSmartContractError. By default they are
+// reported with StatusSuccess and their error is returned by
+// GetVerifyResult.
+func WithSmartContractErrorStatus(enable bool) func(c *ChainConfig) error {
+	return func(c *ChainConfig) error {
+		c.SmartContractErrorStatus = enable
+		return nil
+	}
+}
+
 // InitZCNSDK initializes the SDK with miner, sharder and signature scheme provided.
 func InitZCNSDK(blockWorker string, signscheme string, configs ...func(*ChainConfig) error) error {
-	if signscheme != "ed25519" && signscheme != "bls0chain" {
+	if !zcncrypto.IsSignatureSchemeSupported(signscheme) {
 		return errors.New("invalid/unsupported signature scheme")
 	}
 	_config.chain.BlockWorker = blockWorker
@@ -408,12 +428,35 @@ func CreateWallet(statusCb WalletCallback) error {
 // RecoverWallet recovers the previously generated wallet using the mnemonic.
 // It also registers the wallet again to block chain.
 func RecoverWallet(mnemonic string, statusCb WalletCallback) error {
+	return recoverWallet(mnemonic, "", statusCb)
+}
+
+// RecoverWalletWithPassphrase recovers the wallet of the mnemonic and the
+// BIP39 passphrase, an empty passphrase recovers the wallet RecoverWallet
+// does. It also registers the wallet again to block chain.
+func RecoverWalletWithPassphrase(mnemonic, passphrase string, statusCb WalletCallback) error {
+	return recoverWallet(mnemonic, passphrase, statusCb)
+}
+
+func recoverWallet(mnemonic, passphrase string, statusCb WalletCallback) error {
 	if zcncrypto.IsMnemonicValid(mnemonic) != true {
 		return errors.New("Invalid mnemonic")
 	}
+	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
+	ps, ok := sigScheme.(zcncrypto.PassphraseSignatureScheme)
+	if !ok && passphrase != "" {
+		return errors.New("signature scheme doesn't support passphrases")
+	}
 	go func() {
-		sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
-		wallet, err := sigScheme.RecoverKeys(mnemonic)
+		var (
+			wallet *zcncrypto.Wallet
+			err    error
+		)
+		if ok {
+			wallet, err = ps.RecoverKeysWithPassphrase(mnemonic, passphrase)
+		} else {
+			wallet, err = sigScheme.RecoverKeys(mnemonic)
+		}
 		if err != nil {
 			statusCb.OnWalletCreateComplete(StatusError, "", fmt.Sprintf("%s", err.Error()))
 			return
@@ -427,6 +470,107 @@ func RecoverWallet(mnemonic string, statusCb WalletCallback) error {
 	return nil
 }
 
+// DeriveWallet derives the wallet of the account index from the mnemonic.
+// One mnemonic yields any number of account wallets.
+func DeriveWallet(mnemonic string, index int) (string, error) {
+	w, err := deriveWallet(mnemonic, index)
+	if err != nil {
+		return "", err
+	}
+	wStr, err := w.Marshal()
+	if err != nil {
+		return "", errors.Wrap(err, "wallet encoding failed.")
+	}
+	return wStr, nil
+}
+
+func deriveWallet(mnemonic string, index int) (*zcncrypto.Wallet, error) {
+	if zcncrypto.IsMnemonicValid(mnemonic) != true {
+		return nil, errors.New("Invalid mnemonic")
+	}
+	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
+	hd, ok := sigScheme.(zcncrypto.HDSignatureScheme)
+	if !ok {
+		return nil, errors.New("signature scheme doesn't support key derivation")
+	}
+	return hd.DeriveKeys(mnemonic, zcncrypto.AccountPath(index))
+}
+
+// RecoverDerivedWallets scans the accounts derived from the mnemonic and
+// registers every account with a balance, and the first account, to block
+// chain. The scan stops after gapLimit consecutive accounts without
+// balance, or at the first balance the sharders fail to answer. statusCb
+// is called once for every recovered account and for the error stopping
+// the scan.
+func RecoverDerivedWallets(mnemonic string, gapLimit int, statusCb WalletCallback) error {
+	if zcncrypto.IsMnemonicValid(mnemonic) != true {
+		return errors.New("Invalid mnemonic")
+	}
+	if gapLimit < 1 {
+		return errors.New("gap limit must be positive")
+	}
+	go scanDerivedWallets(mnemonic, gapLimit, statusCb)
+	return nil
+}
+
+// scanDerivedWallets registers the first derived account and the ones with
+// a balance until gapLimit consecutive accounts without balance.
+func scanDerivedWallets(mnemonic string, gapLimit int, statusCb WalletCallback) {
+	for index, gap := 0, 0; gap < gapLimit; index++ {
+		wallet, err := deriveWallet(mnemonic, index)
+		if err != nil {
+			statusCb.OnWalletCreateComplete(StatusError, "", fmt.Sprintf("%s", err.Error()))
+			return
+		}
+		balance, err := getAccountBalance(wallet.ClientID)
+		if err != nil {
+			statusCb.OnWalletCreateComplete(StatusError, "",
+				fmt.Sprintf("account %d: %s", index, err.Error()))
+			return
+		}
+		if balance == 0 {
+			gap++
+			if index > 0 {
+				continue
+			}
+		} else {
+			gap = 0
+		}
+		err = RegisterToMiners(wallet, statusCb)
+		if err != nil {
+			statusCb.OnWalletCreateComplete(StatusError, "", fmt.Sprintf("%s", err.Error()))
+		}
+	}
+}
+
+// getAccountBalance returns the balance of the client agreed on by the
+// sharders. A client the sharders agree they don't know has no balance,
+// any other failure is an error.
+func getAccountBalance(clientID string) (int64, error) {
+	rsp, err := queryShardersConsensus(context.Background(), GET_BALANCE+clientID, newQueryConfig(nil))
+	if err != nil {
+		return 0, err
+	}
+	return parseAccountBalance(rsp)
+}
+
+func parseAccountBalance(rsp *queryResponse) (int64, error) {
+	switch rsp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusNotFound:
+		return 0, nil // unknown client
+	default:
+		return 0, errors.New("sharder_error", fmt.Sprintf("status %d: %s", rsp.StatusCode, rsp.Body))
+	}
+	var balance struct {
+		Balance int64 `json:"balance"`
+	}
+	if err := json.Unmarshal([]byte(rsp.Body), &balance); err != nil {
+		return 0, errors.Wrap(err, "balance parse error")
+	}
+	return balance.Balance, nil
+}
+
 // Split keys from the primary master key
 func SplitKeys(privateKey string, numSplits int) (string, error) {
 	if _config.chain.SignatureScheme != "bls0chain" {
@@ -437,6 +581,7 @@ func SplitKeys(privateKey string, numSplits int) (string, error) {
 	if err != nil {
 		return "", errors.Wrap(err, "set private key failed")
 	}
+	defer sigScheme.Zero()
 	w, err := sigScheme.SplitKeys(numSplits)
 	if err != nil {
 		return "", errors.Wrap(err, "split key failed.")
@@ -465,7 +610,9 @@ func RegisterToMiners(wallet *zcncrypto.Wallet, statusCb WalletCallback) error {
 				Logger.Error(minerurl, "new post request failed. ", err.Error())
 				return
 			}
+			start := time.Now()
 			res, err := req.Post()
+			node.Miners.ReportResponse(minerurl, start, res.StatusCode, err)
 			if err != nil {
 				Logger.Error(minerurl, "send error. ", err.Error())
 			}
@@ -506,7 +653,7 @@ type GetClientResponse struct {
 }
 
 func GetClientDetails(clientID string) (*GetClientResponse, error) {
-	minerurl := util.GetRandom(_config.chain.Miners, 1)[0]
+	minerurl := node.Miners.Select(_config.chain.Miners, 1)[0]
 	url := minerurl + GET_CLIENT
 	url = fmt.Sprintf("%v?id=%v", url, clientID)
 	req, err := util.NewHTTPGetRequest(url)
@@ -514,7 +661,9 @@ func GetClientDetails(clientID string) (*GetClientResponse, error) {
 		Logger.Error(minerurl, "new get request failed. ", err.Error())
 		return nil, err
 	}
+	start := time.Now()
 	res, err := req.Get()
+	node.Miners.ReportResponse(minerurl, start, res.StatusCode, err)
 	if err != nil {
 		Logger.Error(minerurl, "send error. ", err.Error())
 		return nil, err
@@ -534,11 +683,26 @@ func IsMnemonicValid(mnemonic string) bool {
 	return zcncrypto.IsMnemonicValid(mnemonic)
 }
 
+// SuggestMnemonics returns the corrections of a mnemonic with one mistyped
+// word or two swapped adjacent words, as a json array of mnemonics.
+func SuggestMnemonics(mnemonic string) (string, error) {
+	suggestions, err := zcncrypto.SuggestMnemonics(mnemonic)
+	if err != nil {
+		return "", err
+	}
+	res, err := json.Marshal(suggestions)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
 // SetWalletInfo should be set before any transaction or client specific APIs
 // splitKeyWallet parameter is valid only if SignatureScheme is "BLS0Chain"
 func SetWalletInfo(w string, splitKeyWallet bool) error {
 	err := json.Unmarshal([]byte(w), &_config.wallet)
 	if err == nil {
+		_config.signer = nil
 		if _config.chain.SignatureScheme == "bls0chain" {
 			_config.isSplitWallet = splitKeyWallet
 		}
@@ -547,6 +711,58 @@ func SetWalletInfo(w string, splitKeyWallet bool) error {
 	return err
 }
 
+// SetEncryptedWalletInfo should be set before any transaction or client
+// specific APIs. The wallet json string is plain or encrypted with the
+// passphrase.
+func SetEncryptedWalletInfo(w, passphrase string, splitKeyWallet bool) error {
+	wallet, err := zcncrypto.LoadWallet(w, passphrase)
+	if err != nil {
+		return err
+	}
+	_config.wallet = *wallet
+	_config.signer = nil
+	if _config.chain.SignatureScheme == "bls0chain" {
+		_config.isSplitWallet = splitKeyWallet
+	}
+	_config.isValidWallet = true
+	return nil
+}
+
+// SetSigner sets the wallet of the external signer, transactions are
+// signed by the signer. The private key is not known to the SDK.
+// splitKeyWallet parameter is valid only if SignatureScheme is "BLS0Chain"
+func SetSigner(signer zcncrypto.Signer, splitKeyWallet bool) error {
+	clientID, err := zcncrypto.ClientIDFromPublicKey(signer.PublicKey())
+	if err != nil {
+		return err
+	}
+	_config.wallet = zcncrypto.Wallet{
+		ClientID:  clientID,
+		ClientKey: signer.PublicKey(),
+		Keys:      []zcncrypto.KeyPair{{PublicKey: signer.PublicKey()}},
+		Version:   zcncrypto.CryptoVersion,
+	}
+	_config.signer = signer
+	if _config.chain.SignatureScheme == "bls0chain" {
+		_config.isSplitWallet = splitKeyWallet
+	}
+	_config.isValidWallet = true
+	return nil
+}
+
+// GetSigner returns the signer of the wallet.
+func GetSigner() zcncrypto.Signer {
+	if _config.signer != nil {
+		return _config.signer
+	}
+	var keys []zcncrypto.KeyPair
+	if len(_config.wallet.Keys) > 0 {
+		keys = _config.wallet.Keys[:1]
+	}
+	return zcncrypto.NewKeySigner(_config.chain.SignatureScheme,
+		_config.wallet.ClientKey, keys...)
+}
+
 // SetAuthUrl will be called by app to set zauth URL to SDK.
 func SetAuthUrl(url string) error {
 	if !_config.isSplitWallet {
@@ -603,7 +819,7 @@ func getBalanceFromSharders(clientID string) (int64, string, error) {
 	defer close(result)
 	// getMinShardersVerify
 	var numSharders = len(_config.chain.Sharders) // overwrite, use all
-	queryFromSharders(numSharders, fmt.Sprintf("%v%v", GET_BALANCE, clientID), result)
+	queried := queryFromSharders(numSharders, fmt.Sprintf("%v%v", GET_BALANCE, clientID), result)
 	consensus := float32(0)
 	balMap := make(map[int64]float32)
 	winBalance := int64(0)
@@ -638,8 +854,7 @@ func getBalanceFromSharders(clientID string) (int64, string, error) {
 			}
 		}
 	}
-	rate := consensus * 100 / float32(len(_config.chain.Sharders))
-	if rate < consensusThresh {
+	if !isConsensus(int(consensus), queried, consensusThresh) {
 		return 0, winError, errors.New("get balance failed. consensus not reached")
 	}
 	return winBalance, winInfo, nil
@@ -710,7 +925,7 @@ func getInfoFromSharders(urlSuffix string, op int, cb GetInfoCallback) {
 	defer close(result)
 	// getMinShardersVerify()
 	var numSharders = len(_config.chain.Sharders) // overwrite, use all
-	queryFromSharders(numSharders, urlSuffix, result)
+	queried := queryFromSharders(numSharders, urlSuffix, result)
 	consensus := float32(0)
 	resultMap := make(map[int]float32)
 	var winresult *util.GetResponse
@@ -718,6 +933,9 @@ func getInfoFromSharders(urlSuffix string, op int, cb GetInfoCallback) {
 		select {
 		case rsp := <-result:
 			Logger.Debug(rsp.Url, rsp.Status)
+			if rsp.StatusCode == 0 {
+				continue // quarantined or not reachable
+			}
 			resultMap[rsp.StatusCode]++
 			if resultMap[rsp.StatusCode] > consensus {
 				consensus = resultMap[rsp.StatusCode]
@@ -725,9 +943,12 @@ func getInfoFromSharders(urlSuffix string, op int, cb GetInfoCallback) {
 			}
 		}
 	}
-	rate := consensus * 100 / float32(len(_config.chain.Sharders))
-	if rate < consensusThresh {
-		newerr := fmt.Sprintf(`{"code": "consensus_failed", "error": "consensus failed on sharders.", "server_error": "%v"}`, winresult.Body)
+	if !isConsensus(int(consensus), queried, consensusThresh) {
+		var body string
+		if winresult != nil {
+			body = winresult.Body
+		}
+		newerr := fmt.Sprintf(`{"code": "consensus_failed", "error": "consensus failed on sharders.", "server_error": "%v"}`, body)
 		cb.OnInfoAvailable(op, StatusError, "", newerr)
 		return
 	}
@@ -777,6 +998,34 @@ func GetWallet(walletStr string) (*zcncrypto.Wallet, error) {
 
 }
 
+// EncryptWallet encrypts the wallet json string with the passphrase and
+// returns the encrypted wallet json string.
+func EncryptWallet(walletStr, passphrase string) (string, error) {
+	w, err := GetWallet(walletStr)
+	if err != nil {
+		return "", err
+	}
+	ew, err := zcncrypto.EncryptWallet(w, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return ew.Marshal()
+}
+
+// DecryptWallet decrypts the encrypted wallet json string with the
+// passphrase and returns the wallet json string.
+func DecryptWallet(encryptedStr, passphrase string) (string, error) {
+	ew, err := zcncrypto.UnmarshalEncryptedWallet(encryptedStr)
+	if err != nil {
+		return "", err
+	}
+	w, err := ew.Decrypt(passphrase)
+	if err != nil {
+		return "", err
+	}
+	return w.Marshal()
+}
+
 //GetWalletClientID -- given a walletstr return ClientID
 func GetWalletClientID(walletStr string) (string, error) {
 	w, err := GetWallet(walletStr)
@@ -814,19 +1063,11 @@ func GetZcnUSDInfo(cb GetUSDInfoCallback) error {
 // which is running on PC/Mac.
 func SetupAuth(authHost, clientID, clientKey, publicKey, privateKey, localPublicKey string, cb AuthCallback) error {
 	go func() {
-		authHost = strings.TrimRight(authHost, "/")
 		data := map[string]string{"client_id": clientID, "client_key": clientKey, "public_key": publicKey, "private_key": privateKey, "peer_public_key": localPublicKey}
-		req, err := util.NewHTTPPostRequest(authHost+"/setup", data)
+		err := setupAuth(context.Background(), authHost, data)
 		if err != nil {
-			Logger.Error("new post request failed. ", err.Error())
-			return
-		}
-		res, err := req.Post()
-		if err != nil {
-			Logger.Error(authHost+"send error. ", err.Error())
-		}
-		if res.StatusCode != http.StatusOK {
-			cb.OnSetupComplete(StatusError, res.Body)
+			Logger.Error(authHost+" setup error. ", err.Error())
+			cb.OnSetupComplete(StatusError, err.Error())
 			return
 		}
 		cb.OnSetupComplete(StatusSuccess, "")
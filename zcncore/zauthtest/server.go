@@ -0,0 +1,106 @@
+// Package zauthtest provides a local stand-in of the zauth service, which
+// keeps the auth key of split key wallets and co-signs their transactions.
+package zauthtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// Pairing is the auth key of a client and the device key it co-signs for.
+type Pairing struct {
+	ClientID      string `json:"client_id"`
+	ClientKey     string `json:"client_key"`
+	PublicKey     string `json:"public_key"`
+	PrivateKey    string `json:"private_key"`
+	PeerPublicKey string `json:"peer_public_key"`
+}
+
+// Server is a zauth service on a local HTTP server. A new setup of a
+// client replaces its pairing, the previous device key is revoked.
+type Server struct {
+	*httptest.Server
+	Scheme string
+
+	mutex    sync.Mutex
+	pairings map[string]*Pairing
+}
+
+// NewServer starts a zauth service of the signature scheme. Close it when
+// done.
+func NewServer(scheme string) *Server {
+	s := &Server{
+		Scheme:   scheme,
+		pairings: make(map[string]*Pairing),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/setup", s.setup)
+	mux.HandleFunc("/transaction", s.transaction)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Pairing returns the pairing of the client, nil if not paired.
+func (s *Server) Pairing(clientID string) *Pairing {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.pairings[clientID]
+}
+
+func (s *Server) setup(w http.ResponseWriter, r *http.Request) {
+	var p Pairing
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if p.ClientID == "" || p.PrivateKey == "" || p.PeerPublicKey == "" {
+		http.Error(w, "missing client_id, private_key or peer_public_key", http.StatusBadRequest)
+		return
+	}
+	s.mutex.Lock()
+	s.pairings[p.ClientID] = &p
+	s.mutex.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// transaction co-signs the transaction signed by the paired device key.
+func (s *Server) transaction(w http.ResponseWriter, r *http.Request) {
+	var txn transaction.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&txn); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p := s.Pairing(txn.ClientID)
+	if p == nil || p.PeerPublicKey != txn.PublicKey {
+		http.Error(w, "client not paired with the key", http.StatusUnauthorized)
+		return
+	}
+	ok, err := txn.VerifyTransaction(func(signature, hash, publicKey string) (bool, error) {
+		v := zcncrypto.NewSignatureScheme(s.Scheme)
+		if err := v.SetPublicKey(publicKey); err != nil {
+			return false, err
+		}
+		return v.Verify(signature, hash)
+	})
+	if err != nil || !ok {
+		http.Error(w, "invalid device signature", http.StatusUnauthorized)
+		return
+	}
+	signer := zcncrypto.NewSignatureScheme(s.Scheme)
+	if err = signer.SetPrivateKey(p.PrivateKey); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if txn.Signature, err = signer.Sign(txn.Hash); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	txn.PublicKey = p.PublicKey
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&txn)
+}
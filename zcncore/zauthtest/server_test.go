@@ -0,0 +1,86 @@
+package zauthtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/util"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zcncore"
+	"github.com/stretchr/testify/require"
+)
+
+// pairSplitWallet splits the primary key and pairs the auth key with the
+// server, it returns the device wallet.
+func pairSplitWallet(t *testing.T, s *Server, primary *zcncrypto.BLS0ChainScheme) *zcncrypto.Wallet {
+	sw, err := primary.SplitKeys(2)
+	require.NoError(t, err)
+	authKey := sw.Keys[1]
+	sw.Keys = sw.Keys[:1]
+	require.NoError(t, zcncore.PairAuth(context.Background(), s.URL, sw, authKey))
+	return sw
+}
+
+// coSign signs the transaction with the device key and asks the server to
+// co-sign it, it returns the aggregated signature.
+func coSign(t *testing.T, s *Server, w *zcncrypto.Wallet) (*transaction.Transaction, int) {
+	txn := transaction.NewTransactionEntity(w.ClientID, "chain", w.Keys[0].PublicKey)
+	txn.ToClientID = "to"
+	txn.Value = 1
+	device := zcncrypto.NewSignatureScheme("bls0chain")
+	require.NoError(t, device.SetPrivateKey(w.Keys[0].PrivateKey))
+	require.NoError(t, txn.ComputeHashAndSign(device.Sign))
+
+	req, err := util.NewHTTPPostRequest(s.URL+"/transaction", txn)
+	require.NoError(t, err)
+	res, err := req.Post()
+	require.NoError(t, err)
+	if res.StatusCode != http.StatusOK {
+		return nil, res.StatusCode
+	}
+	var authTxn transaction.Transaction
+	require.NoError(t, json.Unmarshal([]byte(res.Body), &authTxn))
+	deviceSig, err := device.Sign(txn.Hash)
+	require.NoError(t, err)
+	txn.Signature, err = zcncrypto.AggregateSignatures("bls0chain", authTxn.Signature, deviceSig)
+	require.NoError(t, err)
+	return txn, res.StatusCode
+}
+
+func TestSplitKeyRotation(t *testing.T) {
+	s := NewServer("bls0chain")
+	defer s.Close()
+
+	primary := zcncrypto.NewBLS0ChainScheme()
+	pw, err := primary.GenerateKeys()
+	require.NoError(t, err)
+
+	old := pairSplitWallet(t, s, primary)
+	require.Equal(t, pw.ClientID, old.ClientID)
+
+	verifier := zcncrypto.NewSignatureScheme("bls0chain")
+	require.NoError(t, verifier.SetPublicKey(pw.ClientKey))
+
+	txn, status := coSign(t, s, old)
+	require.Equal(t, http.StatusOK, status)
+	ok, err := verifier.Verify(txn.Signature, txn.Hash)
+	require.NoError(t, err)
+	require.True(t, ok, "co-signed transaction must verify with the client key")
+
+	// rotate: the old device key is revoked
+	rotated := pairSplitWallet(t, s, primary)
+	require.Equal(t, old.ClientID, rotated.ClientID)
+	require.NotEqual(t, old.Keys[0].PublicKey, rotated.Keys[0].PublicKey)
+
+	_, status = coSign(t, s, old)
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	txn, status = coSign(t, s, rotated)
+	require.Equal(t, http.StatusOK, status)
+	ok, err = verifier.Verify(txn.Signature, txn.Hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+}